@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -19,13 +20,25 @@ type AppServer struct {
 	mcpServer          *mcp.Server
 	router             *gin.Engine
 	httpServer         *http.Server
+
+	// notificationScanMu 保证同一时间只有一个通知扫描（get_pending 和后台轮询共用）
+	notificationScanMu sync.Mutex
+	pollInterval       time.Duration
+	webhook            *WebhookNotifier
+	notificationHub    *NotificationHub
+	retention          RetentionPolicy
+	triage             *TriageRules
+	// fetchNotifications 扫描通知页，默认用 xiaohongshuService 打开浏览器，测试中替换
+	fetchNotifications notificationFetchFunc
 }
 
 // NewAppServer 创建新的应用服务器实例
 func NewAppServer(xiaohongshuService *XiaohongshuService) *AppServer {
 	appServer := &AppServer{
 		xiaohongshuService: xiaohongshuService,
+		notificationHub:    NewNotificationHub(),
 	}
+	appServer.fetchNotifications = xiaohongshuService.GetUnprocessedNotifications
 
 	// 初始化 MCP Server（需要在创建 appServer 之后，因为工具注册需要访问 appServer）
 	appServer.mcpServer = InitMCPServer(appServer)
@@ -33,6 +46,31 @@ func NewAppServer(xiaohongshuService *XiaohongshuService) *AppServer {
 	return appServer
 }
 
+// ConfigureRetention 配置已完成通知的保留策略，需在 Start 之前调用
+func (s *AppServer) ConfigureRetention(policy RetentionPolicy) {
+	s.retention = policy
+}
+
+// ConfigureTriage 加载通知分拣规则文件，path 为空时使用数据库同目录的 notification_rules.json。需在 Start 之前调用
+func (s *AppServer) ConfigureTriage(path string) error {
+	if path == "" {
+		path = defaultTriageRulesPath()
+	}
+	rules, err := LoadTriageRules(path)
+	if err != nil {
+		return err
+	}
+	s.triage = rules
+	return nil
+}
+
+// ConfigureNotificationPush 配置后台通知轮询和 webhook 推送，需在 Start 之前调用。
+// 只配置 webhook 时，notifications_get_pending 扫描到的新通知同样会被推送。
+func (s *AppServer) ConfigureNotificationPush(cfg NotificationPushConfig) {
+	s.pollInterval = cfg.PollInterval
+	s.webhook = NewWebhookNotifier(cfg.Webhook)
+}
+
 // Start 启动服务器
 func (s *AppServer) Start(port string) error {
 	s.router = setupRoutes(s)
@@ -51,12 +89,28 @@ func (s *AppServer) Start(port string) error {
 		}
 	}()
 
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	if s.webhook != nil {
+		logrus.Infof("webhook 推送已启用，共 %d 个地址", len(s.webhook.cfg.URLs))
+		s.webhook.Start(bgCtx)
+	}
+	if s.pollInterval > 0 {
+		go NewNotificationPoller(s, s.pollInterval).Run(bgCtx)
+	}
+	if s.retention.Days > 0 {
+		go runRetentionLoop(bgCtx, s.retention)
+	}
+
 	// 等待中断信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	logrus.Infof("正在关闭服务器...")
+	stopBackground()
+	// 结束 SSE 连接，否则 Shutdown 会一直等它们
+	s.notificationHub.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -67,5 +121,18 @@ func (s *AppServer) Start(port string) error {
 		logrus.Infof("服务器已优雅关闭")
 	}
 
+	if s.webhook != nil {
+		done := make(chan struct{})
+		go func() {
+			s.webhook.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(15 * time.Second):
+			logrus.Warn("等待 webhook 队列推送超时，未推送的事件将丢失")
+		}
+	}
+
 	return nil
 }
@@ -12,3 +12,12 @@ const (
 func GetImagesPath() string {
 	return filepath.Join(os.TempDir(), ImagesDir)
 }
+
+const (
+	MediaDir = "xiaohongshu_media"
+)
+
+// GetMediaPath 笔记媒体（原图、实况图、视频）的下载目录
+func GetMediaPath() string {
+	return filepath.Join(os.TempDir(), MediaDir)
+}
@@ -0,0 +1,13 @@
+package configs
+
+var dbPath = ""
+
+// SetDBPath 设置通知状态数据库路径，为空时使用默认位置（二进制文件同目录）
+func SetDBPath(p string) {
+	dbPath = p
+}
+
+// GetDBPath 通知状态数据库路径，未设置时为空
+func GetDBPath() string {
+	return dbPath
+}
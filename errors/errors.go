@@ -1,6 +1,265 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
 
 var ErrNoFeeds = errors.New("没有捕获到 feeds 数据")
 var ErrNoFeedDetail = errors.New("没有捕获到 feed 详情数据")
+
+// ErrCommentNotFound 评论数据已全部加载但找不到目标评论，即评论已被删除或对当前用户不可见
+var ErrCommentNotFound = errors.New("评论不存在（已被删除或不可见）")
+
+// ErrNotNoteOwner 当前登录账号不是笔记作者，不能执行置顶、删除他人评论等管理操作
+var ErrNotNoteOwner = errors.New("当前账号不是笔记作者，无法管理该笔记的评论")
+
+// ErrNotCommentAuthor 要删除的评论不是当前登录账号发表的
+var ErrNotCommentAuthor = errors.New("该评论不是当前账号发表的，笔记作者删除他人评论请使用 delete_comment_as_owner")
+
+// ErrMentionNotResolved 评论中的 @提及没有在选人面板中对应到目标用户，评论未提交
+var ErrMentionNotResolved = errors.New("@提及未能解析到目标用户")
+
+// ErrCommentUnconfirmed 评论已提交，但没有捕获到平台的发表响应，无法确认是否发表成功
+var ErrCommentUnconfirmed = errors.New("评论已提交但未捕获到平台响应，无法确认是否发表成功")
+
+// ErrDuplicateReply 已经回复过该评论，为避免重复回复拒绝本次操作
+var ErrDuplicateReply = errors.New("已经回复过该评论")
+
+// NoteInaccessibleReason 笔记不可访问的原因
+type NoteInaccessibleReason string
+
+const (
+	ReasonDeleted       NoteInaccessibleReason = "deleted"        // 笔记已被作者删除
+	ReasonPrivate       NoteInaccessibleReason = "private"        // 私密笔记或作者设置了不可见
+	ReasonViolation     NoteInaccessibleReason = "violation"      // 因违规被删除或限制查看
+	ReasonNotFound      NoteInaccessibleReason = "not_found"      // 笔记不存在或链接已失效
+	ReasonUnavailable   NoteInaccessibleReason = "unavailable"    // "当前笔记暂时无法浏览"：被删除、审核中或被限流，平台不区分
+	ReasonRateLimited   NoteInaccessibleReason = "rate_limited"   // 访问过于频繁，被风控拦截
+	ReasonLoginRequired NoteInaccessibleReason = "login_required" // 需要登录才能查看
+	ReasonUnknown       NoteInaccessibleReason = "unknown"        // 有错误提示但无法归类
+)
+
+// inaccessibleKeywords 页面错误提示与原因的对应关系，按顺序匹配，
+// 更具体的提示（如"因违规已被删除"）需要排在通用提示（"已被删除"）之前。
+// 不使用"违规"、"请稍后再试"这类过短的片段，避免命中页面上无关的文字。
+var inaccessibleKeywords = []struct {
+	keyword string
+	reason  NoteInaccessibleReason
+}{
+	{"因违规已被删除", ReasonViolation},
+	{"因违规无法查看", ReasonViolation},
+	{"该笔记已被删除", ReasonDeleted},
+	{"已被删除", ReasonDeleted},
+	{"私密笔记", ReasonPrivate},
+	{"仅作者可见", ReasonPrivate},
+	{"因用户设置，你无法查看", ReasonPrivate},
+	{"内容不存在", ReasonNotFound},
+	{"笔记不存在", ReasonNotFound},
+	{"已失效", ReasonNotFound},
+	{"暂时无法浏览", ReasonUnavailable},
+	{"访问频繁", ReasonRateLimited},
+	{"操作频繁", ReasonRateLimited},
+	{"安全限制", ReasonRateLimited},
+	{"登录后查看", ReasonLoginRequired},
+	{"请先登录", ReasonLoginRequired},
+}
+
+// ClassifyInaccessibleText 根据页面上的错误提示判断不可访问的原因
+func ClassifyInaccessibleText(text string) NoteInaccessibleReason {
+	for _, kw := range inaccessibleKeywords {
+		if strings.Contains(text, kw.keyword) {
+			return kw.reason
+		}
+	}
+	return ReasonUnknown
+}
+
+// NoteInaccessibleError 笔记不可访问，调用方用 AsNoteInaccessible 取出原因，无需匹配中文提示
+type NoteInaccessibleError struct {
+	Reason NoteInaccessibleReason
+	// Message 页面上的原始提示
+	Message string
+}
+
+// NewNoteInaccessibleError 根据页面提示创建不可访问错误
+func NewNoteInaccessibleError(text string) *NoteInaccessibleError {
+	text = strings.TrimSpace(text)
+	return &NoteInaccessibleError{Reason: ClassifyInaccessibleText(text), Message: text}
+}
+
+func (e *NoteInaccessibleError) Error() string {
+	return "笔记不可访问: " + e.Message
+}
+
+// Code 稳定的错误代码，用于 HTTP 响应的 code 字段和 MCP 结果
+func (e *NoteInaccessibleError) Code() string {
+	switch e.Reason {
+	case ReasonDeleted:
+		return "NOTE_DELETED"
+	case ReasonPrivate:
+		return "NOTE_PRIVATE"
+	case ReasonViolation:
+		return "NOTE_VIOLATION"
+	case ReasonNotFound:
+		return "NOTE_NOT_FOUND"
+	case ReasonUnavailable:
+		return "NOTE_UNAVAILABLE"
+	case ReasonRateLimited:
+		return "RATE_LIMITED"
+	case ReasonLoginRequired:
+		return "LOGIN_REQUIRED"
+	default:
+		return "NOTE_INACCESSIBLE"
+	}
+}
+
+// HTTPStatus 对应的 HTTP 状态码
+func (e *NoteInaccessibleError) HTTPStatus() int {
+	switch e.Reason {
+	case ReasonDeleted, ReasonViolation, ReasonNotFound, ReasonUnavailable:
+		return http.StatusNotFound
+	case ReasonRateLimited:
+		return http.StatusTooManyRequests
+	case ReasonLoginRequired:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusForbidden
+	}
+}
+
+// CommentRejectReason 评论被平台拒绝的原因
+type CommentRejectReason string
+
+const (
+	RejectSensitive      CommentRejectReason = "sensitive"       // 含敏感词或违规内容
+	RejectRateLimited    CommentRejectReason = "rate_limited"    // 评论过于频繁
+	RejectCommentsClosed CommentRejectReason = "comments_closed" // 作者关闭了评论或限制了评论范围
+	RejectUnknown        CommentRejectReason = "unknown"         // 被拒绝但无法归类
+)
+
+// commentRejectKeywords 评论接口错误信息与原因的对应关系，按顺序匹配
+var commentRejectKeywords = []struct {
+	keyword string
+	reason  CommentRejectReason
+}{
+	{"关闭评论", RejectCommentsClosed},
+	{"评论已关闭", RejectCommentsClosed},
+	{"关闭了评论", RejectCommentsClosed},
+	{"暂不支持评论", RejectCommentsClosed},
+	{"无法评论", RejectCommentsClosed},
+	{"仅作者关注的人可评论", RejectCommentsClosed},
+	{"敏感", RejectSensitive},
+	{"违规", RejectSensitive},
+	{"违反", RejectSensitive},
+	{"不适宜", RejectSensitive},
+	{"不合适", RejectSensitive},
+	{"频繁", RejectRateLimited},
+	{"太快", RejectRateLimited},
+	{"请稍后再试", RejectRateLimited},
+	{"上限", RejectRateLimited},
+}
+
+// ClassifyCommentRejection 根据评论接口的错误信息判断被拒绝的原因
+func ClassifyCommentRejection(msg string) CommentRejectReason {
+	for _, kw := range commentRejectKeywords {
+		if strings.Contains(msg, kw.keyword) {
+			return kw.reason
+		}
+	}
+	return RejectUnknown
+}
+
+// CommentRejectedError 评论或回复被平台拒绝，调用方用 AsCommentRejected 取出原因
+type CommentRejectedError struct {
+	Reason CommentRejectReason
+	// Message 平台返回的原始提示
+	Message string
+	// APICode 评论接口返回的 code，从页面提示识别时为 0
+	APICode int
+}
+
+// NewCommentRejectedError 根据评论接口的 code 和错误信息创建拒绝错误
+func NewCommentRejectedError(apiCode int, msg string) *CommentRejectedError {
+	msg = strings.TrimSpace(msg)
+	return &CommentRejectedError{Reason: ClassifyCommentRejection(msg), Message: msg, APICode: apiCode}
+}
+
+func (e *CommentRejectedError) Error() string {
+	return fmt.Sprintf("评论被拒绝: %s (code=%d)", e.Message, e.APICode)
+}
+
+// Code 稳定的错误代码
+func (e *CommentRejectedError) Code() string {
+	switch e.Reason {
+	case RejectSensitive:
+		return "COMMENT_SENSITIVE"
+	case RejectRateLimited:
+		return "RATE_LIMITED"
+	case RejectCommentsClosed:
+		return "COMMENTS_CLOSED"
+	default:
+		return "COMMENT_REJECTED"
+	}
+}
+
+// HTTPStatus 对应的 HTTP 状态码
+func (e *CommentRejectedError) HTTPStatus() int {
+	switch e.Reason {
+	case RejectRateLimited:
+		return http.StatusTooManyRequests
+	case RejectCommentsClosed:
+		return http.StatusForbidden
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+// AsCommentRejected 判断 err 链中是否有评论被拒绝错误
+func AsCommentRejected(err error) (*CommentRejectedError, bool) {
+	var target *CommentRejectedError
+	if errors.As(err, &target) {
+		return target, true
+	}
+	return nil, false
+}
+
+// Code 返回 err 对应的稳定错误代码和 HTTP 状态码，err 不是已知的类型化错误时 ok 为 false
+func Code(err error) (code string, status int, ok bool) {
+	if accessErr, isAccess := AsNoteInaccessible(err); isAccess {
+		return accessErr.Code(), accessErr.HTTPStatus(), true
+	}
+	if rejectErr, isReject := AsCommentRejected(err); isReject {
+		return rejectErr.Code(), rejectErr.HTTPStatus(), true
+	}
+	if errors.Is(err, ErrCommentNotFound) {
+		return "COMMENT_NOT_FOUND", http.StatusNotFound, true
+	}
+	if errors.Is(err, ErrNotNoteOwner) {
+		return "NOT_NOTE_OWNER", http.StatusForbidden, true
+	}
+	if errors.Is(err, ErrNotCommentAuthor) {
+		return "NOT_COMMENT_AUTHOR", http.StatusForbidden, true
+	}
+	if errors.Is(err, ErrDuplicateReply) {
+		return "DUPLICATE_REPLY", http.StatusConflict, true
+	}
+	if errors.Is(err, ErrCommentUnconfirmed) {
+		return "COMMENT_UNCONFIRMED", http.StatusGatewayTimeout, true
+	}
+	if errors.Is(err, ErrMentionNotResolved) {
+		return "MENTION_NOT_RESOLVED", http.StatusUnprocessableEntity, true
+	}
+	return "", 0, false
+}
+
+// AsNoteInaccessible 判断 err 链中是否有笔记不可访问错误
+func AsNoteInaccessible(err error) (*NoteInaccessibleError, bool) {
+	var target *NoteInaccessibleError
+	if errors.As(err, &target) {
+		return target, true
+	}
+	return nil, false
+}
@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyInaccessibleText(t *testing.T) {
+	tests := []struct {
+		text string
+		want NoteInaccessibleReason
+	}{
+		{"该笔记已被删除", ReasonDeleted},
+		{"该内容因违规已被删除", ReasonViolation},
+		{"因违规无法查看", ReasonViolation},
+		{"这是一篇私密笔记", ReasonPrivate},
+		{"因用户设置，你无法查看", ReasonPrivate},
+		{"笔记不存在", ReasonNotFound},
+		{"链接已失效", ReasonNotFound},
+		{"访问频繁，请稍后再试", ReasonRateLimited},
+		{"登录后查看更多内容", ReasonLoginRequired},
+		{"当前笔记暂时无法浏览", ReasonUnavailable},
+		{"网络开小差了，请稍后再试", ReasonUnknown},
+		{"举报违规内容", ReasonUnknown},
+		{"登录后推荐更懂你的笔记", ReasonUnknown},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyInaccessibleText(tt.text), tt.text)
+	}
+}
+
+func TestClassifyCommentRejection(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want CommentRejectReason
+	}{
+		{"评论内容包含敏感词，请修改后再试", RejectSensitive},
+		{"内容违反社区规范", RejectSensitive},
+		{"评论太频繁了，请稍后再试", RejectRateLimited},
+		{"今日评论已达上限", RejectRateLimited},
+		{"作者已关闭评论", RejectCommentsClosed},
+		{"该笔记仅作者关注的人可评论", RejectCommentsClosed},
+		{"系统繁忙", RejectUnknown},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyCommentRejection(tt.msg), tt.msg)
+	}
+}
+
+func TestCode(t *testing.T) {
+	err := fmt.Errorf("打开详情页: %w", NewNoteInaccessibleError(" 该笔记已被删除 "))
+	code, status, ok := Code(err)
+	assert.True(t, ok)
+	assert.Equal(t, "NOTE_DELETED", code)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "打开详情页: 笔记不可访问: 该笔记已被删除", err.Error())
+
+	code, _, ok = Code(fmt.Errorf("%w: commentID=1", ErrCommentNotFound))
+	assert.True(t, ok)
+	assert.Equal(t, "COMMENT_NOT_FOUND", code)
+
+	code, status, ok = Code(ErrNotNoteOwner)
+	assert.True(t, ok)
+	assert.Equal(t, "NOT_NOTE_OWNER", code)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	code, status, ok = Code(ErrNotCommentAuthor)
+	assert.True(t, ok)
+	assert.Equal(t, "NOT_COMMENT_AUTHOR", code)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	code, status, ok = Code(fmt.Errorf("%w: 选人面板中没有用户 u1", ErrMentionNotResolved))
+	assert.True(t, ok)
+	assert.Equal(t, "MENTION_NOT_RESOLVED", code)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+
+	code, status, ok = Code(fmt.Errorf("发表评论: %w", NewCommentRejectedError(-9102, "作者已关闭评论")))
+	assert.True(t, ok)
+	assert.Equal(t, "COMMENTS_CLOSED", code)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	code, status, ok = Code(fmt.Errorf("%w: 评论 c1", ErrDuplicateReply))
+	assert.True(t, ok)
+	assert.Equal(t, "DUPLICATE_REPLY", code)
+	assert.Equal(t, http.StatusConflict, status)
+
+	_, _, ok = Code(fmt.Errorf("timeout"))
+	assert.False(t, ok)
+}
@@ -1,9 +1,16 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/xpzouying/xiaohongshu-mcp/cookies"
+	myerrors "github.com/xpzouying/xiaohongshu-mcp/errors"
 	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 
 	"github.com/gin-gonic/gin"
@@ -24,6 +31,17 @@ func respondError(c *gin.Context, statusCode int, code, message string, details
 	c.JSON(statusCode, response)
 }
 
+// respondActionError 返回操作失败响应。
+// 笔记不可访问、评论已删除等类型化错误使用其稳定的错误代码（如 NOTE_DELETED）和对应状态码，
+// 其余错误使用 fallbackCode 和 500。
+func respondActionError(c *gin.Context, fallbackCode, message string, err error) {
+	if code, status, ok := myerrors.Code(err); ok {
+		respondError(c, status, code, message, err.Error())
+		return
+	}
+	respondError(c, http.StatusInternalServerError, fallbackCode, message, err.Error())
+}
+
 // respondSuccess 返回成功响应
 func respondSuccess(c *gin.Context, data any, message string) {
 	response := SuccessResponse{
@@ -199,15 +217,37 @@ func (s *AppServer) getFeedDetailHandler(c *gin.Context) {
 	}
 
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "GET_FEED_DETAIL_FAILED",
-			"获取Feed详情失败", err.Error())
+		respondActionError(c, "GET_FEED_DETAIL_FAILED", "获取Feed详情失败", err)
 		return
 	}
 
+	if req.DownloadMedia && result.Media != nil {
+		if err := s.xiaohongshuService.DownloadFeedMedia(result.Media); err != nil {
+			respondError(c, http.StatusInternalServerError, "DOWNLOAD_MEDIA_FAILED",
+				"下载媒体失败", err.Error())
+			return
+		}
+	}
+
 	c.Set("account", "ai-report")
 	respondSuccess(c, result, "获取Feed详情成功")
 }
 
+// getFeedDetailsBatchHandler 批量获取Feed详情
+func (s *AppServer) getFeedDetailsBatchHandler(c *gin.Context) {
+	var req FeedDetailBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
+		return
+	}
+
+	result := s.xiaohongshuService.GetFeedDetailsBatch(c.Request.Context(), req.Feeds, req.Concurrency)
+
+	c.Set("account", "ai-report")
+	respondSuccess(c, result, "批量获取Feed详情完成")
+}
+
 // userProfileHandler 用户主页
 func (s *AppServer) userProfileHandler(c *gin.Context) {
 	var req UserProfileRequest
@@ -218,10 +258,10 @@ func (s *AppServer) userProfileHandler(c *gin.Context) {
 	}
 
 	// 获取用户信息
-	result, err := s.xiaohongshuService.UserProfile(c.Request.Context(), req.UserID, req.XsecToken)
+	opts := xiaohongshu.UserProfileOptions{Tab: xiaohongshu.ProfileNotesTab(req.Tab), MaxNotes: req.MaxNotes}
+	result, err := s.xiaohongshuService.UserProfile(c.Request.Context(), req.UserID, req.XsecToken, opts)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "GET_USER_PROFILE_FAILED",
-			"获取用户主页失败", err.Error())
+		respondActionError(c, "GET_USER_PROFILE_FAILED", "获取用户主页失败", err)
 		return
 	}
 
@@ -229,6 +269,25 @@ func (s *AppServer) userProfileHandler(c *gin.Context) {
 	respondSuccess(c, map[string]any{"data": result}, "result.Message")
 }
 
+// followUserHandler 关注或取消关注用户
+func (s *AppServer) followUserHandler(c *gin.Context) {
+	var req FollowUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
+		return
+	}
+
+	result, err := s.xiaohongshuService.FollowUser(c.Request.Context(), req.UserID, req.XsecToken, req.Unfollow)
+	if err != nil {
+		respondActionError(c, "FOLLOW_USER_FAILED", "关注操作失败", err)
+		return
+	}
+
+	c.Set("account", "ai-report")
+	respondSuccess(c, result, result.Message)
+}
+
 // postCommentHandler 发表评论到Feed
 func (s *AppServer) postCommentHandler(c *gin.Context) {
 	var req PostCommentRequest
@@ -239,10 +298,9 @@ func (s *AppServer) postCommentHandler(c *gin.Context) {
 	}
 
 	// 发表评论
-	result, err := s.xiaohongshuService.PostCommentToFeed(c.Request.Context(), req.FeedID, req.XsecToken, req.Content)
+	result, err := s.xiaohongshuService.PostCommentToFeed(c.Request.Context(), req.FeedID, req.XsecToken, req.Content, req.Image)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "POST_COMMENT_FAILED",
-			"发表评论失败", err.Error())
+		respondActionError(c, "POST_COMMENT_FAILED", "发表评论失败", err)
 		return
 	}
 
@@ -259,10 +317,9 @@ func (s *AppServer) replyCommentHandler(c *gin.Context) {
 		return
 	}
 
-	result, err := s.xiaohongshuService.ReplyCommentToFeed(c.Request.Context(), req.FeedID, req.XsecToken, req.CommentID, req.UserID, "", req.Content)
+	result, err := s.xiaohongshuService.ReplyCommentToFeed(c.Request.Context(), req.FeedID, req.XsecToken, req.CommentID, req.UserID, "", req.Content, req.Image, req.AllowDuplicate)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "REPLY_COMMENT_FAILED",
-			"回复评论失败", err.Error())
+		respondActionError(c, "REPLY_COMMENT_FAILED", "回复评论失败", err)
 		return
 	}
 
@@ -280,6 +337,25 @@ func healthHandler(c *gin.Context) {
 	}, "服务正常")
 }
 
+// deleteCommentHandler 删除评论
+func (s *AppServer) deleteCommentHandler(c *gin.Context) {
+	var req DeleteCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
+		return
+	}
+
+	result, err := s.xiaohongshuService.DeleteComment(c.Request.Context(), req.FeedID, req.XsecToken, req.CommentID, req.ParentCommentID)
+	if err != nil {
+		respondActionError(c, "DELETE_COMMENT_FAILED", "删除评论失败", err)
+		return
+	}
+
+	c.Set("account", "ai-report")
+	respondSuccess(c, result, result.Message)
+}
+
 // myProfileHandler 我的信息
 func (s *AppServer) myProfileHandler(c *gin.Context) {
 	// 获取当前登录用户信息
@@ -293,3 +369,170 @@ func (s *AppServer) myProfileHandler(c *gin.Context) {
 	c.Set("account", "ai-report")
 	respondSuccess(c, map[string]any{"data": result}, "获取我的主页成功")
 }
+
+// notificationsSearchHandler 查询通知历史
+func (s *AppServer) notificationsSearchHandler(c *gin.Context) {
+	var req NotificationSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
+		return
+	}
+
+	statuses, err := ParseNotificationStatuses(req.Status)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
+		return
+	}
+
+	store, err := GetNotificationStore()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "STATE_STORE_UNAVAILABLE",
+			"初始化状态数据库失败", err.Error())
+		return
+	}
+
+	result, err := store.SearchNotifications(NotificationQuery{
+		Statuses:      statuses,
+		RelationTypes: req.RelationType,
+		UserID:        req.UserID,
+		FeedID:        req.FeedID,
+		SinceUnix:     req.SinceUnix,
+		UntilUnix:     req.UntilUnix,
+		Keyword:       req.Keyword,
+		Offset:        req.Offset,
+		Limit:         req.Limit,
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "SEARCH_NOTIFICATIONS_FAILED",
+			"查询通知失败", err.Error())
+		return
+	}
+
+	c.Set("account", "ai-report")
+	respondSuccess(c, result, "查询通知成功")
+}
+
+// maxStateImportSize 导入文件的大小上限
+const maxStateImportSize = 64 << 20
+
+// notificationsExportHandler 导出通知状态（notifications 和 meta 表）为 JSONL 或 CSV 文件
+func (s *AppServer) notificationsExportHandler(c *gin.Context) {
+	format, err := ParseStateFormat(c.Query("format"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
+		return
+	}
+
+	store, err := GetNotificationStore()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "STATE_STORE_UNAVAILABLE",
+			"初始化状态数据库失败", err.Error())
+		return
+	}
+
+	var buf bytes.Buffer
+	result, err := store.Export(&buf, format)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "EXPORT_STATE_FAILED",
+			"导出通知状态失败", err.Error())
+		return
+	}
+
+	contentType := "application/x-ndjson"
+	if format == StateFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	filename := fmt.Sprintf("notifications-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("X-Export-Notifications", strconv.Itoa(result.Notifications))
+	c.Header("X-Export-Meta", strconv.Itoa(result.Meta))
+	c.Set("account", "ai-report")
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// notificationsImportHandler 导入通知状态，请求体为导出的文件内容，按通知 ID 合并
+func (s *AppServer) notificationsImportHandler(c *gin.Context) {
+	formatStr := c.Query("format")
+	if formatStr == "" && strings.HasPrefix(c.ContentType(), "text/csv") {
+		formatStr = string(StateFormatCSV)
+	}
+	format, err := ParseStateFormat(formatStr)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
+		return
+	}
+
+	entries, err := ReadStateEntries(http.MaxBytesReader(c.Writer, c.Request.Body, maxStateImportSize), format)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_IMPORT_FILE",
+			"导入文件格式错误", err.Error())
+		return
+	}
+
+	store, err := GetNotificationStore()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "STATE_STORE_UNAVAILABLE",
+			"初始化状态数据库失败", err.Error())
+		return
+	}
+
+	result, err := store.ImportEntries(entries)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "IMPORT_STATE_FAILED",
+			"导入通知状态失败", err.Error())
+		return
+	}
+
+	c.Set("account", "ai-report")
+	respondSuccess(c, result, "导入通知状态成功")
+}
+
+// notificationsStreamHandler 以 SSE 推送新入库的通知。
+// 每条通知一个 notification 事件（id 为 notification_id，data 为通知 JSON），
+// 空闲时每 30 秒发送一次注释行保活。只推送连接建立之后入库的通知。
+func (s *AppServer) notificationsStreamHandler(c *gin.Context) {
+	notifications, unsubscribe := s.notificationHub.Subscribe()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	logrus.Infof("通知 SSE 连接建立: %s", c.ClientIP())
+	defer logrus.Infof("通知 SSE 连接断开: %s", c.ClientIP())
+
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case n, ok := <-notifications:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(n)
+			if err != nil {
+				logrus.Warnf("序列化通知 %s 失败: %v", n.ID, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(c.Writer, "id: %s\nevent: notification\ndata: %s\n\n", n.ID, data); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
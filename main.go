@@ -3,34 +3,110 @@ package main
 import (
 	"flag"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/xpzouying/xiaohongshu-mcp/configs"
 )
 
 func main() {
+	// 子命令：导出/导入通知状态，执行完即退出，不启动服务
+	if len(os.Args) > 1 && (os.Args[1] == "export" || os.Args[1] == "import") {
+		if err := runStateCommand(os.Args[1], os.Args[2:]); err != nil {
+			logrus.Fatalf("%s 失败: %v", os.Args[1], err)
+		}
+		return
+	}
+
 	var (
 		headless bool
 		binPath  string // 浏览器二进制文件路径
 		port     string
+
+		pollInterval  time.Duration // 后台通知轮询间隔
+		webhookURLs   string        // 逗号分隔的 webhook 地址
+		webhookSecret string        // webhook 签名密钥
+
+		dbPath        string // 通知状态数据库路径
+		retentionDays int    // 已完成通知的保留天数
+		retentionMode string // 过期记录处理方式：archive / purge
+
+		rulesFile string // 通知分拣规则文件
 	)
 	flag.BoolVar(&headless, "headless", true, "是否无头模式")
 	flag.StringVar(&binPath, "bin", "", "浏览器二进制文件路径")
 	flag.StringVar(&port, "port", ":18060", "端口")
+	flag.DurationVar(&pollInterval, "poll-interval", 0, "后台通知轮询间隔（如 5m），0 表示不启动")
+	flag.StringVar(&webhookURLs, "webhook-url", "", "新通知推送的 webhook 地址，多个用逗号分隔")
+	flag.StringVar(&webhookSecret, "webhook-secret", "", "webhook 签名密钥（HMAC-SHA256）")
+	flag.StringVar(&dbPath, "db-path", "", "通知状态数据库路径，默认与二进制文件同目录的 notifications.db")
+	flag.IntVar(&retentionDays, "retention-days", 0, "已回复/已跳过通知的保留天数，0 表示不清理")
+	flag.StringVar(&retentionMode, "retention-mode", "", "过期通知的处理方式：archive（移到归档库，默认）或 purge（删除）")
+	flag.StringVar(&rulesFile, "rules-file", "", "通知分拣规则文件（JSON），默认与通知数据库同目录的 notification_rules.json")
 	flag.Parse()
 
 	if len(binPath) == 0 {
 		binPath = os.Getenv("ROD_BROWSER_BIN")
 	}
+	if pollInterval == 0 {
+		if v := os.Getenv("XHS_POLL_INTERVAL"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				logrus.Fatalf("XHS_POLL_INTERVAL 格式错误: %v", err)
+			}
+			pollInterval = d
+		}
+	}
+	if len(webhookURLs) == 0 {
+		webhookURLs = os.Getenv("XHS_WEBHOOK_URLS")
+	}
+	if len(webhookSecret) == 0 {
+		webhookSecret = os.Getenv("XHS_WEBHOOK_SECRET")
+	}
+	if len(dbPath) == 0 {
+		dbPath = os.Getenv("XHS_DB_PATH")
+	}
+	if retentionDays == 0 {
+		if v := os.Getenv("XHS_RETENTION_DAYS"); v != "" {
+			days, err := strconv.Atoi(v)
+			if err != nil {
+				logrus.Fatalf("XHS_RETENTION_DAYS 格式错误: %v", err)
+			}
+			retentionDays = days
+		}
+	}
+	if len(retentionMode) == 0 {
+		retentionMode = os.Getenv("XHS_RETENTION_MODE")
+	}
+	if len(rulesFile) == 0 {
+		rulesFile = os.Getenv("XHS_RULES_FILE")
+	}
+	mode, err := ParseRetentionMode(retentionMode)
+	if err != nil {
+		logrus.Fatalf("%v", err)
+	}
 
 	configs.InitHeadless(headless)
 	configs.SetBinPath(binPath)
+	configs.SetDBPath(dbPath)
 
 	// 初始化服务
 	xiaohongshuService := NewXiaohongshuService()
 
 	// 创建并启动应用服务器
 	appServer := NewAppServer(xiaohongshuService)
+	appServer.ConfigureNotificationPush(NotificationPushConfig{
+		PollInterval: pollInterval,
+		Webhook: WebhookConfig{
+			URLs:   parseWebhookURLs(webhookURLs),
+			Secret: webhookSecret,
+		},
+	})
+	appServer.ConfigureRetention(RetentionPolicy{Days: retentionDays, Mode: mode})
+	if err := appServer.ConfigureTriage(rulesFile); err != nil {
+		logrus.Fatalf("加载通知分拣规则失败: %v", err)
+	}
 	if err := appServer.Start(port); err != nil {
 		logrus.Fatalf("failed to run server: %v", err)
 	}
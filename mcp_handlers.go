@@ -4,17 +4,33 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/sirupsen/logrus"
 	"github.com/xpzouying/xiaohongshu-mcp/cookies"
+	myerrors "github.com/xpzouying/xiaohongshu-mcp/errors"
 	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
 
 // MCP 工具处理函数
 
+// errorResult 构造失败结果。类型化错误会在前缀后附上稳定的错误代码，
+// 如 "获取Feed详情失败 [NOTE_DELETED]: 笔记不可访问: 该笔记已被删除"，调用方据此判断原因。
+func errorResult(prefix string, err error) *MCPToolResult {
+	text := prefix + ": " + err.Error()
+	if code, _, ok := myerrors.Code(err); ok {
+		text = fmt.Sprintf("%s [%s]: %s", prefix, code, err.Error())
+	}
+	return &MCPToolResult{
+		Content: []MCPContent{{Type: "text", Text: text}},
+		IsError: true,
+	}
+}
+
 // handleCheckLoginStatus 处理检查登录状态
 func (s *AppServer) handleCheckLoginStatus(ctx context.Context) *MCPToolResult {
 	logrus.Info("MCP: 检查登录状态")
@@ -408,12 +424,18 @@ func (s *AppServer) handleGetFeedDetail(ctx context.Context, args map[string]any
 
 	result, err := s.xiaohongshuService.GetFeedDetailWithConfig(ctx, feedID, xsecToken, loadAll, config)
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "获取Feed详情失败: " + err.Error(),
-			}},
-			IsError: true,
+		return errorResult("获取Feed详情失败", err)
+	}
+
+	if downloadMedia, _ := args["download_media"].(bool); downloadMedia && result.Media != nil {
+		if err := s.xiaohongshuService.DownloadFeedMedia(result.Media); err != nil {
+			return &MCPToolResult{
+				Content: []MCPContent{{
+					Type: "text",
+					Text: "获取Feed详情成功，但下载媒体失败: " + err.Error(),
+				}},
+				IsError: true,
+			}
 		}
 	}
 
@@ -437,6 +459,101 @@ func (s *AppServer) handleGetFeedDetail(ctx context.Context, args map[string]any
 	}
 }
 
+// handleGetCommentReplies 获取楼中楼回复
+func (s *AppServer) handleGetCommentReplies(ctx context.Context, args CommentRepliesArgs) *MCPToolResult {
+	if args.FeedID == "" || args.XsecToken == "" || args.CommentID == "" {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: "获取子评论失败: 缺少 feed_id、xsec_token 或 comment_id 参数"}},
+			IsError: true,
+		}
+	}
+
+	logrus.Infof("MCP: 获取子评论 - Feed ID: %s, Comment ID: %s, cursor: %s", args.FeedID, args.CommentID, args.Cursor)
+
+	result, err := s.xiaohongshuService.GetCommentReplies(ctx, args.FeedID, args.XsecToken, args.CommentID, args.Cursor)
+	if err != nil {
+		return errorResult("获取子评论失败", err)
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("获取子评论成功，但序列化失败: %v", err)}},
+			IsError: true,
+		}
+	}
+
+	return &MCPToolResult{
+		Content: []MCPContent{{Type: "text", Text: string(jsonData)}},
+	}
+}
+
+// handleGetNotificationContext 获取通知所在楼层的上下文
+func (s *AppServer) handleGetNotificationContext(ctx context.Context, args GetNotificationContextArgs) *MCPToolResult {
+	if args.NotificationID == "" {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: "获取通知上下文失败: 缺少 notification_id 参数"}},
+			IsError: true,
+		}
+	}
+
+	logrus.Infof("MCP: 获取通知上下文 - notification_id: %s, root_comment_id: %s", args.NotificationID, args.RootCommentID)
+
+	result, err := s.xiaohongshuService.GetNotificationContext(ctx, args.NotificationID, args.RootCommentID)
+	if err != nil {
+		return errorResult("获取通知上下文失败", err)
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("获取通知上下文成功，但序列化失败: %v", err)}},
+			IsError: true,
+		}
+	}
+
+	return &MCPToolResult{
+		Content: []MCPContent{{Type: "text", Text: string(jsonData)}},
+	}
+}
+
+// handleGetFeedDetailsBatch 批量获取Feed详情
+func (s *AppServer) handleGetFeedDetailsBatch(ctx context.Context, args FeedDetailsBatchArgs) *MCPToolResult {
+	if len(args.Feeds) == 0 || len(args.Feeds) > 50 {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: "批量获取Feed详情失败: feeds 数量需在 1-50 之间"}},
+			IsError: true,
+		}
+	}
+
+	items := make([]FeedDetailBatchItem, 0, len(args.Feeds))
+	for i, f := range args.Feeds {
+		if f.FeedID == "" || f.XsecToken == "" {
+			return &MCPToolResult{
+				Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("批量获取Feed详情失败: 第 %d 条缺少 feed_id 或 xsec_token", i+1)}},
+				IsError: true,
+			}
+		}
+		items = append(items, FeedDetailBatchItem{FeedID: f.FeedID, XsecToken: f.XsecToken})
+	}
+
+	logrus.Infof("MCP: 批量获取Feed详情 - %d 条, concurrency=%d", len(items), args.Concurrency)
+
+	result := s.xiaohongshuService.GetFeedDetailsBatch(ctx, items, args.Concurrency)
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("批量获取Feed详情完成，但序列化失败: %v", err)}},
+			IsError: true,
+		}
+	}
+
+	return &MCPToolResult{
+		Content: []MCPContent{{Type: "text", Text: string(jsonData)}},
+	}
+}
+
 // handleUserProfile 获取用户主页
 func (s *AppServer) handleUserProfile(ctx context.Context, args map[string]any) *MCPToolResult {
 	logrus.Info("MCP: 获取用户主页")
@@ -464,18 +581,30 @@ func (s *AppServer) handleUserProfile(ctx context.Context, args map[string]any)
 		}
 	}
 
-	logrus.Infof("MCP: 获取用户主页 - User ID: %s", userID)
-
-	result, err := s.xiaohongshuService.UserProfile(ctx, userID, xsecToken)
-	if err != nil {
+	tab, _ := args["tab"].(string)
+	switch xiaohongshu.ProfileNotesTab(tab) {
+	case "", xiaohongshu.ProfileTabNotes, xiaohongshu.ProfileTabCollected, xiaohongshu.ProfileTabLiked:
+	default:
 		return &MCPToolResult{
 			Content: []MCPContent{{
 				Type: "text",
-				Text: "获取用户主页失败: " + err.Error(),
+				Text: fmt.Sprintf("获取用户主页失败: 无效的 tab %q，合法值：notes / collected / liked", tab),
 			}},
 			IsError: true,
 		}
 	}
+	maxNotes, _ := args["max_notes"].(int)
+	if maxNotes > 500 {
+		maxNotes = 500
+	}
+
+	logrus.Infof("MCP: 获取用户主页 - User ID: %s, tab: %q, max_notes: %d", userID, tab, maxNotes)
+
+	opts := xiaohongshu.UserProfileOptions{Tab: xiaohongshu.ProfileNotesTab(tab), MaxNotes: maxNotes}
+	result, err := s.xiaohongshuService.UserProfile(ctx, userID, xsecToken, opts)
+	if err != nil {
+		return errorResult("获取用户主页失败", err)
+	}
 
 	// 格式化输出，转换为JSON字符串
 	jsonData, err := json.MarshalIndent(result, "", "  ")
@@ -523,7 +652,7 @@ func (s *AppServer) handleLikeFeed(ctx context.Context, args map[string]interfac
 		if unlike {
 			action = "取消点赞"
 		}
-		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: action + "失败: " + err.Error()}}, IsError: true}
+		return errorResult(action+"失败", err)
 	}
 
 	action := "点赞"
@@ -533,6 +662,25 @@ func (s *AppServer) handleLikeFeed(ctx context.Context, args map[string]interfac
 	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("%s成功 - Feed ID: %s", action, res.FeedID)}}}
 }
 
+// handleLikeComment 处理点赞/取消点赞评论
+func (s *AppServer) handleLikeComment(ctx context.Context, args LikeCommentArgs) *MCPToolResult {
+	if args.FeedID == "" || args.XsecToken == "" || args.CommentID == "" {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "操作失败: 缺少 feed_id、xsec_token 或 comment_id 参数"}}, IsError: true}
+	}
+
+	action := "点赞评论"
+	if args.Unlike {
+		action = "取消点赞评论"
+	}
+
+	res, err := s.xiaohongshuService.LikeComment(ctx, args.FeedID, args.XsecToken, args.CommentID, args.ParentCommentID, args.Unlike)
+	if err != nil {
+		return errorResult(action+"失败", err)
+	}
+
+	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("%s成功 - Feed ID: %s, Comment ID: %s", action, res.FeedID, args.CommentID)}}}
+}
+
 // handleFavoriteFeed 处理收藏/取消收藏
 func (s *AppServer) handleFavoriteFeed(ctx context.Context, args map[string]interface{}) *MCPToolResult {
 	feedID, ok := args["feed_id"].(string)
@@ -559,7 +707,7 @@ func (s *AppServer) handleFavoriteFeed(ctx context.Context, args map[string]inte
 		if unfavorite {
 			action = "取消收藏"
 		}
-		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: action + "失败: " + err.Error()}}, IsError: true}
+		return errorResult(action+"失败", err)
 	}
 
 	action := "收藏"
@@ -607,22 +755,18 @@ func (s *AppServer) handlePostComment(ctx context.Context, args map[string]inter
 		}
 	}
 
-	logrus.Infof("MCP: 发表评论 - Feed ID: %s, 内容长度: %d", feedID, len(content))
+	image, _ := args["image"].(string)
+
+	logrus.Infof("MCP: 发表评论 - Feed ID: %s, 内容长度: %d, 图片: %s", feedID, len(content), image)
 
 	// 发表评论
-	result, err := s.xiaohongshuService.PostCommentToFeed(ctx, feedID, xsecToken, content)
+	result, err := s.xiaohongshuService.PostCommentToFeed(ctx, feedID, xsecToken, content, image)
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "发表评论失败: " + err.Error(),
-			}},
-			IsError: true,
-		}
+		return errorResult("发表评论失败", err)
 	}
 
-	// 返回成功结果，只包含feed_id
-	resultText := fmt.Sprintf("评论发表成功 - Feed ID: %s", result.FeedID)
+	// 返回成功结果，包含新评论的 ID
+	resultText := fmt.Sprintf("%s - Feed ID: %s, Comment ID: %s", result.Message, result.FeedID, result.CommentID)
 	return &MCPToolResult{
 		Content: []MCPContent{{
 			Type: "text",
@@ -683,31 +827,219 @@ func (s *AppServer) handleReplyComment(ctx context.Context, args map[string]inte
 		}
 	}
 
-	logrus.Infof("MCP: 回复评论 - Feed ID: %s, Comment ID: %s, parent_comment_id: %s, User ID: %s, 内容长度: %d",
-		feedID, commentID, parentCommentID, userID, len(content))
+	image, _ := args["image"].(string)
+	allowDuplicate, _ := args["allow_duplicate"].(bool)
+
+	logrus.Infof("MCP: 回复评论 - Feed ID: %s, Comment ID: %s, parent_comment_id: %s, User ID: %s, 内容长度: %d, 图片: %s",
+		feedID, commentID, parentCommentID, userID, len(content), image)
 
 	// 回复评论
-	result, err := s.xiaohongshuService.ReplyCommentToFeed(ctx, feedID, xsecToken, commentID, userID, parentCommentID, content)
+	result, err := s.xiaohongshuService.ReplyCommentToFeed(ctx, feedID, xsecToken, commentID, userID, parentCommentID, content, image, allowDuplicate)
 	if err != nil {
+		return errorResult("回复评论失败", err)
+	}
+
+	// 返回成功结果
+	responseText := fmt.Sprintf("%s - Feed ID: %s, Reply ID: %s, 目标 Comment ID: %s, User ID: %s",
+		result.Message, result.FeedID, result.CommentID, result.TargetCommentID, result.TargetUserID)
+	if result.NotificationsMarked > 0 {
+		responseText += fmt.Sprintf("\n已自动将 %d 条相关通知标记为 replied", result.NotificationsMarked)
+	}
+	if result.Warning != "" {
+		responseText += "\n注意: " + result.Warning
+	}
+	return &MCPToolResult{
+		Content: []MCPContent{{
+			Type: "text",
+			Text: responseText,
+		}},
+	}
+}
+
+// handleDeleteComment 处理删除评论
+func (s *AppServer) handleDeleteComment(ctx context.Context, args DeleteCommentArgs) *MCPToolResult {
+	if args.FeedID == "" || args.XsecToken == "" || args.CommentID == "" {
 		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "回复评论失败: " + err.Error(),
-			}},
+			Content: []MCPContent{{Type: "text", Text: "删除评论失败: 缺少 feed_id、xsec_token 或 comment_id 参数"}},
 			IsError: true,
 		}
 	}
 
-	// 返回成功结果
-	responseText := fmt.Sprintf("评论回复成功 - Feed ID: %s, Comment ID: %s, User ID: %s", result.FeedID, result.TargetCommentID, result.TargetUserID)
+	logrus.Infof("MCP: 删除评论 - Feed ID: %s, Comment ID: %s, parent_comment_id: %s", args.FeedID, args.CommentID, args.ParentCommentID)
+
+	result, err := s.xiaohongshuService.DeleteComment(ctx, args.FeedID, args.XsecToken, args.CommentID, args.ParentCommentID)
+	if err != nil {
+		return errorResult("删除评论失败", err)
+	}
+
 	return &MCPToolResult{
 		Content: []MCPContent{{
 			Type: "text",
-			Text: responseText,
+			Text: fmt.Sprintf("评论删除成功 - Feed ID: %s, Comment ID: %s", result.FeedID, result.CommentID),
+		}},
+	}
+}
+
+// handleUserRelations 处理获取关注/粉丝列表
+func (s *AppServer) handleUserRelations(ctx context.Context, args UserRelationsArgs) *MCPToolResult {
+	listType := xiaohongshu.RelationListType(args.ListType)
+	if listType != xiaohongshu.RelationFollowers && listType != xiaohongshu.RelationFollowing {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("获取列表失败: 无效的 list_type %q，合法值：followers / following", args.ListType)}}, IsError: true}
+	}
+	if args.UserID != "" && args.XsecToken == "" {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "获取列表失败: 查询他人列表时需要 xsec_token"}}, IsError: true}
+	}
+
+	offset := args.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	logrus.Infof("MCP: 获取%s列表 - User ID: %q, offset=%d, limit=%d", listType, args.UserID, offset, limit)
+
+	result, err := s.xiaohongshuService.GetUserRelations(ctx, args.UserID, args.XsecToken, listType, offset, limit)
+	if err != nil {
+		return errorResult("获取列表失败", err)
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("获取列表成功，但序列化失败: %v", err)}}, IsError: true}
+	}
+	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: string(jsonData)}}}
+}
+
+// handleFollowUser 处理关注/取消关注用户
+func (s *AppServer) handleFollowUser(ctx context.Context, args FollowUserArgs) *MCPToolResult {
+	action := "关注"
+	if args.Unfollow {
+		action = "取消关注"
+	}
+	if args.UserID == "" || args.XsecToken == "" {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: action + "失败: 缺少 user_id 或 xsec_token 参数"}}, IsError: true}
+	}
+
+	logrus.Infof("MCP: %s用户 - User ID: %s", action, args.UserID)
+
+	res, err := s.xiaohongshuService.FollowUser(ctx, args.UserID, args.XsecToken, args.Unfollow)
+	if err != nil {
+		return errorResult(action+"失败", err)
+	}
+
+	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("%s - User ID: %s", res.Message, res.UserID)}}}
+}
+
+// handlePinComment 处理置顶/取消置顶评论
+func (s *AppServer) handlePinComment(ctx context.Context, args PinCommentArgs) *MCPToolResult {
+	if args.FeedID == "" || args.XsecToken == "" || args.CommentID == "" {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "操作失败: 缺少 feed_id、xsec_token 或 comment_id 参数"}}, IsError: true}
+	}
+
+	action := "置顶评论"
+	if args.Unpin {
+		action = "取消置顶评论"
+	}
+	logrus.Infof("MCP: %s - Feed ID: %s, Comment ID: %s", action, args.FeedID, args.CommentID)
+
+	res, err := s.xiaohongshuService.PinComment(ctx, args.FeedID, args.XsecToken, args.CommentID, args.Unpin)
+	if err != nil {
+		return errorResult(action+"失败", err)
+	}
+
+	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("%s成功 - Feed ID: %s, Comment ID: %s", action, res.FeedID, args.CommentID)}}}
+}
+
+// handleDeleteCommentAsOwner 处理笔记作者删除他人评论
+func (s *AppServer) handleDeleteCommentAsOwner(ctx context.Context, args DeleteCommentArgs) *MCPToolResult {
+	if args.FeedID == "" || args.XsecToken == "" || args.CommentID == "" {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: "删除评论失败: 缺少 feed_id、xsec_token 或 comment_id 参数"}},
+			IsError: true,
+		}
+	}
+
+	logrus.Infof("MCP: 作者删除评论 - Feed ID: %s, Comment ID: %s, parent_comment_id: %s", args.FeedID, args.CommentID, args.ParentCommentID)
+
+	result, err := s.xiaohongshuService.DeleteCommentAsOwner(ctx, args.FeedID, args.XsecToken, args.CommentID, args.ParentCommentID)
+	if err != nil {
+		return errorResult("删除评论失败", err)
+	}
+
+	return &MCPToolResult{
+		Content: []MCPContent{{
+			Type: "text",
+			Text: fmt.Sprintf("评论删除成功 - Feed ID: %s, Comment ID: %s", result.FeedID, result.CommentID),
 		}},
 	}
 }
 
+// notificationRelationLabel 通知关系类型的中文描述
+func notificationRelationLabel(rt xiaohongshu.NotificationRelationType) string {
+	switch rt {
+	case xiaohongshu.RelationCommentOnMyNote:
+		return "评论了我的笔记"
+	case xiaohongshu.RelationReplyToMyComment:
+		return "回复了我的评论"
+	case xiaohongshu.RelationAtOthersUnderMyComment:
+		return "在我的评论下@了他人"
+	case xiaohongshu.RelationMentionedMe:
+		return "在评论中@了我"
+	case xiaohongshu.RelationLikedMyNote:
+		return "赞了我的笔记"
+	case xiaohongshu.RelationCollectedMyNote:
+		return "收藏了我的笔记"
+	case xiaohongshu.RelationLikedMyComment:
+		return "赞了我的评论"
+	case xiaohongshu.RelationNewFollower:
+		return "关注了我"
+	default:
+		return string(rt)
+	}
+}
+
+// storeInfoNotifications 把赞和收藏、新增关注通知记录到状态数据库（status=info），
+// 新入库的同样推送给 webhook 和订阅者。这类通知不进入待处理列表。
+func (s *AppServer) storeInfoNotifications(notifications []xiaohongshu.Notification) {
+	if len(notifications) == 0 {
+		return
+	}
+	store, err := GetNotificationStore()
+	if err != nil {
+		logrus.Warnf("初始化状态数据库失败，跳过记录通知: %v", err)
+		return
+	}
+
+	records := make([]NotificationRecord, 0, len(notifications))
+	for _, n := range notifications {
+		records = append(records, NotificationRecord{
+			ID:             n.ID,
+			Status:         StatusInfo,
+			FeedID:         n.ItemInfo.ID,
+			XsecToken:      n.ItemInfo.XsecToken,
+			CommentID:      n.CommentInfo.ID,
+			CommentContent: n.CommentInfo.Content,
+			UserID:         n.UserInfo.UserID,
+			UserNickname:   n.UserInfo.Nickname,
+			NoteTitle:      n.ItemInfo.Content,
+			RelationType:   string(n.RelationType),
+			NotifTimeUnix:  n.Time,
+		})
+	}
+	inserted, err := store.UpsertNotifications(records)
+	if err != nil {
+		logrus.Warnf("记录通知到 DB 失败: %v", err)
+		return
+	}
+	s.publishNewNotifications(inserted)
+}
+
 // handleGetNotifications 处理获取通知列表请求
 func (s *AppServer) handleGetNotifications(ctx context.Context, args map[string]interface{}) *MCPToolResult {
 	cursor, _ := args["cursor"].(string)
@@ -717,16 +1049,23 @@ func (s *AppServer) handleGetNotifications(ctx context.Context, args map[string]
 		limit = 20
 	}
 	sinceUnix, _ := args["since_unix"].(int64)
+	categoryStr, _ := args["category"].(string)
+	category, err := xiaohongshu.ParseNotificationCategory(categoryStr)
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}
+	}
 
-	logrus.Infof("MCP: 获取通知列表 - cursor=%s, limit=%d, since_unix=%d", cursor, limit, sinceUnix)
+	logrus.Infof("MCP: 获取通知列表 - category=%s, cursor=%s, limit=%d, since_unix=%d", category, cursor, limit, sinceUnix)
 
 	var result *xiaohongshu.NotificationsResult
-	var err error
 
 	if sinceUnix > 0 {
-		result, err = s.xiaohongshuService.GetNotificationsSince(ctx, sinceUnix)
+		result, err = s.xiaohongshuService.GetNotificationsSince(ctx, category, sinceUnix)
 	} else {
-		result, err = s.xiaohongshuService.GetNotifications(ctx, cursor, limit)
+		result, err = s.xiaohongshuService.GetNotifications(ctx, category, cursor, limit)
 	}
 	if err != nil {
 		return &MCPToolResult{
@@ -738,6 +1077,10 @@ func (s *AppServer) handleGetNotifications(ctx context.Context, args map[string]
 		}
 	}
 
+	if category != xiaohongshu.NotificationCategoryMentions {
+		s.storeInfoNotifications(result.Notifications)
+	}
+
 	if len(result.Notifications) == 0 {
 		msg := "暂无通知"
 		if cursor != "" {
@@ -758,6 +1101,9 @@ func (s *AppServer) handleGetNotifications(ctx context.Context, args map[string]
 		sb.WriteString(fmt.Sprintf("（%s ~ %s）", tN.Format("01-02 15:04"), t0.Format("01-02 15:04")))
 	}
 	sb.WriteString("\n")
+	if result.Incomplete {
+		sb.WriteString(fmt.Sprintf("⚠️ 翻页未完成，更早的通知没有取到：%s\n", result.Gap))
+	}
 	if result.HasMore {
 		sb.WriteString(fmt.Sprintf("next_cursor=%s（传入可获取更早的通知）\n", result.NextCursor))
 	} else {
@@ -769,21 +1115,7 @@ func (s *AppServer) handleGetNotifications(ctx context.Context, args map[string]
 		t := time.Unix(n.Time, 0).In(cst)
 		timeStr := t.Format("2006-01-02 15:04:05")
 
-		var relationLabel string
-		switch n.RelationType {
-		case xiaohongshu.RelationCommentOnMyNote:
-			relationLabel = "评论了我的笔记"
-		case xiaohongshu.RelationReplyToMyComment:
-			relationLabel = "回复了我的评论"
-		case xiaohongshu.RelationAtOthersUnderMyComment:
-			relationLabel = "在我的评论下@了他人"
-		case xiaohongshu.RelationMentionedMe:
-			relationLabel = "在评论中@了我"
-		default:
-			relationLabel = string(n.RelationType)
-		}
-
-		sb.WriteString(fmt.Sprintf("--- 通知 %d [%s] ---\n", i+1, relationLabel))
+		sb.WriteString(fmt.Sprintf("--- 通知 %d [%s] ---\n", i+1, notificationRelationLabel(n.RelationType)))
 		sb.WriteString(fmt.Sprintf("notification_id: %s\n", n.ID))
 		sb.WriteString(fmt.Sprintf("时间: %s\n", timeStr))
 		sb.WriteString(fmt.Sprintf("用户: %s (user_id: %s)", n.UserInfo.Nickname, n.UserInfo.UserID))
@@ -791,8 +1123,19 @@ func (s *AppServer) handleGetNotifications(ctx context.Context, args map[string]
 			sb.WriteString(fmt.Sprintf("【%s】", n.UserInfo.Indicator))
 		}
 		sb.WriteString("\n")
-		sb.WriteString(fmt.Sprintf("评论内容: %s\n", n.CommentInfo.Content))
-		sb.WriteString(fmt.Sprintf("comment_id: %s\n", n.CommentInfo.ID))
+
+		if n.RelationType == xiaohongshu.RelationNewFollower {
+			if n.UserInfo.FollowStatus != "" {
+				sb.WriteString(fmt.Sprintf("关注关系: %s\n", n.UserInfo.FollowStatus))
+			}
+			sb.WriteString("\n")
+			continue
+		}
+
+		if n.CommentInfo.ID != "" {
+			sb.WriteString(fmt.Sprintf("评论内容: %s\n", n.CommentInfo.Content))
+			sb.WriteString(fmt.Sprintf("comment_id: %s\n", n.CommentInfo.ID))
+		}
 
 		if n.Type == "comment/comment" && n.CommentInfo.TargetComment != nil {
 			sb.WriteString(fmt.Sprintf("被回复的评论: [%s] %s\n",
@@ -830,6 +1173,7 @@ func (s *AppServer) handleGetNotifications(ctx context.Context, args map[string]
 //   - full_scan: true 时扫满 max_pages 页不提前停止（用于全量补漏扫描）
 //   - since_hours: 兜底时间窗口（默认48小时），仅当所有 ID 列表均为空时生效
 //   - max_results: 单次最多返回多少条（默认20），防止输出截断
+//
 // extractSinceUnixFromIDs 从 processed_ids 中提取最小雪花 ID 对应的 Unix 时间戳（秒）。
 // 小红书雪花 ID 高 41 位是毫秒时间戳（相对于 2013-01-01 00:00:00 UTC 的偏移）。
 //
@@ -840,6 +1184,9 @@ func (s *AppServer) handleGetNotifications(ctx context.Context, args map[string]
 // 纳入计算只会把窗口拉得更早，增加不必要的扫描量。
 //
 // 如果 processedIDs 为空（首次运行），返回 0，调用方退回 since_hours 兜底。
+//
+// 保留策略清理旧记录后，processedIDs 只剩较新的记录，调用方还需保证起点不早于清理水位
+// （NotificationStore.RetentionWatermark），否则被清理的旧通知会被当作全新通知。
 func extractSinceUnixFromIDs(sets ...map[string]bool) int64 {
 	// 小红书 notification_id 高位直接编码 Unix 秒时间戳：
 	// notification_id >> 32 = Unix 时间戳（秒）
@@ -877,15 +1224,29 @@ func truncate(s string, n int) string {
 	return string(runes[:n]) + "..."
 }
 
-// handleNotificationsGetPending 获取待处理通知列表（从 DB + 实时扫描合并）
-func (s *AppServer) handleNotificationsGetPending(ctx context.Context, args NotificationsGetPendingArgs) *MCPToolResult {
-	store, err := GetNotificationStore()
-	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{Type: "text", Text: "初始化状态数据库失败: " + err.Error()}},
-			IsError: true,
-		}
-	}
+// notificationScanOptions 一次通知扫描的参数，零值使用默认值
+type notificationScanOptions struct {
+	SinceHours int
+	MaxPages   int
+	MaxResults int
+	FullScan   bool
+}
+
+// notificationFetchFunc 翻页扫描通知，参数含义同 XiaohongshuService.GetUnprocessedNotifications
+type notificationFetchFunc func(
+	ctx context.Context,
+	processedIDs, retryIDs, deletedIDs map[string]bool,
+	maxPages, stopAfterConsecutiveDone int,
+	sinceUnix int64,
+	maxResults int,
+) (*xiaohongshu.UnprocessedNotificationsResult, error)
+
+// scanNotifications 扫描通知，全新通知经分拣规则处理后写入 DB（INSERT OR IGNORE，不覆盖已有状态），
+// 返回扫描结果和本次真正新入库的记录（含分拣结果）。notifications_get_pending 和后台轮询共用，
+// 同一时间只允许一个扫描，避免两个浏览器同时翻通知页。
+func (s *AppServer) scanNotifications(ctx context.Context, store *NotificationStore, opts notificationScanOptions) (*xiaohongshu.UnprocessedNotificationsResult, []NotificationRecord, error) {
+	s.notificationScanMu.Lock()
+	defer s.notificationScanMu.Unlock()
 
 	// 自动跳过重试次数过多的通知
 	skipped, err := store.AutoSkipExcessiveRetries(5)
@@ -898,41 +1259,32 @@ func (s *AppServer) handleNotificationsGetPending(ctx context.Context, args Noti
 	// 从 DB 读取各状态 ID 集合
 	processedIDs, err := store.GetProcessedIDs()
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{Type: "text", Text: "读取已处理 ID 失败: " + err.Error()}},
-			IsError: true,
-		}
+		return nil, nil, fmt.Errorf("读取已处理 ID 失败: %w", err)
 	}
 	retryIDs, err := store.GetRetryIDs()
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{Type: "text", Text: "读取重试 ID 失败: " + err.Error()}},
-			IsError: true,
-		}
+		return nil, nil, fmt.Errorf("读取重试 ID 失败: %w", err)
 	}
 	deletedCheckIDs, err := store.GetDeletedCheckIDs()
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{Type: "text", Text: "读取待确认 ID 失败: " + err.Error()}},
-			IsError: true,
-		}
+		return nil, nil, fmt.Errorf("读取待确认 ID 失败: %w", err)
 	}
 
 	// 计算扫描起点：从 processedIDs 中最小雪花 ID 推算，或退回 since_hours
-	sinceHours := args.SinceHours
+	sinceHours := opts.SinceHours
 	if sinceHours <= 0 {
 		sinceHours = 48
 	}
-	maxPages := args.MaxPages
+	maxPages := opts.MaxPages
 	if maxPages <= 0 {
 		maxPages = 5
 	}
-	maxResults := args.MaxResults
+	maxResults := opts.MaxResults
 	if maxResults <= 0 {
 		maxResults = 20
 	}
 	stopAfterConsecutive := 5
-	if args.FullScan {
+	if opts.FullScan {
 		stopAfterConsecutive = 999999
 	}
 
@@ -946,23 +1298,25 @@ func (s *AppServer) handleNotificationsGetPending(ctx context.Context, args Noti
 			sinceUnix = time.Now().Unix() - int64(sinceHours)*3600
 		}
 	}
+	// 保留策略清理掉的已完成通知不在 processedIDs 里，扫描起点不能早于清理水位
+	if watermark, err := store.RetentionWatermark(); err != nil {
+		logrus.Warnf("读取清理水位失败: %v", err)
+	} else if watermark > 0 && sinceUnix <= watermark {
+		sinceUnix = watermark + 1
+	}
 
-	logrus.Infof("notifications.get_pending: processed=%d, retry=%d, deleted_check=%d, maxPages=%d, sinceUnix=%d",
+	logrus.Infof("扫描通知: processed=%d, retry=%d, deleted_check=%d, maxPages=%d, sinceUnix=%d",
 		len(processedIDs), len(retryIDs), len(deletedCheckIDs), maxPages, sinceUnix)
 
 	// 调用底层扫描
-	result, err := s.xiaohongshuService.GetUnprocessedNotifications(
+	result, err := s.fetchNotifications(
 		ctx, processedIDs, retryIDs, deletedCheckIDs,
 		maxPages, stopAfterConsecutive, sinceUnix, maxResults,
 	)
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{Type: "text", Text: "扫描通知失败: " + err.Error()}},
-			IsError: true,
-		}
+		return nil, nil, fmt.Errorf("扫描通知失败: %w", err)
 	}
 
-	// 将扫描到的全新通知写入 DB（INSERT OR IGNORE，不覆盖已有状态）
 	var newRecords []NotificationRecord
 	for _, n := range result.Notifications {
 		if n.RetryReason == xiaohongshu.RetryReasonNone {
@@ -981,20 +1335,95 @@ func (s *AppServer) handleNotificationsGetPending(ctx context.Context, args Noti
 			})
 		}
 	}
+	var inserted []NotificationRecord
 	if len(newRecords) > 0 {
-		if err := store.UpsertNotifications(newRecords); err != nil {
+		if s.triage != nil {
+			if n := s.triage.Apply(newRecords); n > 0 {
+				logrus.Infof("分拣规则命中 %d 条新通知", n)
+			}
+		}
+		inserted, err = store.UpsertNotifications(newRecords)
+		if err != nil {
 			logrus.Warnf("写入新通知到 DB 失败: %v", err)
 		}
 	}
 
-	// 更新 last_fetch_time 为本次扫描到的最新通知时间
-	if len(result.Notifications) > 0 {
+	// 更新 last_fetch_time 为本次扫描到的最新通知时间。
+	// 翻页中断时不更新：断开处之后的通知还没扫到，下次仍要从旧的时间点开始
+	if result.Gap != "" {
+		logrus.Warnf("通知扫描翻页中断，不更新 last_fetch_time: %s", result.Gap)
+	} else if len(result.Notifications) > 0 {
 		latestTime := result.Notifications[0].TimeUnix
 		if latestTime > 0 {
 			_ = store.SetLastFetchTime(latestTime)
 		}
 	}
 
+	s.publishNewNotifications(inserted)
+
+	return result, inserted, nil
+}
+
+// publishNewNotifications 把新入库的通知推送给 webhook、SSE 连接和订阅了资源的 MCP 客户端
+func (s *AppServer) publishNewNotifications(records []NotificationRecord) {
+	if len(records) == 0 {
+		return
+	}
+	if s.webhook != nil {
+		s.webhook.Enqueue(records)
+	}
+	s.notificationHub.Publish(records)
+
+	ids := make([]any, 0, len(records))
+	for _, r := range records {
+		ids = append(ids, r.ID)
+	}
+	if err := s.mcpServer.ResourceUpdated(context.Background(), &mcp.ResourceUpdatedNotificationParams{
+		URI:  newNotificationsResourceURI,
+		Meta: mcp.Meta{"notification_ids": ids},
+	}); err != nil {
+		logrus.Warnf("推送 MCP 资源更新失败: %v", err)
+	}
+}
+
+// handleReadNewNotifications 读取最近新入库的通知
+func (s *AppServer) handleReadNewNotifications(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	data, err := json.Marshal(s.notificationHub.Recent())
+	if err != nil {
+		return nil, fmt.Errorf("序列化通知失败: %w", err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{
+			URI:      req.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		}},
+	}, nil
+}
+
+// handleNotificationsGetPending 获取待处理通知列表（从 DB + 实时扫描合并）
+func (s *AppServer) handleNotificationsGetPending(ctx context.Context, args NotificationsGetPendingArgs) *MCPToolResult {
+	store, err := GetNotificationStore()
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: "初始化状态数据库失败: " + err.Error()}},
+			IsError: true,
+		}
+	}
+
+	result, inserted, err := s.scanNotifications(ctx, store, notificationScanOptions{
+		SinceHours: args.SinceHours,
+		MaxPages:   args.MaxPages,
+		MaxResults: args.MaxResults,
+		FullScan:   args.FullScan,
+	})
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}
+	}
+
 	// 从 DB 读取所有待处理记录（pending/retry/deleted_check），
 	// 与扫描结果合并——确保即使扫描页数不足，DB 里的旧 pending 也不会丢失。
 	dbPendingRecords, err := store.GetPendingRecords()
@@ -1002,32 +1431,53 @@ func (s *AppServer) handleNotificationsGetPending(ctx context.Context, args Noti
 		logrus.Warnf("读取 DB pending 记录失败: %v", err)
 	}
 
+	// 分拣结果：新入库的以本次结果为准，其余取 DB 记录；被规则跳过的不再返回
+	triaged := make(map[string]NotificationRecord)
+	for _, r := range dbPendingRecords {
+		triaged[r.ID] = r
+	}
+	autoSkipped := make(map[string]bool)
+	for _, r := range inserted {
+		triaged[r.ID] = r
+		if r.Status == StatusSkipped {
+			autoSkipped[r.ID] = true
+		}
+	}
+
 	// 以扫描结果为基础，补充 DB 里有但扫描未覆盖到的 pending 记录
 	scannedIDs := make(map[string]bool)
 	for _, n := range result.Notifications {
 		scannedIDs[n.NotificationID] = true
 	}
 
-	// 构建最终输出列表：先放扫描结果，再追加 DB 里未被扫描覆盖的旧 pending
+	// 构建最终输出列表：先放扫描结果，再追加 DB 里未被扫描覆盖的旧 pending，
+	// 然后按分拣优先级稳定排序（high 在前，low 在后）
 	type outputEntry struct {
 		fromScan bool
 		scan     xiaohongshu.UnprocessedNotification
 		db       NotificationRecord
+		triage   NotificationRecord
 	}
 	var entries []outputEntry
 	for _, n := range result.Notifications {
-		entries = append(entries, outputEntry{fromScan: true, scan: n})
+		if autoSkipped[n.NotificationID] {
+			continue
+		}
+		entries = append(entries, outputEntry{fromScan: true, scan: n, triage: triaged[n.NotificationID]})
 	}
 	var dbOnlyCount int
 	for _, r := range dbPendingRecords {
 		if !scannedIDs[r.ID] {
-			entries = append(entries, outputEntry{fromScan: false, db: r})
+			entries = append(entries, outputEntry{fromScan: false, db: r, triage: r})
 			dbOnlyCount++
 		}
 	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return triagePriorityRank(entries[i].triage.Priority) < triagePriorityRank(entries[j].triage.Priority)
+	})
 
-	logrus.Infof("notifications.get_pending: 扫描返回 %d 条，DB 补充 %d 条旧 pending，合计 %d 条",
-		len(result.Notifications), dbOnlyCount, len(entries))
+	logrus.Infof("notifications.get_pending: 扫描返回 %d 条，规则跳过 %d 条，DB 补充 %d 条旧 pending，合计 %d 条",
+		len(result.Notifications), len(autoSkipped), dbOnlyCount, len(entries))
 
 	// 构建输出
 	var sb strings.Builder
@@ -1038,11 +1488,17 @@ func (s *AppServer) handleNotificationsGetPending(ctx context.Context, args Noti
 	if dbOnlyCount > 0 {
 		sb.WriteString(fmt.Sprintf("，DB补充旧pending %d 条", dbOnlyCount))
 	}
+	if len(autoSkipped) > 0 {
+		sb.WriteString(fmt.Sprintf("，分拣规则自动跳过 %d 条", len(autoSkipped)))
+	}
 	sb.WriteString("\n")
 
 	if result.HasMore {
 		sb.WriteString("⚠️ 扫描到的待处理通知超过单次返回上限，处理完后请再次调用。\n")
 	}
+	if result.Gap != "" {
+		sb.WriteString(fmt.Sprintf("⚠️ 通知翻页中断，更早的通知本次没有扫描到，稍后请再次调用：%s\n", result.Gap))
+	}
 	sb.WriteString("\n")
 
 	if len(entries) == 0 {
@@ -1064,20 +1520,7 @@ func (s *AppServer) handleNotificationsGetPending(ctx context.Context, args Noti
 			default:
 				tag = "全新"
 			}
-			var relationLabel string
-			switch n.RelationType {
-			case xiaohongshu.RelationCommentOnMyNote:
-				relationLabel = "评论了我的笔记"
-			case xiaohongshu.RelationReplyToMyComment:
-				relationLabel = "回复了我的评论"
-			case xiaohongshu.RelationAtOthersUnderMyComment:
-				relationLabel = "在我的评论下@了他人"
-			case xiaohongshu.RelationMentionedMe:
-				relationLabel = "在评论中@了我"
-			default:
-				relationLabel = string(n.RelationType)
-			}
-			sb.WriteString(fmt.Sprintf("--- 通知 %d [%s][%s] ---\n", i+1, tag, relationLabel))
+			sb.WriteString(fmt.Sprintf("--- 通知 %d [%s][%s] ---\n", i+1, tag, notificationRelationLabel(n.RelationType)))
 			sb.WriteString(fmt.Sprintf("notification_id: %s\n", n.NotificationID))
 			sb.WriteString(fmt.Sprintf("时间: %s\n", n.TimeCST))
 			sb.WriteString(fmt.Sprintf("用户: %s (user_id: %s)\n", n.UserNickname, n.UserID))
@@ -1118,6 +1561,14 @@ func (s *AppServer) handleNotificationsGetPending(ctx context.Context, args Noti
 			sb.WriteString(fmt.Sprintf("feed_id: %s\n", r.FeedID))
 			sb.WriteString(fmt.Sprintf("xsec_token: %s\n", r.XsecToken))
 		}
+		if t := e.triage; t.TriageRule != "" {
+			if t.Priority != "" {
+				sb.WriteString(fmt.Sprintf("优先级: %s（规则 %s）\n", t.Priority, t.TriageRule))
+			}
+			if t.SuggestedReply != "" {
+				sb.WriteString(fmt.Sprintf("建议回复（规则 %s）: %s\n", t.TriageRule, t.SuggestedReply))
+			}
+		}
 		sb.WriteString("\n")
 	}
 
@@ -1207,9 +1658,196 @@ func (s *AppServer) handleNotificationsStats(ctx context.Context) *MCPToolResult
 	sb.WriteString(fmt.Sprintf("  删除待确认 (deleted_check): %d\n", stats["deleted_check"]))
 	sb.WriteString(fmt.Sprintf("  已回复 (replied):      %d\n", stats["replied"]))
 	sb.WriteString(fmt.Sprintf("  已跳过 (skipped):      %d\n", stats["skipped"]))
+	sb.WriteString(fmt.Sprintf("  仅记录 (info，赞/收藏/关注): %d\n", stats["info"]))
 	sb.WriteString(fmt.Sprintf("上次拉取时间: %s\n", lastFetchStr))
+	if version, err := store.SchemaVersion(); err == nil {
+		sb.WriteString(fmt.Sprintf("数据库版本: v%d\n", version))
+	}
 
 	return &MCPToolResult{
 		Content: []MCPContent{{Type: "text", Text: sb.String()}},
 	}
 }
+
+// handleNotificationsSearch 查询通知历史
+func (s *AppServer) handleNotificationsSearch(_ context.Context, args NotificationsSearchArgs) *MCPToolResult {
+	statuses, err := ParseNotificationStatuses(args.Status)
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}
+	}
+
+	store, err := GetNotificationStore()
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: "初始化状态数据库失败: " + err.Error()}},
+			IsError: true,
+		}
+	}
+
+	result, err := store.SearchNotifications(NotificationQuery{
+		Statuses:      statuses,
+		RelationTypes: args.RelationType,
+		UserID:        args.UserID,
+		FeedID:        args.FeedID,
+		SinceUnix:     args.SinceUnix,
+		UntilUnix:     args.UntilUnix,
+		Keyword:       args.Keyword,
+		Offset:        args.Offset,
+		Limit:         args.Limit,
+	})
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: "查询通知失败: " + err.Error()}},
+			IsError: true,
+		}
+	}
+
+	if len(result.Records) == 0 {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("没有符合条件的通知（共 %d 条）", result.Total)}},
+		}
+	}
+
+	cst := time.FixedZone("CST", 8*3600)
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("共 %d 条，本页 %d-%d", result.Total, result.Offset+1, result.NextOffset))
+	if result.HasMore {
+		sb.WriteString(fmt.Sprintf("，next_offset=%d", result.NextOffset))
+	}
+	sb.WriteString("\n\n")
+
+	for i, r := range result.Records {
+		timeCST := time.Unix(r.NotifTimeUnix, 0).In(cst).Format("2006-01-02 15:04")
+		sb.WriteString(fmt.Sprintf("--- %d [%s][%s] ---\n", result.Offset+i+1, r.Status,
+			notificationRelationLabel(xiaohongshu.NotificationRelationType(r.RelationType))))
+		sb.WriteString(fmt.Sprintf("notification_id: %s\n", r.ID))
+		sb.WriteString(fmt.Sprintf("时间: %s\n", timeCST))
+		sb.WriteString(fmt.Sprintf("用户: %s (user_id: %s)\n", r.UserNickname, r.UserID))
+		if r.CommentContent != "" {
+			sb.WriteString(fmt.Sprintf("评论: %s\n", r.CommentContent))
+			sb.WriteString(fmt.Sprintf("comment_id: %s\n", r.CommentID))
+		}
+		if r.ReplyContent != "" {
+			sb.WriteString(fmt.Sprintf("我的回复: %s\n", r.ReplyContent))
+		}
+		if r.TriageRule != "" {
+			sb.WriteString(fmt.Sprintf("分拣规则: %s\n", r.TriageRule))
+		}
+		if r.FeedID != "" {
+			sb.WriteString(fmt.Sprintf("笔记: %s\n", truncate(r.NoteTitle, 40)))
+			sb.WriteString(fmt.Sprintf("feed_id: %s\n", r.FeedID))
+		}
+		sb.WriteString("\n")
+	}
+
+	return &MCPToolResult{
+		Content: []MCPContent{{Type: "text", Text: sb.String()}},
+	}
+}
+
+// handleNotificationRulesList 查看通知分拣规则
+func (s *AppServer) handleNotificationRulesList(_ context.Context) *MCPToolResult {
+	if s.triage == nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: "通知分拣规则未启用"}},
+			IsError: true,
+		}
+	}
+
+	rules, err := s.triage.List()
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: "读取分拣规则失败: " + err.Error()}},
+			IsError: true,
+		}
+	}
+
+	jsonData, err := json.MarshalIndent(map[string]any{
+		"path":  s.triage.Path(),
+		"rules": rules,
+	}, "", "  ")
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("读取分拣规则成功，但序列化失败: %v", err)}},
+			IsError: true,
+		}
+	}
+
+	return &MCPToolResult{
+		Content: []MCPContent{{Type: "text", Text: string(jsonData)}},
+	}
+}
+
+// handleNotificationRulesSet 新增或修改通知分拣规则
+func (s *AppServer) handleNotificationRulesSet(_ context.Context, args NotificationRuleSetArgs) *MCPToolResult {
+	if s.triage == nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: "通知分拣规则未启用"}},
+			IsError: true,
+		}
+	}
+
+	logrus.Infof("MCP: 设置通知分拣规则 - name: %s, action: %s", args.Name, args.Action)
+
+	created, err := s.triage.Set(TriageRule{
+		Name:           args.Name,
+		Disabled:       args.Disabled,
+		Keywords:       args.Keywords,
+		Pattern:        args.Pattern,
+		RelationTypes:  args.RelationTypes,
+		UserIDs:        args.UserIDs,
+		ExcludeUserIDs: args.ExcludeUserIDs,
+		FeedIDs:        args.FeedIDs,
+		NoteKeywords:   args.NoteKeywords,
+		Action:         TriageAction(args.Action),
+		Template:       args.Template,
+		Priority:       TriagePriority(args.Priority),
+	})
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: "设置分拣规则失败: " + err.Error()}},
+			IsError: true,
+		}
+	}
+
+	verb := "已更新"
+	if created {
+		verb = "已新增"
+	}
+	return &MCPToolResult{
+		Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("%s分拣规则 %s（%s）", verb, args.Name, s.triage.Path())}},
+	}
+}
+
+// handleNotificationRulesDelete 删除通知分拣规则
+func (s *AppServer) handleNotificationRulesDelete(_ context.Context, args NotificationRuleDeleteArgs) *MCPToolResult {
+	if s.triage == nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: "通知分拣规则未启用"}},
+			IsError: true,
+		}
+	}
+
+	logrus.Infof("MCP: 删除通知分拣规则 - name: %s", args.Name)
+
+	deleted, err := s.triage.Delete(args.Name)
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: "删除分拣规则失败: " + err.Error()}},
+			IsError: true,
+		}
+	}
+	if !deleted {
+		return &MCPToolResult{
+			Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("分拣规则 %s 不存在", args.Name)}},
+			IsError: true,
+		}
+	}
+
+	return &MCPToolResult{
+		Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("已删除分拣规则 %s", args.Name)}},
+	}
+}
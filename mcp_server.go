@@ -57,19 +57,107 @@ type FeedDetailArgs struct {
 	ClickMoreReplies bool   `json:"click_more_replies,omitempty" jsonschema:"【仅当load_all_comments为true时生效】是否展开二级回复。true展开子评论，false不展开（默认）"`
 	ReplyLimit       int    `json:"reply_limit,omitempty" jsonschema:"【仅当click_more_replies为true时生效】跳过回复数过多的评论。例如10表示跳过超过10条回复的，默认10"`
 	ScrollSpeed      string `json:"scroll_speed,omitempty" jsonschema:"【仅当load_all_comments为true时生效】滚动速度slow慢速、normal正常、fast快速"`
+	DownloadMedia    bool   `json:"download_media,omitempty" jsonschema:"是否将原图、实况图视频、视频和封面下载到本地，返回的media中会带local_path。默认false"`
+}
+
+// DeleteCommentArgs 删除评论的参数
+type DeleteCommentArgs struct {
+	FeedID          string `json:"feed_id" jsonschema:"小红书笔记ID"`
+	XsecToken       string `json:"xsec_token" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
+	CommentID       string `json:"comment_id" jsonschema:"要删除的评论ID，必须是当前账号发表的评论或回复"`
+	ParentCommentID string `json:"parent_comment_id,omitempty" jsonschema:"父评论ID（可选）。删除楼中楼回复时传入可加快定位"`
+}
+
+// FeedRefArgs 笔记引用
+type FeedRefArgs struct {
+	FeedID    string `json:"feed_id" jsonschema:"小红书笔记ID"`
+	XsecToken string `json:"xsec_token" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
+}
+
+// FeedDetailsBatchArgs 批量获取Feed详情的参数
+type FeedDetailsBatchArgs struct {
+	Feeds       []FeedRefArgs `json:"feeds" jsonschema:"要获取详情的笔记列表，1-50条"`
+	Concurrency int           `json:"concurrency,omitempty" jsonschema:"同时打开的页面数，默认3，最大5"`
+}
+
+// CommentRepliesArgs 获取楼中楼回复的参数
+type CommentRepliesArgs struct {
+	FeedID    string `json:"feed_id" jsonschema:"小红书笔记ID，从Feed列表获取"`
+	XsecToken string `json:"xsec_token" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
+	CommentID string `json:"comment_id" jsonschema:"顶级评论ID，从笔记详情的评论列表获取"`
+	Cursor    string `json:"cursor,omitempty" jsonschema:"分页游标（可选）。留空从第一条回复开始；传入上次返回的 cursor 继续获取后续回复"`
 }
 
 // UserProfileArgs 获取用户主页的参数
 type UserProfileArgs struct {
 	UserID    string `json:"user_id" jsonschema:"小红书用户ID，从Feed列表获取"`
 	XsecToken string `json:"xsec_token" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
+	Tab       string `json:"tab,omitempty" jsonschema:"笔记标签页（可选）：notes（笔记，默认）、collected（收藏）、liked（赞过），收藏和赞过需对方公开"`
+	MaxNotes  int    `json:"max_notes,omitempty" jsonschema:"最多返回的笔记数（可选，最大500）。设置后会滚动主页加载更多笔记；不设置则只返回首屏笔记"`
+}
+
+// FollowUserArgs 关注用户的参数
+type FollowUserArgs struct {
+	UserID    string `json:"user_id" jsonschema:"小红书用户ID，从Feed列表或评论的用户信息获取"`
+	XsecToken string `json:"xsec_token" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
+	Unfollow  bool   `json:"unfollow,omitempty" jsonschema:"是否取消关注，true为取消关注，false或未设置则为关注"`
+}
+
+// UserRelationsArgs 获取关注/粉丝列表的参数
+type UserRelationsArgs struct {
+	ListType  string `json:"list_type" jsonschema:"列表类型：followers（粉丝）或 following（关注）"`
+	UserID    string `json:"user_id,omitempty" jsonschema:"小红书用户ID（可选），不传则获取当前登录账号的列表"`
+	XsecToken string `json:"xsec_token,omitempty" jsonschema:"访问令牌，查询他人列表时必填，从Feed列表或评论的用户信息获取"`
+	Offset    int    `json:"offset,omitempty" jsonschema:"跳过的条数，翻页时传入上一页返回的 next_offset，默认0"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"本页条数，默认50，最大200"`
+}
+
+// NotificationsSearchArgs notifications_search 的参数
+type NotificationsSearchArgs struct {
+	Status       []string `json:"status,omitempty" jsonschema:"状态过滤（可选，可多选）：pending / replied / skipped / retry / deleted_check / info"`
+	RelationType []string `json:"relation_type,omitempty" jsonschema:"关系类型过滤（可选，可多选），如 comment_on_my_note、reply_to_my_comment、new_follower"`
+	UserID       string   `json:"user_id,omitempty" jsonschema:"发通知的用户ID（可选）"`
+	FeedID       string   `json:"feed_id,omitempty" jsonschema:"笔记ID（可选）"`
+	SinceUnix    int64    `json:"since_unix,omitempty" jsonschema:"通知时间下界，Unix 秒（可选）"`
+	UntilUnix    int64    `json:"until_unix,omitempty" jsonschema:"通知时间上界，Unix 秒（可选）"`
+	Keyword      string   `json:"keyword,omitempty" jsonschema:"在评论内容和我的回复内容中模糊搜索（可选）"`
+	Offset       int      `json:"offset,omitempty" jsonschema:"跳过的条数，翻页时传入上一页返回的 next_offset，默认0"`
+	Limit        int      `json:"limit,omitempty" jsonschema:"本页条数，默认20，最大100"`
+}
+
+// GetNotificationContextArgs 获取通知上下文的参数
+type GetNotificationContextArgs struct {
+	NotificationID string `json:"notification_id" jsonschema:"通知ID，从 notifications_get_pending 或 notifications_search 获取"`
+	RootCommentID  string `json:"root_comment_id,omitempty" jsonschema:"楼层的顶级评论ID（可选）。默认根据通知推断，通知评论是楼中楼回复时从评论区找到其顶级评论"`
+}
+
+// NotificationRuleSetArgs 新增或修改通知分拣规则的参数
+type NotificationRuleSetArgs struct {
+	Name           string   `json:"name" jsonschema:"规则名，已存在时替换该规则（位置不变），否则追加到末尾"`
+	Action         string   `json:"action" jsonschema:"命中后的动作：skip（直接标记为已跳过）、suggest（按模板生成建议回复）、priority（只打优先级）"`
+	Template       string   `json:"template,omitempty" jsonschema:"suggest 的回复模板，可使用 {nickname}、{note_title}"`
+	Priority       string   `json:"priority,omitempty" jsonschema:"优先级：high / normal / low。priority 动作必填，suggest 可选"`
+	Keywords       []string `json:"keywords,omitempty" jsonschema:"评论内容包含任一关键词（不区分大小写）"`
+	Pattern        string   `json:"pattern,omitempty" jsonschema:"评论内容匹配的正则（Go RE2 语法）"`
+	RelationTypes  []string `json:"relation_types,omitempty" jsonschema:"关系类型，如 comment_on_my_note、reply_to_my_comment"`
+	UserIDs        []string `json:"user_ids,omitempty" jsonschema:"只对这些用户生效（如拉黑名单）"`
+	ExcludeUserIDs []string `json:"exclude_user_ids,omitempty" jsonschema:"这些用户不受该规则影响（白名单）"`
+	FeedIDs        []string `json:"feed_ids,omitempty" jsonschema:"只对这些笔记生效"`
+	NoteKeywords   []string `json:"note_keywords,omitempty" jsonschema:"笔记标题包含任一关键词"`
+	Disabled       bool     `json:"disabled,omitempty" jsonschema:"停用规则但保留配置"`
+}
+
+// NotificationRuleDeleteArgs 删除通知分拣规则的参数
+type NotificationRuleDeleteArgs struct {
+	Name string `json:"name" jsonschema:"要删除的规则名"`
 }
 
 // PostCommentArgs 发表评论的参数
 type PostCommentArgs struct {
 	FeedID    string `json:"feed_id" jsonschema:"小红书笔记ID，从Feed列表获取"`
 	XsecToken string `json:"xsec_token" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
-	Content   string `json:"content" jsonschema:"评论内容"`
+	Content   string `json:"content" jsonschema:"评论内容，可包含平台表情代码（如 [笑哭R]），会通过表情面板插入显示为表情；@{user_id} 或 @{user_id:昵称} 会插入为真正的@提及并通知对方，无法确认提及时不发表评论"`
+	Image     string `json:"image,omitempty" jsonschema:"评论图片（可选），支持HTTP/HTTPS链接或本地绝对路径"`
 }
 
 // ReplyCommentArgs 回复评论的参数
@@ -79,7 +167,9 @@ type ReplyCommentArgs struct {
 	CommentID       string `json:"comment_id,omitempty" jsonschema:"目标评论ID，从评论列表获取"`
 	UserID          string `json:"user_id,omitempty" jsonschema:"目标评论用户ID，从评论列表获取"`
 	ParentCommentID string `json:"parent_comment_id,omitempty" jsonschema:"父评论ID（可选）。回复子评论（reply_to_my_comment / at_others_under_my_comment 类型）时传入，用于精确定位楼中楼结构；顶级评论（comment_on_my_note）无需传入"`
-	Content         string `json:"content" jsonschema:"回复内容"`
+	Content         string `json:"content" jsonschema:"回复内容，表情代码和@提及的写法同发表评论"`
+	Image           string `json:"image,omitempty" jsonschema:"回复图片（可选），支持HTTP/HTTPS链接或本地绝对路径"`
+	AllowDuplicate  bool   `json:"allow_duplicate,omitempty" jsonschema:"是否允许再次回复已经回复过的评论。默认false：回复过（不论内容是否相同）或之前的回复未确认是否发出时不发出回复，返回 [DUPLICATE_REPLY]；回复过时自动把对应通知标记为 replied"`
 }
 
 // LikeFeedArgs 点赞参数
@@ -89,6 +179,23 @@ type LikeFeedArgs struct {
 	Unlike    bool   `json:"unlike,omitempty" jsonschema:"是否取消点赞，true为取消点赞，false或未设置则为点赞"`
 }
 
+// LikeCommentArgs 点赞评论参数
+type LikeCommentArgs struct {
+	FeedID          string `json:"feed_id" jsonschema:"小红书笔记ID"`
+	XsecToken       string `json:"xsec_token" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
+	CommentID       string `json:"comment_id" jsonschema:"要点赞的评论ID，顶级评论或楼中楼回复均可"`
+	ParentCommentID string `json:"parent_comment_id,omitempty" jsonschema:"父评论ID（可选）。点赞楼中楼回复时传入可加快定位，通知中的 parent_comment_id 可直接使用"`
+	Unlike          bool   `json:"unlike,omitempty" jsonschema:"是否取消点赞，true为取消点赞，false或未设置则为点赞"`
+}
+
+// PinCommentArgs 置顶评论参数
+type PinCommentArgs struct {
+	FeedID    string `json:"feed_id" jsonschema:"小红书笔记ID，必须是当前账号发布的笔记"`
+	XsecToken string `json:"xsec_token" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
+	CommentID string `json:"comment_id" jsonschema:"要置顶的顶级评论ID（楼中楼回复不能置顶）"`
+	Unpin     bool   `json:"unpin,omitempty" jsonschema:"是否取消置顶，true为取消置顶，false或未设置则为置顶"`
+}
+
 // FavoriteFeedArgs 收藏参数
 type FavoriteFeedArgs struct {
 	FeedID     string `json:"feed_id" jsonschema:"小红书笔记ID，从Feed列表获取"`
@@ -105,6 +212,7 @@ type GetNotificationsArgs struct {
 	Cursor    string `json:"cursor,omitempty" jsonschema:"分页游标（可选）。留空获取最新通知；传入上次返回的 next_cursor 可获取更早的旧通知"`
 	Limit     int    `json:"limit,omitempty" jsonschema:"每次获取的通知数量（可选，默认20，最大20）"`
 	SinceUnix int64  `json:"since_unix,omitempty" jsonschema:"（可选）只返回此 Unix 时间戳（秒）之后的通知，自动翻页汇总所有符合条件的通知。例如 1771200000"`
+	Category  string `json:"category,omitempty" jsonschema:"通知分类（可选）：mentions评论和@（默认）、likes赞和收藏、connections新增关注"`
 }
 
 // NotificationsGetPendingArgs notifications.get_pending 的参数
@@ -122,7 +230,6 @@ type NotificationsMarkResultArgs struct {
 	ReplyContent   string `json:"reply_content,omitempty" jsonschema:"回复内容，可选，status为replied时填写"`
 }
 
-
 // InitMCPServer 初始化 MCP Server
 func InitMCPServer(appServer *AppServer) *mcp.Server {
 	// 创建 MCP Server
@@ -131,17 +238,52 @@ func InitMCPServer(appServer *AppServer) *mcp.Server {
 			Name:    "xiaohongshu-mcp",
 			Version: "2.0.0",
 		},
-		nil,
+		&mcp.ServerOptions{
+			SubscribeHandler:   validateResourceSubscription,
+			UnsubscribeHandler: func(context.Context, *mcp.UnsubscribeRequest) error { return nil },
+		},
 	)
 
 	// 注册所有工具
 	registerTools(server, appServer)
 
+	// 注册资源
+	registerResources(server, appServer)
+
 	logrus.Info("MCP Server initialized with official SDK")
 
 	return server
 }
 
+// newNotificationsResourceURI 新通知资源。订阅后每当有新通知入库都会收到
+// notifications/resources/updated，_meta.notification_ids 为本次入库的通知 ID
+const newNotificationsResourceURI = "xhs://notifications/new"
+
+// registerResources 注册 MCP 资源
+func registerResources(server *mcp.Server, appServer *AppServer) {
+	server.AddResource(
+		&mcp.Resource{
+			URI:         newNotificationsResourceURI,
+			Name:        "new_notifications",
+			Description: "最近新入库的通知（最多50条，最新的在前）。可订阅，有新通知入库时推送 resources/updated，之后读取本资源获取详情",
+			MIMEType:    "application/json",
+		},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return appServer.handleReadNewNotifications(ctx, req)
+		},
+	)
+
+	logrus.Infof("Registered %d MCP resources", 1)
+}
+
+// validateResourceSubscription 只允许订阅可推送的资源
+func validateResourceSubscription(_ context.Context, req *mcp.SubscribeRequest) error {
+	if req.Params.URI != newNotificationsResourceURI {
+		return fmt.Errorf("资源 %s 不支持订阅", req.Params.URI)
+	}
+	return nil
+}
+
 func withPanicRecovery[T any](
 	toolName string,
 	handler func(context.Context, *mcp.CallToolRequest, T) (*mcp.CallToolResult, any, error),
@@ -284,7 +426,7 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 	mcp.AddTool(server,
 		&mcp.Tool{
 			Name:        "get_feed_detail",
-			Description: "获取小红书笔记详情，返回笔记内容、图片、作者信息、互动数据（点赞/收藏/分享数）及评论列表。默认返回前10条一级评论，如需更多评论请设置load_all_comments=true",
+			Description: "获取小红书笔记详情，返回笔记内容、图片、作者信息、互动数据（点赞/收藏/分享数）及评论列表。media字段包含原图地址、实况图视频、视频流（编码/分辨率/码率）和封面帧。默认返回前10条一级评论，如需更多评论请设置load_all_comments=true",
 			Annotations: &mcp.ToolAnnotations{
 				Title:        "Get Feed Detail",
 				ReadOnlyHint: true,
@@ -295,6 +437,7 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 				"feed_id":           args.FeedID,
 				"xsec_token":        args.XsecToken,
 				"load_all_comments": args.LoadAllComments,
+				"download_media":    args.DownloadMedia,
 			}
 
 			// 只有当 load_all_comments=true 时，才处理其他参数
@@ -328,8 +471,9 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 	// 工具 8: 获取用户主页
 	mcp.AddTool(server,
 		&mcp.Tool{
-			Name:        "user_profile",
-			Description: "获取指定的小红书用户主页，返回用户基本信息，关注、粉丝、获赞量及其笔记内容",
+			Name: "user_profile",
+			Description: "获取指定的小红书用户主页，返回用户基本信息，关注、粉丝、获赞量及其笔记内容。\n" +
+				"默认只返回首屏笔记；设置 max_notes 可滚动加载更多（hasMore 表示是否还有），tab 可切换到收藏或赞过的笔记",
 			Annotations: &mcp.ToolAnnotations{
 				Title:        "User Profile",
 				ReadOnlyHint: true,
@@ -339,6 +483,8 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 			argsMap := map[string]interface{}{
 				"user_id":    args.UserID,
 				"xsec_token": args.XsecToken,
+				"tab":        args.Tab,
+				"max_notes":  args.MaxNotes,
 			}
 			result := appServer.handleUserProfile(ctx, argsMap)
 			return convertToMCPResult(result), nil, nil
@@ -360,6 +506,7 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 				"feed_id":    args.FeedID,
 				"xsec_token": args.XsecToken,
 				"content":    args.Content,
+				"image":      args.Image,
 			}
 			result := appServer.handlePostComment(ctx, argsMap)
 			return convertToMCPResult(result), nil, nil
@@ -391,6 +538,8 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 				"user_id":           args.UserID,
 				"parent_comment_id": args.ParentCommentID,
 				"content":           args.Content,
+				"image":             args.Image,
+				"allow_duplicate":   args.AllowDuplicate,
 			}
 			result := appServer.handleReplyComment(ctx, argsMap)
 			return convertToMCPResult(result), nil, nil
@@ -462,11 +611,11 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 		}),
 	)
 
-	// 工具 14: 获取通知列表（评论和回复）
+	// 工具 14: 获取通知列表（评论和@、赞和收藏、新增关注）
 	mcp.AddTool(server,
 		&mcp.Tool{
 			Name: "get_notifications",
-			Description: "获取小红书「评论和@」通知列表（按时间倒序，最新在最前）。\n" +
+			Description: "获取小红书通知列表（按时间倒序，最新在最前），category 默认为「评论和@」。\n" +
 				"每条通知包含 relation_type 字段，客观描述该评论与当前用户的关系：\n" +
 				"  - comment_on_my_note：有人直接评论了你的笔记（顶级评论）\n" +
 				"  - reply_to_my_comment：有人直接回复了你的评论（子评论）\n" +
@@ -474,7 +623,10 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 				"  - mentioned_me：有人在评论中直接 @了你（不是在你的评论下，而是在任意评论里 @你）\n" +
 				"回复评论时，用返回的 feed_id + xsec_token + comment_id 调用 reply_comment_in_feed；\n" +
 				"对于 reply_to_my_comment 类型，建议同时传入 parent_comment_id 以提高子评论定位成功率。\n" +
-				"分页：不传 cursor 获取最新页；传入返回的 next_cursor 可获取更早的旧通知。",
+				"category=likes 获取「赞和收藏」（liked_my_note / collected_my_note / liked_my_comment），\n" +
+				"category=connections 获取「新增关注」（new_follower）；这两类通知会记录到状态数据库（status=info），不需要标记处理结果。\n" +
+				"分页：不传 cursor 获取最新页；传入返回的 next_cursor 可获取更早的旧通知（会从第一页起逐页翻到该游标，期间有新通知导致游标失效时报错，需从第一页重新获取）。\n" +
+				"since_unix 自动翻页时若翻页中断，会提示未完成并给出 next_cursor，可据此续翻。",
 			Annotations: &mcp.ToolAnnotations{
 				Title:        "Get Notifications",
 				ReadOnlyHint: true,
@@ -485,6 +637,7 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 				"cursor":     args.Cursor,
 				"limit":      float64(args.Limit),
 				"since_unix": args.SinceUnix,
+				"category":   args.Category,
 			}
 			result := appServer.handleGetNotifications(ctx, argsMap)
 			return convertToMCPResult(result), nil, nil
@@ -499,14 +652,15 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 				"状态由 MCP 内部 SQLite 数据库管理，无需 Liko 传入任何 ID 列表。\n\n" +
 				"工作流程：\n" +
 				"  1. 从数据库读取 processed/retry/deleted_check 状态\n" +
-				"  2. 自动翻页扫描小红书通知，合并全新通知写入数据库\n" +
-				"  3. 返回所有待处理通知（全新 + 待重试 + 删除待确认）\n\n" +
+				"  2. 自动翻页扫描小红书通知，全新通知经分拣规则处理后写入数据库\n" +
+				"  3. 返回所有待处理通知（全新 + 待重试 + 删除待确认），被规则跳过的不返回，按优先级排序\n\n" +
 				"返回的每条通知包含：\n" +
 				"  - notification_id（处理完后必须调用 notifications_mark_result 标记）\n" +
 				"  - relation_type：comment_on_my_note / reply_to_my_comment / at_others_under_my_comment / mentioned_me\n" +
 				"  - retry_reason：\"\"=全新，\"timeout\"=重试，\"deleted_recheck\"=删除待确认\n" +
 				"  - comment_id, comment_content, parent_comment_id（子评论必填）\n" +
-				"  - user_id, user_nickname, feed_id, xsec_token, note_title\n\n" +
+				"  - user_id, user_nickname, feed_id, xsec_token, note_title\n" +
+				"  - 命中分拣规则时：优先级、建议回复（见 notification_rules_set）\n\n" +
 				"每次心跳必须对每条通知调用 notifications_mark_result 标记处理结果，否则下次心跳会重复返回。",
 			Annotations: &mcp.ToolAnnotations{
 				Title:        "Get Pending Notifications",
@@ -528,7 +682,10 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 				"  - replied：已成功回复（reply_content 填写回复内容）\n" +
 				"  - skipped：主动跳过（不需要回复，如广告、无意义评论等）\n" +
 				"  - retry：处理失败需重试（如回复超时、网络错误）\n" +
-				"  - deleted_check：评论可能已删除，下次心跳将进入详情页二次确认\n\n" +
+				"  - deleted_check：评论可能已删除，下次心跳将进入详情页二次确认\n" +
+				"    （回复失败信息中带 [COMMENT_NOT_FOUND]、[NOTE_DELETED]、[NOTE_VIOLATION]、[NOTE_NOT_FOUND]、\n" +
+				"    [NOTE_UNAVAILABLE]、[NOTE_PRIVATE] 时使用；[RATE_LIMITED]、[LOGIN_REQUIRED] 属于临时失败，使用 retry）\n" +
+				"  回复失败信息中带 [COMMENT_UNCONFIRMED] 时回复可能已经发出，先用 get_comment_replies 确认，已发出则标记 replied，否则 retry\n\n" +
 				"必须在每次处理通知后调用，否则下次心跳会重复返回该通知。",
 			Annotations: &mcp.ToolAnnotations{
 				Title:           "Mark Notification Result",
@@ -557,7 +714,229 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 		}),
 	)
 
-	logrus.Infof("Registered %d MCP tools", 17)
+	// 工具 18: 获取楼中楼回复
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "get_comment_replies",
+			Description: "获取笔记下某条顶级评论的全部楼中楼回复（子评论），按时间顺序返回。\n" +
+				"get_feed_detail 只包含页面预加载的少量子评论，需要完整阅读某个热门讨论串时使用本工具。\n" +
+				"回复过多时单次可能无法取完，此时 has_more=true，传入返回的 cursor 继续获取。",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "Get Comment Replies",
+				ReadOnlyHint: true,
+			},
+		},
+		withPanicRecovery("get_comment_replies", func(ctx context.Context, req *mcp.CallToolRequest, args CommentRepliesArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleGetCommentReplies(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 19: 批量获取Feed详情
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "get_feed_details_batch",
+			Description: "批量获取多条小红书笔记详情（最多50条），共用一个浏览器并发处理，比逐条调用 get_feed_detail 快得多。\n" +
+				"每条结果单独给出 success 和 error（如笔记已删除、私密），部分失败时仍返回其余笔记的详情。只返回页面预加载的评论。",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "Get Feed Details Batch",
+				ReadOnlyHint: true,
+			},
+		},
+		withPanicRecovery("get_feed_details_batch", func(ctx context.Context, req *mcp.CallToolRequest, args FeedDetailsBatchArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleGetFeedDetailsBatch(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 20: 删除评论
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "delete_comment",
+			Description: "删除当前账号在笔记下发表的评论或楼中楼回复，用于撤回发错的内容。\n" +
+				"删除前核对评论作者，不是当前账号发表的评论返回 [NOT_COMMENT_AUTHOR] 且不会删除（笔记作者删除他人评论请使用 delete_comment_as_owner）；\n" +
+				"删除结果以小红书删除接口的响应为准；评论不存在时返回 [COMMENT_NOT_FOUND]。",
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Delete Comment",
+				DestructiveHint: boolPtr(true),
+			},
+		},
+		withPanicRecovery("delete_comment", func(ctx context.Context, req *mcp.CallToolRequest, args DeleteCommentArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleDeleteComment(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 21: 点赞评论
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "like_comment",
+			Description: "为笔记下的某条评论或楼中楼回复点赞或取消点赞（如已点赞将跳过点赞，如未点赞将跳过取消点赞）。\n" +
+				"处理通知时可对有价值的评论点赞，comment_id / parent_comment_id 直接使用通知中的字段。",
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Like Comment",
+				DestructiveHint: boolPtr(true),
+			},
+		},
+		withPanicRecovery("like_comment", func(ctx context.Context, req *mcp.CallToolRequest, args LikeCommentArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleLikeComment(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 22: 置顶评论（笔记作者）
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "pin_comment",
+			Description: "置顶或取消置顶自己笔记下的一条顶级评论，用于展示优质回答。仅笔记作者可用，\n" +
+				"当前账号不是笔记作者时返回 [NOT_NOTE_OWNER]。置顶新评论会替换原有置顶。",
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Pin Comment",
+				DestructiveHint: boolPtr(true),
+			},
+		},
+		withPanicRecovery("pin_comment", func(ctx context.Context, req *mcp.CallToolRequest, args PinCommentArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handlePinComment(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 23: 删除他人评论（笔记作者）
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "delete_comment_as_owner",
+			Description: "以笔记作者身份删除自己笔记下其他用户的评论或回复，用于清理垃圾评论。仅笔记作者可用，\n" +
+				"当前账号不是笔记作者时返回 [NOT_NOTE_OWNER]。删除自己的评论请使用 delete_comment。",
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Delete Comment As Owner",
+				DestructiveHint: boolPtr(true),
+			},
+		},
+		withPanicRecovery("delete_comment_as_owner", func(ctx context.Context, req *mcp.CallToolRequest, args DeleteCommentArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleDeleteCommentAsOwner(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 24: 关注/取消关注用户
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "follow_user",
+			Description: "关注或取消关注小红书用户。已是目标状态时直接返回成功，操作后会校验关注状态",
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Follow User",
+				DestructiveHint: boolPtr(true),
+			},
+		},
+		withPanicRecovery("follow_user", func(ctx context.Context, req *mcp.CallToolRequest, args FollowUserArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleFollowUser(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 25: 关注/粉丝列表
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "user_relations",
+			Description: "分页获取关注或粉丝列表，包含用户ID、昵称、头像、xsec_token、是否互关和关注时间（接口提供时）。\n" +
+				"不传 user_id 时获取当前账号的列表；查询他人列表需对方公开。网页端需从头滚动加载，offset 越大越慢",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "User Relations",
+				ReadOnlyHint: true,
+			},
+		},
+		withPanicRecovery("user_relations", func(ctx context.Context, req *mcp.CallToolRequest, args UserRelationsArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleUserRelations(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 26: 通知历史查询
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "notifications_search",
+			Description: "查询状态数据库中的通知历史（含已回复、已跳过的通知和回复内容），按通知时间倒序分页返回。\n" +
+				"可按状态、关系类型、用户、笔记、时间范围过滤，keyword 在评论内容和回复内容中模糊搜索。只查本地记录，不打开浏览器",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "Search Notifications",
+				ReadOnlyHint: true,
+			},
+		},
+		withPanicRecovery("notifications_search", func(ctx context.Context, req *mcp.CallToolRequest, args NotificationsSearchArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleNotificationsSearch(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 27: 获取通知上下文
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "get_notification_context",
+			Description: "获取一条评论类通知的完整上下文，用于在多轮对话的楼层里保持回复连贯：\n" +
+				"所在笔记的摘要、楼层的顶级评论、楼层内全部回复（按时间顺序，包括我们自己的回复）、以及之前对该用户的回复记录（来自状态数据库）。\n" +
+				"通知需已在状态数据库中；会打开浏览器加载笔记页",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "Get Notification Context",
+				ReadOnlyHint: true,
+			},
+		},
+		withPanicRecovery("get_notification_context", func(ctx context.Context, req *mcp.CallToolRequest, args GetNotificationContextArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleGetNotificationContext(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 28: 查看通知分拣规则
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "notification_rules_list",
+			Description: "查看通知分拣规则（按匹配顺序）和规则文件路径。新通知入库时按顺序匹配，第一条命中的规则生效",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "List Notification Rules",
+				ReadOnlyHint: true,
+			},
+		},
+		withPanicRecovery("notification_rules_list", func(ctx context.Context, req *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleNotificationRulesList(ctx)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 29: 新增或修改通知分拣规则
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "notification_rules_set",
+			Description: "新增或修改一条通知分拣规则并写入规则文件。规则在新通知入库时（notifications_get_pending 返回前、后台轮询）执行：\n" +
+				"skip 直接标记为已跳过不再返回；suggest 附带模板生成的建议回复；priority 打上优先级，get_pending 按优先级排序。\n" +
+				"设置的条件全部满足才命中，至少需要一个条件；已入库的通知不会重新分拣",
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Set Notification Rule",
+				DestructiveHint: boolPtr(false),
+			},
+		},
+		withPanicRecovery("notification_rules_set", func(ctx context.Context, req *mcp.CallToolRequest, args NotificationRuleSetArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleNotificationRulesSet(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 30: 删除通知分拣规则
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "notification_rules_delete",
+			Description: "删除一条通知分拣规则并写入规则文件",
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Delete Notification Rule",
+				DestructiveHint: boolPtr(true),
+			},
+		},
+		withPanicRecovery("notification_rules_delete", func(ctx context.Context, req *mcp.CallToolRequest, args NotificationRuleDeleteArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleNotificationRulesDelete(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	logrus.Infof("Registered %d MCP tools", 30)
 }
 
 // convertToMCPResult 将自定义的 MCPToolResult 转换为官方 SDK 的格式
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// notificationSubscriberBuffer 每个订阅者的缓冲，消费太慢时丢弃超出的通知
+	notificationSubscriberBuffer = 64
+	// notificationRecentSize 保留最近新入库通知的条数，供 MCP 资源读取
+	notificationRecentSize = 50
+)
+
+// NotificationHub 把新入库的通知广播给 SSE 连接，并保留最近的若干条
+type NotificationHub struct {
+	mu          sync.Mutex
+	subscribers map[chan NotificationRecord]struct{}
+	recent      []NotificationRecord
+	closed      bool
+}
+
+// NewNotificationHub 创建通知广播器
+func NewNotificationHub() *NotificationHub {
+	return &NotificationHub{subscribers: make(map[chan NotificationRecord]struct{})}
+}
+
+// Subscribe 订阅新通知，返回接收通道和取消订阅函数。广播器关闭后通道会被关闭
+func (h *NotificationHub) Subscribe() (<-chan NotificationRecord, func()) {
+	ch := make(chan NotificationRecord, notificationSubscriberBuffer)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	h.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[ch]; ok {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Publish 广播一批新入库的通知（按入库顺序）
+func (h *NotificationHub) Publish(records []NotificationRecord) {
+	if len(records) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+
+	h.recent = append(h.recent, records...)
+	if len(h.recent) > notificationRecentSize {
+		h.recent = append([]NotificationRecord{}, h.recent[len(h.recent)-notificationRecentSize:]...)
+	}
+
+	for ch := range h.subscribers {
+		for _, r := range records {
+			select {
+			case ch <- r:
+			default:
+				logrus.Warnf("通知订阅者消费过慢，丢弃通知 %s", r.ID)
+			}
+		}
+	}
+}
+
+// Recent 返回最近新入库的通知，最新的在前
+func (h *NotificationHub) Recent() []NotificationRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	records := make([]NotificationRecord, 0, len(h.recent))
+	for i := len(h.recent) - 1; i >= 0; i-- {
+		records = append(records, h.recent[i])
+	}
+	return records
+}
+
+// Close 关闭所有订阅，服务关闭时调用
+func (h *NotificationHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for ch := range h.subscribers {
+		close(ch)
+	}
+	h.subscribers = nil
+}
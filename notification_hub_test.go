@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func hubRecords(prefix string, n int) []NotificationRecord {
+	records := make([]NotificationRecord, n)
+	for i := range records {
+		records[i] = NotificationRecord{ID: fmt.Sprintf("%s%d", prefix, i)}
+	}
+	return records
+}
+
+// drain 读出通道里已有的通知，不等待
+func drain(ch <-chan NotificationRecord) []string {
+	var ids []string
+	for {
+		select {
+		case r, ok := <-ch:
+			if !ok {
+				return ids
+			}
+			ids = append(ids, r.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestNotificationHubPublishSubscribe(t *testing.T) {
+	hub := NewNotificationHub()
+	a, unsubscribeA := hub.Subscribe()
+	b, unsubscribeB := hub.Subscribe()
+	defer unsubscribeB()
+
+	hub.Publish(hubRecords("n", 2))
+	hub.Publish(nil)
+	require.Equal(t, []string{"n0", "n1"}, drain(a))
+	require.Equal(t, []string{"n0", "n1"}, drain(b))
+
+	// 取消订阅后通道关闭，不再收到通知；重复取消不会 panic
+	unsubscribeA()
+	unsubscribeA()
+	_, ok := <-a
+	require.False(t, ok)
+
+	hub.Publish([]NotificationRecord{{ID: "n2"}})
+	require.Equal(t, []string{"n2"}, drain(b))
+
+	// 订阅之前发布的通知不会补发
+	c, unsubscribeC := hub.Subscribe()
+	defer unsubscribeC()
+	require.Empty(t, drain(c))
+}
+
+func TestNotificationHubRecent(t *testing.T) {
+	hub := NewNotificationHub()
+	require.Empty(t, hub.Recent())
+
+	hub.Publish(hubRecords("a", 3))
+	require.Equal(t, []string{"a2", "a1", "a0"}, recordIDs(hub.Recent()), "最新的在前")
+
+	// 超过上限时只保留最近的 notificationRecentSize 条
+	hub.Publish(hubRecords("b", notificationRecentSize))
+	recent := hub.Recent()
+	require.Len(t, recent, notificationRecentSize)
+	require.Equal(t, fmt.Sprintf("b%d", notificationRecentSize-1), recent[0].ID)
+	require.Equal(t, "b0", recent[len(recent)-1].ID)
+
+	// 返回的是副本，修改不影响广播器
+	recent[0].ID = "changed"
+	require.NotEqual(t, "changed", hub.Recent()[0].ID)
+}
+
+func TestNotificationHubSlowSubscriberDoesNotBlock(t *testing.T) {
+	hub := NewNotificationHub()
+	slow, unsubscribeSlow := hub.Subscribe()
+	defer unsubscribeSlow()
+	fast, unsubscribeFast := hub.Subscribe()
+	defer unsubscribeFast()
+
+	// slow 一直不读，超出缓冲的通知被丢弃，Publish 不阻塞
+	done := make(chan struct{})
+	var fastIDs []string
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			hub.Publish(hubRecords(fmt.Sprintf("p%d-", i), notificationSubscriberBuffer))
+			fastIDs = append(fastIDs, drain(fast)...)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("消费慢的订阅者阻塞了 Publish")
+	}
+
+	require.Len(t, fastIDs, 3*notificationSubscriberBuffer, "读得及时的订阅者不受影响")
+	slowIDs := drain(slow)
+	require.Len(t, slowIDs, notificationSubscriberBuffer)
+	require.Equal(t, "p0-0", slowIDs[0], "保留最早进入缓冲的通知")
+	require.Len(t, hub.Recent(), notificationRecentSize)
+}
+
+func TestNotificationHubClose(t *testing.T) {
+	hub := NewNotificationHub()
+	ch, unsubscribe := hub.Subscribe()
+	hub.Publish([]NotificationRecord{{ID: "n0"}})
+
+	hub.Close()
+	hub.Close()
+
+	// 已缓冲的通知仍可读出，之后通道关闭
+	r, ok := <-ch
+	require.True(t, ok)
+	require.Equal(t, "n0", r.ID)
+	_, ok = <-ch
+	require.False(t, ok)
+	unsubscribe()
+
+	// 关闭后的发布被忽略，新订阅直接拿到已关闭的通道
+	hub.Publish([]NotificationRecord{{ID: "n1"}})
+	require.Equal(t, []string{"n0"}, recordIDs(hub.Recent()))
+	late, unsubscribeLate := hub.Subscribe()
+	_, ok = <-late
+	require.False(t, ok)
+	unsubscribeLate()
+}
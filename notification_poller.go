@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 单次轮询扫描的超时，避免浏览器卡住时一直占着扫描锁
+const pollScanTimeout = 10 * time.Minute
+
+// NotificationPushConfig 后台通知轮询和 webhook 推送配置
+type NotificationPushConfig struct {
+	// PollInterval 后台轮询间隔，<=0 时不启动轮询
+	PollInterval time.Duration
+	Webhook      WebhookConfig
+}
+
+// NotificationPoller 定时扫描通知并写入 DB，新通知由 scanNotifications 交给 webhook 推送
+type NotificationPoller struct {
+	app      *AppServer
+	interval time.Duration
+	// store 获取通知存储，默认使用全局实例
+	store func() (*NotificationStore, error)
+}
+
+// NewNotificationPoller 创建通知轮询器
+func NewNotificationPoller(app *AppServer, interval time.Duration) *NotificationPoller {
+	return &NotificationPoller{app: app, interval: interval, store: GetNotificationStore}
+}
+
+// Run 立即扫描一次，之后按间隔扫描，直到 ctx 取消
+func (p *NotificationPoller) Run(ctx context.Context) {
+	logrus.Infof("通知后台轮询已启动，间隔 %s", p.interval)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.poll(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			logrus.Info("通知后台轮询已停止")
+			return
+		}
+	}
+}
+
+func (p *NotificationPoller) poll(ctx context.Context) {
+	store, err := p.store()
+	if err != nil {
+		logrus.Errorf("通知轮询：初始化状态数据库失败: %v", err)
+		return
+	}
+
+	scanCtx, cancel := context.WithTimeout(ctx, pollScanTimeout)
+	defer cancel()
+
+	result, inserted, err := p.app.scanNotifications(scanCtx, store, notificationScanOptions{})
+	if err != nil {
+		logrus.Warnf("通知轮询失败: %v", err)
+		return
+	}
+	logrus.Infof("通知轮询：扫描 %d 页 %d 条，新入库 %d 条", result.PagesScanned, result.TotalScanned, len(inserted))
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+// fakeNotificationPages 按调用次数依次返回预设的扫描结果
+type fakeNotificationPages struct {
+	mu    sync.Mutex
+	pages [][]xiaohongshu.UnprocessedNotification
+	calls int
+}
+
+func (f *fakeNotificationPages) fetch(context.Context, map[string]bool, map[string]bool, map[string]bool, int, int, int64, int) (*xiaohongshu.UnprocessedNotificationsResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	notifications := f.pages[f.calls]
+	f.calls++
+	return &xiaohongshu.UnprocessedNotificationsResult{Notifications: notifications, TotalScanned: len(notifications), PagesScanned: 1}, nil
+}
+
+func newNotification(id string, reason xiaohongshu.RetryReason) xiaohongshu.UnprocessedNotification {
+	return xiaohongshu.UnprocessedNotification{
+		NotificationID: id,
+		RelationType:   xiaohongshu.RelationCommentOnMyNote,
+		RetryReason:    reason,
+		CommentID:      "c-" + id,
+		FeedID:         "f1",
+		TimeUnix:       time.Now().Unix(),
+	}
+}
+
+func TestNotificationPollerDispatchesOnlyNewNotifications(t *testing.T) {
+	store := newTestStore(t)
+	insertTestNotification(t, store, NotificationRecord{ID: "retry-1", Status: StatusRetry, FeedID: "f1", CommentID: "c-retry-1"})
+
+	srv, requests := newWebhookServer(t, func(int32, http.ResponseWriter, *http.Request) {})
+
+	pages := &fakeNotificationPages{pages: [][]xiaohongshu.UnprocessedNotification{
+		{newNotification("n2", xiaohongshu.RetryReasonNone), newNotification("n1", xiaohongshu.RetryReasonNone), newNotification("retry-1", xiaohongshu.RetryReasonTimeout)},
+		// 第二次扫描：n1/n2 仍是 pending 所以会再次返回，只有 n3 是新的
+		{newNotification("n3", xiaohongshu.RetryReasonNone), newNotification("n2", xiaohongshu.RetryReasonNone), newNotification("n1", xiaohongshu.RetryReasonNone)},
+		{newNotification("n3", xiaohongshu.RetryReasonNone)},
+	}}
+
+	app := NewAppServer(nil)
+	app.fetchNotifications = pages.fetch
+	app.webhook = NewWebhookNotifier(WebhookConfig{URLs: []string{srv.URL}})
+	sub, unsubscribe := app.notificationHub.Subscribe()
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	app.webhook.Start(ctx)
+
+	poller := NewNotificationPoller(app, time.Hour)
+	poller.store = func() (*NotificationStore, error) { return store, nil }
+	for range pages.pages {
+		poller.poll(ctx)
+	}
+	cancel()
+	app.webhook.Wait()
+
+	require.Equal(t, 3, pages.calls)
+	var events []WebhookEvent
+	for _, r := range requests() {
+		var event WebhookEvent
+		require.NoError(t, json.Unmarshal(r.body, &event))
+		events = append(events, event)
+	}
+	require.Len(t, events, 2, "没有新通知的扫描不推送")
+	require.Equal(t, []string{"n2", "n1"}, recordIDs(events[0].Notifications))
+	require.Equal(t, []string{"n3"}, recordIDs(events[1].Notifications))
+
+	var published []string
+	for len(sub) > 0 {
+		published = append(published, (<-sub).ID)
+	}
+	require.Equal(t, []string{"n2", "n1", "n3"}, published)
+	require.Equal(t, []string{"n3", "n1", "n2"}, recordIDs(app.notificationHub.Recent()))
+
+	// 重试通知保持原状态
+	r, err := store.GetRecord("retry-1")
+	require.NoError(t, err)
+	require.Equal(t, StatusRetry, r.Status)
+}
+
+func recordIDs(records []NotificationRecord) []string {
+	ids := make([]string, 0, len(records))
+	for _, r := range records {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
@@ -0,0 +1,97 @@
+package downloader
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/h2non/filetype"
+	"github.com/pkg/errors"
+)
+
+// MediaDownloader 媒体下载器，支持图片和视频，视频以流式写入磁盘
+type MediaDownloader struct {
+	savePath   string
+	httpClient *http.Client
+}
+
+// NewMediaDownloader 创建媒体下载器
+func NewMediaDownloader(savePath string) (*MediaDownloader, error) {
+	if err := os.MkdirAll(savePath, 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create save path")
+	}
+
+	return &MediaDownloader{
+		savePath: savePath,
+		httpClient: &http.Client{
+			// 视频体积较大，超时放宽
+			Timeout: 5 * time.Minute,
+		},
+	}, nil
+}
+
+// DownloadMedia 下载媒体文件，返回本地文件路径。
+// 同一 URL 重复下载时直接返回已有文件。
+func (d *MediaDownloader) DownloadMedia(mediaURL string) (string, error) {
+	if !IsImageURL(mediaURL) {
+		return "", errors.New("invalid media URL format")
+	}
+
+	hash := sha256.Sum256([]byte(mediaURL))
+	baseName := fmt.Sprintf("media_%x", hash[:8])
+	if matches, _ := filepath.Glob(filepath.Join(d.savePath, baseName+".*")); len(matches) > 0 {
+		return matches[0], nil
+	}
+
+	req, err := http.NewRequest("GET", mediaURL, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Referer", "https://www.xiaohongshu.com/")
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to download media from %s", mediaURL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download failed with status %d for URL: %s", resp.StatusCode, mediaURL)
+	}
+
+	// 只读取文件头判断类型，正文直接流式写入
+	body := bufio.NewReaderSize(resp.Body, 8192)
+	head, _ := body.Peek(261)
+	kind, _ := filetype.Match(head)
+	if !filetype.IsImage(head) && !filetype.IsVideo(head) {
+		return "", fmt.Errorf("downloaded file is not an image or video: %s", mediaURL)
+	}
+
+	filePath := filepath.Join(d.savePath, baseName+"."+kind.Extension)
+	tmpPath := filePath + ".part"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create file")
+	}
+
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return "", errors.Wrap(err, "failed to save media")
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return "", errors.Wrap(err, "failed to save media")
+	}
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		return "", errors.Wrap(err, "failed to save media")
+	}
+	return filePath, nil
+}
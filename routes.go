@@ -47,10 +47,17 @@ func setupRoutes(appServer *AppServer) *gin.Engine {
 		api.GET("/feeds/search", appServer.searchFeedsHandler)
 		api.POST("/feeds/search", appServer.searchFeedsHandler)
 		api.POST("/feeds/detail", appServer.getFeedDetailHandler)
+		api.POST("/feeds/detail/batch", appServer.getFeedDetailsBatchHandler)
 		api.POST("/user/profile", appServer.userProfileHandler)
+		api.POST("/user/follow", appServer.followUserHandler)
 		api.POST("/feeds/comment", appServer.postCommentHandler)
 		api.POST("/feeds/comment/reply", appServer.replyCommentHandler)
+		api.POST("/feeds/comment/delete", appServer.deleteCommentHandler)
 		api.GET("/user/me", appServer.myProfileHandler)
+		api.GET("/notifications/stream", appServer.notificationsStreamHandler)
+		api.POST("/notifications/search", appServer.notificationsSearchHandler)
+		api.GET("/notifications/export", appServer.notificationsExportHandler)
+		api.POST("/notifications/import", appServer.notificationsImportHandler)
 	}
 
 	return router
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"sync"
@@ -14,6 +15,7 @@ import (
 	"github.com/xpzouying/xiaohongshu-mcp/browser"
 	"github.com/xpzouying/xiaohongshu-mcp/configs"
 	"github.com/xpzouying/xiaohongshu-mcp/cookies"
+	myerrors "github.com/xpzouying/xiaohongshu-mcp/errors"
 	"github.com/xpzouying/xiaohongshu-mcp/pkg/downloader"
 	"github.com/xpzouying/xiaohongshu-mcp/pkg/xhsutil"
 	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
@@ -92,6 +94,8 @@ type UserProfileResponse struct {
 	UserBasicInfo xiaohongshu.UserBasicInfo      `json:"userBasicInfo"`
 	Interactions  []xiaohongshu.UserInteractions `json:"interactions"`
 	Feeds         []xiaohongshu.Feed             `json:"feeds"`
+	Tab           xiaohongshu.ProfileNotesTab    `json:"tab,omitempty"`
+	HasMore       bool                           `json:"hasMore,omitempty"`
 }
 
 // DeleteCookies 删除 cookies 文件，用于登录重置
@@ -412,13 +416,269 @@ func (s *XiaohongshuService) GetFeedDetailWithConfig(ctx context.Context, feedID
 	response := &FeedDetailResponse{
 		FeedID: feedID,
 		Data:   result,
+		Media:  xiaohongshu.ExtractNoteMedia(&result.Note),
 	}
 
 	return response, nil
 }
 
-// UserProfile 获取用户信息
-func (s *XiaohongshuService) UserProfile(ctx context.Context, userID, xsecToken string) (*UserProfileResponse, error) {
+// DownloadFeedMedia 将笔记媒体下载到本地，下载结果回填到 media 的 LocalPath 字段。
+// 视频只下载一路最合适的流；单个资源失败不影响其他资源，失败原因记录在 DownloadErrors。
+func (s *XiaohongshuService) DownloadFeedMedia(media *xiaohongshu.NoteMedia) error {
+	d, err := downloader.NewMediaDownloader(configs.GetMediaPath())
+	if err != nil {
+		return err
+	}
+
+	download := func(url string, dst *string) {
+		if url == "" {
+			return
+		}
+		path, err := d.DownloadMedia(url)
+		if err != nil {
+			logrus.Warnf("下载媒体失败: %v", err)
+			media.DownloadErrors = append(media.DownloadErrors, err.Error())
+			return
+		}
+		*dst = path
+	}
+
+	for i := range media.Images {
+		img := &media.Images[i]
+		download(img.OriginalURL, &img.LocalPath)
+		// 原图 CDN 偶尔拒绝访问，退回展示图
+		if img.LocalPath == "" && img.DefaultURL != img.OriginalURL {
+			download(img.DefaultURL, &img.LocalPath)
+		}
+		download(img.LivePhotoURL, &img.LivePhotoLocalPath)
+	}
+	for i := range media.Covers {
+		download(media.Covers[i].URL, &media.Covers[i].LocalPath)
+	}
+	if best := media.BestVideo(); best != nil {
+		download(best.MasterURL, &best.LocalPath)
+	}
+
+	return nil
+}
+
+const (
+	defaultBatchConcurrency = 3
+	maxBatchConcurrency     = 5
+)
+
+// GetFeedDetailsBatch 批量获取Feed详情。
+// 共用一个浏览器，最多同时打开 concurrency 个页面，每个页面依次处理分配到的笔记；
+// 单条失败（包括笔记不可访问）只记录在对应结果中，不影响其他笔记。
+func (s *XiaohongshuService) GetFeedDetailsBatch(ctx context.Context, items []FeedDetailBatchItem, concurrency int) *FeedDetailBatchResponse {
+	b := newBrowser()
+	defer b.Close()
+
+	return runFeedDetailBatch(ctx, items, concurrency, func() (feedDetailFetchFunc, func()) {
+		page := b.NewPage()
+		action := xiaohongshu.NewFeedDetailAction(page)
+		fetch := func(ctx context.Context, item FeedDetailBatchItem) (*xiaohongshu.FeedDetailResponse, error) {
+			return action.GetFeedDetailWithConfig(ctx, item.FeedID, item.XsecToken, false, xiaohongshu.DefaultCommentLoadConfig())
+		}
+		return fetch, func() { page.Close() }
+	})
+}
+
+// feedDetailFetchFunc 在一个 worker 上获取一条笔记详情
+type feedDetailFetchFunc func(ctx context.Context, item FeedDetailBatchItem) (*xiaohongshu.FeedDetailResponse, error)
+
+// runFeedDetailBatch 按 concurrency（默认 3，最大 5）启动 worker 并分发笔记，结果顺序与 items 一致。
+// newWorker 为每个 worker 创建获取函数和释放函数，worker 退出时调用释放函数。
+func runFeedDetailBatch(ctx context.Context, items []FeedDetailBatchItem, concurrency int, newWorker func() (feedDetailFetchFunc, func())) *FeedDetailBatchResponse {
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	if concurrency > maxBatchConcurrency {
+		concurrency = maxBatchConcurrency
+	}
+	if concurrency > len(items) {
+		concurrency = len(items)
+	}
+
+	results := make([]FeedDetailBatchResult, len(items))
+	for i, item := range items {
+		results[i].FeedID = item.FeedID
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		fetch, release := newWorker()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer release()
+
+			for i := range jobs {
+				results[i] = fetchBatchFeedDetail(ctx, fetch, items[i])
+			}
+		}()
+	}
+
+	for i := range items {
+		if err := ctx.Err(); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	response := &FeedDetailBatchResponse{Results: results, Total: len(results)}
+	for _, r := range results {
+		if r.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	return response
+}
+
+// fetchBatchFeedDetail 获取单条笔记详情，页面操作的 panic 转为该条的失败结果
+func fetchBatchFeedDetail(ctx context.Context, fetch feedDetailFetchFunc, item FeedDetailBatchItem) (result FeedDetailBatchResult) {
+	result.FeedID = item.FeedID
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.Errorf("批量获取详情 panic: feed=%s %v", item.FeedID, r)
+			result = FeedDetailBatchResult{FeedID: item.FeedID, Error: fmt.Sprintf("页面操作异常: %v", r)}
+		}
+	}()
+
+	detail, err := fetch(ctx, item)
+	if err != nil {
+		logrus.Warnf("批量获取详情失败: feed=%s %v", item.FeedID, err)
+		result.Error = err.Error()
+		if code, _, ok := myerrors.Code(err); ok {
+			result.ErrorCode = code
+		}
+		return result
+	}
+
+	result.Success = true
+	result.Data = detail
+	result.Media = xiaohongshu.ExtractNoteMedia(&detail.Note)
+	return result
+}
+
+// GetCommentReplies 获取指定评论下的楼中楼回复（子评论分页）
+func (s *XiaohongshuService) GetCommentReplies(ctx context.Context, feedID, xsecToken, commentID, cursor string) (*xiaohongshu.CommentRepliesResult, error) {
+	b := newBrowser()
+	defer b.Close()
+
+	page := b.NewPage()
+	defer page.Close()
+
+	action := xiaohongshu.NewFeedDetailAction(page)
+	return action.GetCommentReplies(ctx, feedID, xsecToken, commentID, cursor)
+}
+
+// previousRepliesLimit 通知上下文中最多返回的历史回复条数
+const previousRepliesLimit = 20
+
+// NotificationContextResponse 一条通知所在评论楼层的完整上下文
+type NotificationContextResponse struct {
+	Notification NotificationRecord      `json:"notification"`
+	Note         xiaohongshu.NoteSummary `json:"note"`
+	// RootComment 楼层的顶级评论
+	RootComment *xiaohongshu.Comment `json:"root_comment"`
+	// Replies 楼层内的全部回复（包括我们自己的），按时间顺序
+	Replies      []xiaohongshu.Comment `json:"replies"`
+	RepliesTotal string                `json:"replies_total"`
+	// RepliesTruncated 楼层太长，Replies 未取完
+	RepliesTruncated bool `json:"replies_truncated"`
+	// PreviousReplies 之前对该用户的回复（状态库中 replied 的通知，最新的在前，最多 20 条）
+	PreviousReplies []NotificationRecord `json:"previous_replies"`
+}
+
+// GetNotificationContext 获取通知所在的评论楼层、笔记摘要以及之前对该用户的回复。
+// rootCommentID 为空时根据通知记录推断顶级评论。
+func (s *XiaohongshuService) GetNotificationContext(ctx context.Context, notificationID, rootCommentID string) (*NotificationContextResponse, error) {
+	store, err := GetNotificationStore()
+	if err != nil {
+		return nil, fmt.Errorf("初始化状态数据库失败: %w", err)
+	}
+	record, err := store.GetRecord(notificationID)
+	if err != nil {
+		return nil, fmt.Errorf("读取通知记录失败: %w", err)
+	}
+	if record == nil {
+		return nil, fmt.Errorf("通知 %s 不在状态数据库中，请先通过 notifications_get_pending 获取", notificationID)
+	}
+	if record.CommentID == "" {
+		return nil, fmt.Errorf("通知 %s 不是评论类通知，没有评论楼层", notificationID)
+	}
+	if record.FeedID == "" || record.XsecToken == "" {
+		return nil, fmt.Errorf("通知 %s 缺少 feed_id 或 xsec_token，无法打开笔记", notificationID)
+	}
+	if rootCommentID == "" {
+		rootCommentID = resolveThreadRoot(store, record)
+	}
+
+	b := newBrowser()
+	defer b.Close()
+
+	page := b.NewPage()
+	defer page.Close()
+
+	action := xiaohongshu.NewFeedDetailAction(page)
+	thread, err := action.GetCommentThread(ctx, record.FeedID, record.XsecToken, rootCommentID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := []NotificationRecord{}
+	if record.UserID != "" {
+		result, err := store.SearchNotifications(NotificationQuery{
+			UserID:   record.UserID,
+			Statuses: []NotificationStatus{StatusReplied},
+			Limit:    previousRepliesLimit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("读取历史回复失败: %w", err)
+		}
+		for _, r := range result.Records {
+			if r.ID != record.ID && r.ReplyContent != "" {
+				previous = append(previous, r)
+			}
+		}
+	}
+
+	return &NotificationContextResponse{
+		Notification:     *record,
+		Note:             thread.Note,
+		RootComment:      thread.Replies.Parent,
+		Replies:          thread.Replies.Replies,
+		RepliesTotal:     thread.Replies.Total,
+		RepliesTruncated: thread.Replies.HasMore,
+		PreviousReplies:  previous,
+	}, nil
+}
+
+// resolveThreadRoot 推断通知评论所在楼层的顶级评论。被回复的是本服务发表的楼中楼回复时，从发表记录中取其顶级评论；
+// 其他情况（直接评论笔记、回复别人的楼中楼评论等）返回通知评论本身，由 GetCommentThread 从评论 API 中找到其顶级评论
+func resolveThreadRoot(store *NotificationStore, record *NotificationRecord) string {
+	if record.ParentCommentID == "" {
+		return record.CommentID
+	}
+	posted, err := store.GetPostedComment(record.ParentCommentID)
+	if err != nil {
+		logrus.Warnf("读取已发表评论 %s 失败: %v", record.ParentCommentID, err)
+	}
+	if posted != nil && posted.ParentCommentID != "" {
+		return posted.ParentCommentID
+	}
+	return record.CommentID
+}
+
+// UserProfile 获取用户信息，opts 控制加载哪个标签页的笔记以及最多加载多少条
+func (s *XiaohongshuService) UserProfile(ctx context.Context, userID, xsecToken string, opts xiaohongshu.UserProfileOptions) (*UserProfileResponse, error) {
 	b := newBrowser()
 	defer b.Close()
 
@@ -427,7 +687,7 @@ func (s *XiaohongshuService) UserProfile(ctx context.Context, userID, xsecToken
 
 	action := xiaohongshu.NewUserProfileAction(page)
 
-	result, err := action.UserProfile(ctx, userID, xsecToken)
+	result, err := action.UserProfile(ctx, userID, xsecToken, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -435,14 +695,54 @@ func (s *XiaohongshuService) UserProfile(ctx context.Context, userID, xsecToken
 		UserBasicInfo: result.UserBasicInfo,
 		Interactions:  result.Interactions,
 		Feeds:         result.Feeds,
+		Tab:           result.Tab,
+		HasMore:       result.HasMore,
 	}
 
 	return response, nil
 
 }
 
-// PostCommentToFeed 发表评论到Feed
-func (s *XiaohongshuService) PostCommentToFeed(ctx context.Context, feedID, xsecToken, content string) (*PostCommentResponse, error) {
+// GetUserRelations 获取用户的关注或粉丝列表，userID 为空时获取当前登录账号的列表
+func (s *XiaohongshuService) GetUserRelations(ctx context.Context, userID, xsecToken string, listType xiaohongshu.RelationListType, offset, limit int) (*xiaohongshu.RelationListResult, error) {
+	b := newBrowser()
+	defer b.Close()
+
+	page := b.NewPage()
+	defer page.Close()
+
+	action := xiaohongshu.NewUserRelationAction(page)
+	return action.GetRelationList(ctx, userID, xsecToken, listType, offset, limit)
+}
+
+// FollowUser 关注或取消关注用户，已是目标状态时直接返回成功
+func (s *XiaohongshuService) FollowUser(ctx context.Context, userID, xsecToken string, unfollow bool) (*FollowUserResponse, error) {
+	b := newBrowser()
+	defer b.Close()
+
+	page := b.NewPage()
+	defer page.Close()
+
+	action := xiaohongshu.NewFollowAction(page)
+	if unfollow {
+		if err := action.Unfollow(ctx, userID, xsecToken); err != nil {
+			return nil, err
+		}
+		return &FollowUserResponse{UserID: userID, Following: false, Success: true, Message: "取消关注成功或未关注"}, nil
+	}
+	if err := action.Follow(ctx, userID, xsecToken); err != nil {
+		return nil, err
+	}
+	return &FollowUserResponse{UserID: userID, Following: true, Success: true, Message: "关注成功或已关注"}, nil
+}
+
+// PostCommentToFeed 发表评论到Feed，image 为可选的评论图片（URL 或本地路径）
+func (s *XiaohongshuService) PostCommentToFeed(ctx context.Context, feedID, xsecToken, content, image string) (*PostCommentResponse, error) {
+	imagePath, err := s.processCommentImage(image)
+	if err != nil {
+		return nil, err
+	}
+
 	b := newBrowser()
 	defer b.Close()
 
@@ -451,11 +751,51 @@ func (s *XiaohongshuService) PostCommentToFeed(ctx context.Context, feedID, xsec
 
 	action := xiaohongshu.NewCommentFeedAction(page)
 
-	if err := action.PostComment(ctx, feedID, xsecToken, content); err != nil {
+	posted, err := action.PostComment(ctx, feedID, xsecToken, content, imagePath)
+	if err != nil {
 		return nil, err
 	}
 
-	return &PostCommentResponse{FeedID: feedID, Success: true, Message: "评论发表成功"}, nil
+	s.recordPostedComment(PostedCommentRecord{
+		CommentID: posted.ID,
+		FeedID:    feedID,
+		XsecToken: xsecToken,
+		Content:   content,
+	})
+
+	return &PostCommentResponse{
+		FeedID:    feedID,
+		CommentID: posted.ID,
+		Success:   true,
+		Message:   "评论发表成功",
+	}, nil
+}
+
+// recordPostedComment 记录发表成功的评论，供之后删除时补全定位信息。记录失败只打日志
+func (s *XiaohongshuService) recordPostedComment(r PostedCommentRecord) {
+	if r.CommentID == "" {
+		return
+	}
+	store, err := GetNotificationStore()
+	if err != nil {
+		logrus.Warnf("记录已发表评论失败: %v", err)
+		return
+	}
+	if err := store.RecordPostedComment(r); err != nil {
+		logrus.Warnf("记录已发表评论失败: %v", err)
+	}
+}
+
+// processCommentImage 处理评论图片，URL 先下载到本地；image 为空时返回空路径
+func (s *XiaohongshuService) processCommentImage(image string) (string, error) {
+	if image == "" {
+		return "", nil
+	}
+	paths, err := s.processImages([]string{image})
+	if err != nil {
+		return "", fmt.Errorf("处理评论图片失败: %w", err)
+	}
+	return paths[0], nil
 }
 
 // LikeFeed 点赞笔记
@@ -488,6 +828,66 @@ func (s *XiaohongshuService) UnlikeFeed(ctx context.Context, feedID, xsecToken s
 	return &ActionResult{FeedID: feedID, Success: true, Message: "取消点赞成功或未点赞"}, nil
 }
 
+// PinComment 置顶或取消置顶自己笔记下的评论（仅笔记作者）
+func (s *XiaohongshuService) PinComment(ctx context.Context, feedID, xsecToken, commentID string, unpin bool) (*ActionResult, error) {
+	b := newBrowser()
+	defer b.Close()
+
+	page := b.NewPage()
+	defer page.Close()
+
+	action := xiaohongshu.NewCommentFeedAction(page)
+	if unpin {
+		if err := action.UnpinComment(ctx, feedID, xsecToken, commentID); err != nil {
+			return nil, err
+		}
+		return &ActionResult{FeedID: feedID, Success: true, Message: "取消置顶成功或未置顶"}, nil
+	}
+	if err := action.PinComment(ctx, feedID, xsecToken, commentID); err != nil {
+		return nil, err
+	}
+	return &ActionResult{FeedID: feedID, Success: true, Message: "置顶成功或已置顶"}, nil
+}
+
+// DeleteCommentAsOwner 以笔记作者身份删除自己笔记下他人的评论
+func (s *XiaohongshuService) DeleteCommentAsOwner(ctx context.Context, feedID, xsecToken, commentID, parentCommentID string) (*DeleteCommentResponse, error) {
+	b := newBrowser()
+	defer b.Close()
+
+	page := b.NewPage()
+	defer page.Close()
+
+	action := xiaohongshu.NewCommentFeedAction(page)
+	if err := action.DeleteCommentAsOwner(ctx, feedID, xsecToken, commentID, parentCommentID); err != nil {
+		return nil, err
+	}
+
+	return &DeleteCommentResponse{
+		FeedID:    feedID,
+		CommentID: commentID,
+		Success:   true,
+		Message:   "评论删除成功",
+	}, nil
+}
+
+// LikeComment 点赞或取消点赞评论
+func (s *XiaohongshuService) LikeComment(ctx context.Context, feedID, xsecToken, commentID, parentCommentID string, unlike bool) (*ActionResult, error) {
+	b := newBrowser()
+	defer b.Close()
+
+	page := b.NewPage()
+	defer page.Close()
+
+	action := xiaohongshu.NewCommentFeedAction(page)
+	if err := action.LikeComment(ctx, feedID, xsecToken, commentID, parentCommentID, !unlike); err != nil {
+		return nil, err
+	}
+	if unlike {
+		return &ActionResult{FeedID: feedID, Success: true, Message: "取消点赞评论成功或未点赞"}, nil
+	}
+	return &ActionResult{FeedID: feedID, Success: true, Message: "点赞评论成功或已点赞"}, nil
+}
+
 // FavoriteFeed 收藏笔记
 func (s *XiaohongshuService) FavoriteFeed(ctx context.Context, feedID, xsecToken string) (*ActionResult, error) {
 	b := newBrowser()
@@ -518,10 +918,68 @@ func (s *XiaohongshuService) UnfavoriteFeed(ctx context.Context, feedID, xsecTok
 	return &ActionResult{FeedID: feedID, Success: true, Message: "取消收藏成功或未收藏"}, nil
 }
 
+// replyTargetLocks 按 (feed, comment) 串行化回复，避免并发调用（如后台轮询与 agent 心跳）同时通过去重检查
+var replyTargetLocks = newKeyedMutex()
+
+// keyedMutex 按 key 加锁，不再使用的 key 自动清理
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: make(map[string]*keyedLock)}
+}
+
+// lock 锁住 key，返回解锁函数
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	l := k.locks[key]
+	if l == nil {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		k.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
 // ReplyCommentToFeed 回复指定评论
 // parentCommentID 为可选参数：当目标评论是子评论（comment/comment 类型）时，
 // 传入父评论 ID 可帮助浏览器先展开父评论的"查看回复"，再定位子评论，提高成功率。
-func (s *XiaohongshuService) ReplyCommentToFeed(ctx context.Context, feedID, xsecToken, commentID, userID, parentCommentID, content string) (*ReplyCommentResponse, error) {
+// image 为可选的评论图片（URL 或本地路径）。
+// 同一条评论的回复串行执行，发出回复前先在本地数据库记录本次尝试：回复过同一条评论（不论内容），
+// 或之前的尝试未确认是否发出（提交后未捕获到响应、进程中途退出）时返回 ErrDuplicateReply，
+// allowDuplicate 为 true 时只给出警告；回复成功后把针对该评论的待处理通知标记为 replied。
+func (s *XiaohongshuService) ReplyCommentToFeed(ctx context.Context, feedID, xsecToken, commentID, userID, parentCommentID, content, image string, allowDuplicate bool) (*ReplyCommentResponse, error) {
+	unlock := replyTargetLocks.lock(feedID + "/" + commentID)
+	defer unlock()
+
+	reservation, warning, err := s.reserveReply(feedID, commentID, content, allowDuplicate)
+	if err != nil {
+		return nil, err
+	}
+
+	imagePath, err := s.processCommentImage(image)
+	if err != nil {
+		reservation.cancel()
+		return nil, err
+	}
+
 	b := newBrowser()
 	defer b.Close()
 
@@ -530,16 +988,179 @@ func (s *XiaohongshuService) ReplyCommentToFeed(ctx context.Context, feedID, xse
 
 	action := xiaohongshu.NewCommentFeedAction(page)
 
-	if err := action.ReplyToComment(ctx, feedID, xsecToken, commentID, userID, parentCommentID, content); err != nil {
+	posted, err := action.ReplyToComment(ctx, feedID, xsecToken, commentID, userID, parentCommentID, content, imagePath)
+	if err != nil {
+		if errors.Is(err, myerrors.ErrCommentUnconfirmed) {
+			// 回复可能已经发出，保留记录，之后的重试按重复回复拦截
+			reservation.finish(ReplyGuardUnconfirmed, "")
+		} else {
+			reservation.cancel()
+		}
 		return nil, err
 	}
+	reservation.finish(ReplyGuardPosted, posted.ID)
+
+	// 回复挂在顶级评论下：回复子评论时是其父评论，回复顶级评论时就是该评论
+	replyParentID := parentCommentID
+	if replyParentID == "" {
+		replyParentID = commentID
+	}
+	s.recordPostedComment(PostedCommentRecord{
+		CommentID:       posted.ID,
+		FeedID:          feedID,
+		XsecToken:       xsecToken,
+		ParentCommentID: replyParentID,
+		TargetCommentID: commentID,
+		Content:         content,
+	})
+	marked := markCommentReplied(reservation.store, feedID, commentID, content)
 
 	return &ReplyCommentResponse{
+		FeedID:              feedID,
+		CommentID:           posted.ID,
+		TargetCommentID:     commentID,
+		TargetUserID:        userID,
+		Warning:             warning,
+		NotificationsMarked: marked,
+		Success:             true,
+		Message:             "评论回复成功",
+	}, nil
+}
+
+// reserveReply 取全局数据库执行 reserveReply，数据库不可用时不拦截、不记录
+func (s *XiaohongshuService) reserveReply(feedID, commentID, content string, allowDuplicate bool) (*replyReservation, string, error) {
+	if commentID == "" {
+		return &replyReservation{}, "", nil
+	}
+	store, err := GetNotificationStore()
+	if err != nil {
+		logrus.Warnf("回复去重检查跳过: %v", err)
+		return &replyReservation{}, "", nil
+	}
+	return reserveReply(store, feedID, commentID, content, allowDuplicate)
+}
+
+// replyReservation 发出回复前写入 reply_guard 的一次尝试，store 为 nil 时不做任何记录
+type replyReservation struct {
+	store *NotificationStore
+	id    int64
+}
+
+// finish 记录回复的结果
+func (r *replyReservation) finish(status ReplyGuardStatus, replyCommentID string) {
+	if r.store == nil {
+		return
+	}
+	if err := r.store.FinishReply(r.id, status, replyCommentID); err != nil {
+		logrus.Warnf("记录回复结果失败: %v", err)
+	}
+}
+
+// cancel 回复确定没有发出时删除记录，之后可以正常重试
+func (r *replyReservation) cancel() {
+	if r.store == nil {
+		return
+	}
+	if err := r.store.CancelReply(r.id); err != nil {
+		logrus.Warnf("删除回复记录失败: %v", err)
+	}
+}
+
+// reserveReply 检查是否回复过同一条评论，并在发出回复前记录本次尝试。
+// 之前有记录（不论内容是否相同，包括未确认是否发出的尝试）时返回 ErrDuplicateReply，
+// 并用之前确认发出的回复补标记对应的通知；allowDuplicate 时放行，只返回警告。数据库出错时不拦截
+func reserveReply(store *NotificationStore, feedID, commentID, content string, allowDuplicate bool) (*replyReservation, string, error) {
+	id, previous, err := store.ReserveReply(ReplyGuardRecord{
 		FeedID:          feedID,
 		TargetCommentID: commentID,
-		TargetUserID:    userID,
-		Success:         true,
-		Message:         "评论回复成功",
+		ContentHash:     replyContentHash(content),
+		Content:         content,
+	}, allowDuplicate)
+	if err != nil {
+		logrus.Warnf("回复去重检查跳过: %v", err)
+		return &replyReservation{}, "", nil
+	}
+	reservation := &replyReservation{store: store, id: id}
+	if len(previous) == 0 {
+		return reservation, "", nil
+	}
+
+	last := previous[0]
+	lastAt := time.Unix(last.CreatedAt, 0).Format("2006-01-02 15:04:05")
+	if allowDuplicate {
+		warning := fmt.Sprintf("该评论已于 %s 回复过（共 %d 次），按 allow_duplicate 再次回复", lastAt, len(previous))
+		logrus.Warnf("评论 %s: %s", commentID, warning)
+		return reservation, warning, nil
+	}
+
+	// 上次回复后可能没来得及标记通知，用实际发出的回复内容补上；未确认是否发出时不标记
+	for _, r := range previous {
+		if r.Status == ReplyGuardPosted {
+			if n := markCommentReplied(store, feedID, commentID, r.Content); n > 0 {
+				logrus.Infof("重复回复被拦截，已将评论 %s 的 %d 条通知标记为 replied", commentID, n)
+			}
+			break
+		}
+	}
+
+	sameContent := ""
+	if last.ContentHash == replyContentHash(content) {
+		sameContent = "，内容与本次相同"
+	}
+	var detail string
+	switch last.Status {
+	case ReplyGuardUnconfirmed:
+		detail = fmt.Sprintf("已于 %s 提交过回复但未确认是否发出%s，请先查看评论区", lastAt, sameContent)
+	case ReplyGuardPending:
+		detail = fmt.Sprintf("于 %s 开始的回复没有正常结束（可能正在回复或进程中途退出），回复可能已发出%s", lastAt, sameContent)
+	default:
+		detail = fmt.Sprintf("已于 %s 回复过（回复 ID: %s%s）", lastAt, last.ReplyCommentID, sameContent)
+	}
+	return nil, "", fmt.Errorf("%w: 评论 %s %s，确需再次回复请设置 allow_duplicate",
+		myerrors.ErrDuplicateReply, commentID, detail)
+}
+
+// markCommentReplied 把针对该评论的待处理通知标记为 replied，返回标记的通知条数；store 为 nil 时不做处理
+func markCommentReplied(store *NotificationStore, feedID, commentID, content string) int {
+	if store == nil || commentID == "" {
+		return 0
+	}
+	n, err := store.MarkRepliedByComment(feedID, commentID, content)
+	if err != nil {
+		logrus.Warnf("标记通知为已回复失败: %v", err)
+		return 0
+	}
+	return n
+}
+
+// DeleteComment 删除当前账号发表的评论或回复
+// 未传 parentCommentID 时，若评论是本服务发表的，从发表记录中补全。
+func (s *XiaohongshuService) DeleteComment(ctx context.Context, feedID, xsecToken, commentID, parentCommentID string) (*DeleteCommentResponse, error) {
+	if parentCommentID == "" {
+		if store, err := GetNotificationStore(); err == nil {
+			if record, err := store.GetPostedComment(commentID); err == nil && record != nil {
+				parentCommentID = record.ParentCommentID
+			}
+		}
+	}
+
+	b := newBrowser()
+	defer b.Close()
+
+	page := b.NewPage()
+	defer page.Close()
+
+	action := xiaohongshu.NewCommentFeedAction(page)
+
+	if err := action.DeleteComment(ctx, feedID, xsecToken, commentID, parentCommentID); err != nil {
+		return nil, err
+	}
+
+	return &DeleteCommentResponse{
+		FeedID:    feedID,
+		CommentID: commentID,
+		Success:   true,
+		Message:   "评论删除成功",
 	}, nil
 }
 
@@ -573,10 +1194,10 @@ func withBrowserPage(fn func(*rod.Page) error) error {
 	return fn(page)
 }
 
-// GetNotifications 获取通知列表（评论和回复）
+// GetNotifications 获取指定分类的通知列表（评论和@、赞和收藏、新增关注）
 // cursor 为空时获取最新通知，非空时获取下一页（通过滚动触发）
 // limit 为每次获取的数量（最大 20，默认 20）
-func (s *XiaohongshuService) GetNotifications(ctx context.Context, cursor string, limit int) (*xiaohongshu.NotificationsResult, error) {
+func (s *XiaohongshuService) GetNotifications(ctx context.Context, category xiaohongshu.NotificationCategory, cursor string, limit int) (*xiaohongshu.NotificationsResult, error) {
 	notificationsMu.Lock()
 	defer notificationsMu.Unlock()
 
@@ -587,12 +1208,12 @@ func (s *XiaohongshuService) GetNotifications(ctx context.Context, cursor string
 	defer page.Close()
 
 	action := xiaohongshu.NewNotificationsAction(page)
-	return action.GetNotifications(ctx, cursor, limit)
+	return action.GetNotifications(ctx, category, cursor, limit)
 }
 
-// GetNotificationsSince 获取指定时间之后的所有通知（自动翻页）
+// GetNotificationsSince 获取指定分类在指定时间之后的所有通知（自动翻页）
 // sinceUnix 为 Unix 时间戳（秒），0 表示获取所有
-func (s *XiaohongshuService) GetNotificationsSince(ctx context.Context, sinceUnix int64) (*xiaohongshu.NotificationsResult, error) {
+func (s *XiaohongshuService) GetNotificationsSince(ctx context.Context, category xiaohongshu.NotificationCategory, sinceUnix int64) (*xiaohongshu.NotificationsResult, error) {
 	notificationsMu.Lock()
 	defer notificationsMu.Unlock()
 
@@ -603,7 +1224,7 @@ func (s *XiaohongshuService) GetNotificationsSince(ctx context.Context, sinceUni
 	defer page.Close()
 
 	action := xiaohongshu.NewNotificationsAction(page)
-	return action.GetNotificationsSince(ctx, sinceUnix)
+	return action.GetNotificationsSince(ctx, category, sinceUnix)
 }
 
 // GetUnprocessedNotifications 获取需要处理的通知（自动翻页+去重）
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	myerrors "github.com/xpzouying/xiaohongshu-mcp/errors"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+func batchItems(n int) []FeedDetailBatchItem {
+	items := make([]FeedDetailBatchItem, n)
+	for i := range items {
+		items[i] = FeedDetailBatchItem{FeedID: fmt.Sprintf("feed-%d", i), XsecToken: "token"}
+	}
+	return items
+}
+
+// fakeBatchWorkers 所有 worker 共用同一个获取函数，记录创建和释放的 worker 数
+type fakeBatchWorkers struct {
+	fetch    feedDetailFetchFunc
+	created  atomic.Int32
+	released atomic.Int32
+}
+
+func (w *fakeBatchWorkers) newWorker() (feedDetailFetchFunc, func()) {
+	w.created.Add(1)
+	return w.fetch, func() { w.released.Add(1) }
+}
+
+func TestRunFeedDetailBatchKeepsOrderAndReportsItemErrors(t *testing.T) {
+	items := batchItems(6)
+	workers := &fakeBatchWorkers{fetch: func(_ context.Context, item FeedDetailBatchItem) (*xiaohongshu.FeedDetailResponse, error) {
+		switch item.FeedID {
+		case "feed-1":
+			return nil, myerrors.NewNoteInaccessibleError("该笔记已被删除")
+		case "feed-3":
+			return nil, errors.New("网络错误")
+		case "feed-4":
+			panic("element not found")
+		}
+		// 第一条耗时更长，完成顺序与输入顺序不同
+		if item.FeedID == "feed-0" {
+			time.Sleep(30 * time.Millisecond)
+		}
+		return &xiaohongshu.FeedDetailResponse{Note: xiaohongshu.FeedDetail{NoteID: item.FeedID}}, nil
+	}}
+
+	resp := runFeedDetailBatch(context.Background(), items, 3, workers.newWorker)
+
+	require.Equal(t, 6, resp.Total)
+	require.Equal(t, 3, resp.Succeeded)
+	require.Equal(t, 3, resp.Failed)
+	for i, r := range resp.Results {
+		require.Equal(t, items[i].FeedID, r.FeedID, "结果顺序应与请求一致")
+	}
+	for _, i := range []int{0, 2, 5} {
+		require.True(t, resp.Results[i].Success)
+		require.Equal(t, items[i].FeedID, resp.Results[i].Data.Note.NoteID)
+		require.NotNil(t, resp.Results[i].Media)
+	}
+
+	require.False(t, resp.Results[1].Success)
+	require.Equal(t, "NOTE_DELETED", resp.Results[1].ErrorCode)
+	require.Equal(t, "网络错误", resp.Results[3].Error)
+	require.Empty(t, resp.Results[3].ErrorCode)
+	require.Contains(t, resp.Results[4].Error, "element not found", "panic 转为该条的失败结果")
+	require.Nil(t, resp.Results[4].Data)
+
+	require.Equal(t, int32(3), workers.created.Load())
+	require.Equal(t, int32(3), workers.released.Load())
+}
+
+func TestRunFeedDetailBatchConcurrencyCap(t *testing.T) {
+	tests := []struct {
+		name        string
+		items       int
+		concurrency int
+		want        int32
+	}{
+		{name: "默认", items: 10, concurrency: 0, want: defaultBatchConcurrency},
+		{name: "超过上限", items: 10, concurrency: 20, want: maxBatchConcurrency},
+		{name: "不超过笔记数", items: 2, concurrency: 5, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var running, peak int32
+			workers := &fakeBatchWorkers{fetch: func(_ context.Context, item FeedDetailBatchItem) (*xiaohongshu.FeedDetailResponse, error) {
+				mu.Lock()
+				running++
+				if running > peak {
+					peak = running
+				}
+				mu.Unlock()
+				time.Sleep(20 * time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return &xiaohongshu.FeedDetailResponse{}, nil
+			}}
+
+			resp := runFeedDetailBatch(context.Background(), batchItems(tt.items), tt.concurrency, workers.newWorker)
+			require.Equal(t, tt.items, resp.Succeeded)
+			require.Equal(t, tt.want, workers.created.Load())
+			require.LessOrEqual(t, peak, tt.want)
+		})
+	}
+}
+
+func TestRunFeedDetailBatchCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	workers := &fakeBatchWorkers{fetch: func(context.Context, FeedDetailBatchItem) (*xiaohongshu.FeedDetailResponse, error) {
+		t.Fatal("已取消时不应再获取")
+		return nil, nil
+	}}
+
+	resp := runFeedDetailBatch(ctx, batchItems(3), 2, workers.newWorker)
+	require.Equal(t, 3, resp.Failed)
+	for _, r := range resp.Results {
+		require.Equal(t, context.Canceled.Error(), r.Error)
+	}
+}
+
+func TestReserveReplyGuard(t *testing.T) {
+	store := newTestStore(t)
+	for _, r := range []NotificationRecord{
+		{ID: "pending", Status: StatusPending, FeedID: "f1", CommentID: "c1"},
+		{ID: "retry", Status: StatusRetry, FeedID: "f1", CommentID: "c1"},
+		{ID: "deleted-check", Status: StatusDeletedCheck, FeedID: "f1", CommentID: "c1"},
+		{ID: "no-feed", Status: StatusPending, CommentID: "c1"},
+	} {
+		insertTestNotification(t, store, r)
+	}
+
+	reservation, warning, err := reserveReply(store, "f1", "c1", "谢谢支持", false)
+	require.NoError(t, err)
+	require.Empty(t, warning)
+	reservation.finish(ReplyGuardPosted, "r1")
+
+	// 回复过的评论再次回复被拒绝，通知用实际发出的内容标记为 replied
+	_, _, err = reserveReply(store, "f1", "c1", "换个说法", false)
+	require.ErrorIs(t, err, myerrors.ErrDuplicateReply)
+	require.Contains(t, err.Error(), "r1")
+	for _, id := range []string{"pending", "retry", "deleted-check", "no-feed"} {
+		r, err := store.GetRecord(id)
+		require.NoError(t, err)
+		require.Equal(t, StatusReplied, r.Status, id)
+		require.Equal(t, "谢谢支持", r.ReplyContent, id)
+	}
+
+	// allow_duplicate 放行并给出警告
+	reservation, warning, err = reserveReply(store, "f1", "c1", "换个说法", true)
+	require.NoError(t, err)
+	require.Contains(t, warning, "共 1 次")
+	reservation.finish(ReplyGuardPosted, "r2")
+
+	replies, err := store.FindReplies("f1", "c1")
+	require.NoError(t, err)
+	require.Len(t, replies, 2)
+}
+
+func TestReserveReplyUnfinishedAttempts(t *testing.T) {
+	store := newTestStore(t)
+	insertTestNotification(t, store, NotificationRecord{ID: "n1", Status: StatusPending, FeedID: "f1", CommentID: "c1"})
+
+	// 提交后未确认：之后的重试按重复拦截，通知不标记
+	reservation, _, err := reserveReply(store, "f1", "c1", "谢谢", false)
+	require.NoError(t, err)
+	reservation.finish(ReplyGuardUnconfirmed, "")
+	_, _, err = reserveReply(store, "f1", "c1", "谢谢", false)
+	require.ErrorIs(t, err, myerrors.ErrDuplicateReply)
+	require.Contains(t, err.Error(), "未确认")
+	r, err := store.GetRecord("n1")
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, r.Status)
+
+	// 提交过程中进程退出：记录停留在 pending，同样拦截
+	reservation, _, err = reserveReply(store, "f1", "c2", "谢谢", false)
+	require.NoError(t, err)
+	_, _, err = reserveReply(store, "f1", "c2", "谢谢", false)
+	require.ErrorIs(t, err, myerrors.ErrDuplicateReply)
+
+	// 确定没有发出时删除记录，可以重试
+	reservation.cancel()
+	_, _, err = reserveReply(store, "f1", "c2", "谢谢", false)
+	require.NoError(t, err)
+}
+
+func TestReserveReplyConcurrent(t *testing.T) {
+	store := newTestStore(t)
+	locks := newKeyedMutex()
+
+	var wg sync.WaitGroup
+	var posted, refused atomic.Int32
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := locks.lock("f1/c1")
+			defer unlock()
+
+			reservation, _, err := reserveReply(store, "f1", "c1", "谢谢", false)
+			if err != nil {
+				refused.Add(1)
+				return
+			}
+			time.Sleep(5 * time.Millisecond) // 模拟发出回复
+			reservation.finish(ReplyGuardPosted, "r1")
+			posted.Add(1)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), posted.Load())
+	require.Equal(t, int32(7), refused.Load())
+	require.Empty(t, locks.locks, "解锁后不保留 key")
+}
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
 	_ "modernc.org/sqlite"
 )
 
@@ -21,6 +25,7 @@ const (
 	StatusSkipped      NotificationStatus = "skipped"       // 已跳过（不需要回复）
 	StatusRetry        NotificationStatus = "retry"         // 待重试（上次超时/报错）
 	StatusDeletedCheck NotificationStatus = "deleted_check" // 待二次确认（首次判断为已删除）
+	StatusInfo         NotificationStatus = "info"          // 仅记录（赞、收藏、新增关注等不需要回复的通知）
 )
 
 // NotificationRecord 通知记录
@@ -41,12 +46,85 @@ type NotificationRecord struct {
 	ReplyContent    string             `json:"reply_content"`
 	UpdatedAt       int64              `json:"updated_at"`
 	CreatedAt       int64              `json:"created_at"`
+	// 以下三项由分拣规则在入库时填写，未命中规则时为空
+	Priority       TriagePriority `json:"priority,omitempty"`
+	SuggestedReply string         `json:"suggested_reply,omitempty"`
+	TriageRule     string         `json:"triage_rule,omitempty"`
+}
+
+// PostedCommentRecord 本服务发表的评论或回复，用于之后删除等操作时补全定位信息
+type PostedCommentRecord struct {
+	CommentID string `json:"comment_id"`
+	FeedID    string `json:"feed_id"`
+	XsecToken string `json:"xsec_token"`
+	// ParentCommentID 回复所在的顶级评论 ID，顶级评论为空
+	ParentCommentID string `json:"parent_comment_id"`
+	// TargetCommentID 被回复的评论 ID，顶级评论为空
+	TargetCommentID string `json:"target_comment_id"`
+	Content         string `json:"content"`
+	CreatedAt       int64  `json:"created_at"`
+}
+
+// ReplyGuardStatus 一次回复尝试的状态
+type ReplyGuardStatus string
+
+const (
+	// ReplyGuardPending 发出回复前写入，之后进程退出或崩溃时停留在该状态，回复可能已经发出
+	ReplyGuardPending ReplyGuardStatus = "pending"
+	// ReplyGuardUnconfirmed 已提交但没有捕获到平台响应，回复可能已经发出
+	ReplyGuardUnconfirmed ReplyGuardStatus = "unconfirmed"
+	// ReplyGuardPosted 平台确认回复已发出
+	ReplyGuardPosted ReplyGuardStatus = "posted"
+)
+
+// ReplyGuardRecord 一次回复尝试，用于防止进程重启或并发调用时重复回复同一条评论
+type ReplyGuardRecord struct {
+	ID              int64            `json:"id"`
+	FeedID          string           `json:"feed_id"`
+	TargetCommentID string           `json:"target_comment_id"`
+	ContentHash     string           `json:"content_hash"`
+	Content         string           `json:"content"` // 回复内容，v5 之前的记录为空
+	Status          ReplyGuardStatus `json:"status"`
+	// ReplyCommentID 新回复的 ID，未确认时为空
+	ReplyCommentID string `json:"reply_comment_id"`
+	CreatedAt      int64  `json:"created_at"`
+}
+
+// replyContentHash 回复内容的摘要，忽略首尾空白
+func replyContentHash(content string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(content)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// notificationColumns notifications 表的完整列，与 scanNotificationRecord 的顺序一致
+const notificationColumns = `id, status, retry_count, feed_id, xsec_token, comment_id,
+		       parent_comment_id, comment_content, user_id, user_nickname,
+		       note_title, relation_type, notif_time_unix, reply_content,
+		       updated_at, created_at, priority, suggested_reply, triage_rule`
+
+// scanNotificationRecord 读取一行 notificationColumns
+func scanNotificationRecord(row interface{ Scan(...any) error }) (*NotificationRecord, error) {
+	r := &NotificationRecord{}
+	var status, priority string
+	if err := row.Scan(
+		&r.ID, &status, &r.RetryCount, &r.FeedID, &r.XsecToken,
+		&r.CommentID, &r.ParentCommentID, &r.CommentContent,
+		&r.UserID, &r.UserNickname, &r.NoteTitle, &r.RelationType,
+		&r.NotifTimeUnix, &r.ReplyContent, &r.UpdatedAt, &r.CreatedAt,
+		&priority, &r.SuggestedReply, &r.TriageRule,
+	); err != nil {
+		return nil, err
+	}
+	r.Status = NotificationStatus(status)
+	r.Priority = TriagePriority(priority)
+	return r, nil
 }
 
 // NotificationStore 通知状态存储
 type NotificationStore struct {
-	db *sql.DB
-	mu sync.Mutex
+	db     *sql.DB
+	dbPath string
+	mu     sync.Mutex
 }
 
 var globalStore *NotificationStore
@@ -76,7 +154,11 @@ func GetNotificationStore() (*NotificationStore, error) {
 }
 
 func getDBPath() string {
-	// 与二进制文件同目录
+	// 优先使用 -db-path / XHS_DB_PATH 指定的路径
+	if p := configs.GetDBPath(); p != "" {
+		return p
+	}
+	// 默认与二进制文件同目录
 	exe, err := os.Executable()
 	if err != nil {
 		return "notifications.db"
@@ -92,7 +174,7 @@ func newNotificationStore(dbPath string) (*NotificationStore, error) {
 
 	db.SetMaxOpenConns(1) // SQLite 单连接避免锁竞争
 
-	store := &NotificationStore{db: db}
+	store := &NotificationStore{db: db, dbPath: dbPath}
 	if err := store.migrate(); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("数据库迁移失败: %w", err)
@@ -101,47 +183,15 @@ func newNotificationStore(dbPath string) (*NotificationStore, error) {
 	return store, nil
 }
 
-func (s *NotificationStore) migrate() error {
-	_, err := s.db.Exec(`
-		CREATE TABLE IF NOT EXISTS notifications (
-			id               TEXT    PRIMARY KEY,
-			status           TEXT    NOT NULL DEFAULT 'pending',
-			retry_count      INTEGER NOT NULL DEFAULT 0,
-			feed_id          TEXT    NOT NULL DEFAULT '',
-			xsec_token       TEXT    NOT NULL DEFAULT '',
-			comment_id       TEXT    NOT NULL DEFAULT '',
-			parent_comment_id TEXT   NOT NULL DEFAULT '',
-			comment_content  TEXT    NOT NULL DEFAULT '',
-			user_id          TEXT    NOT NULL DEFAULT '',
-			user_nickname    TEXT    NOT NULL DEFAULT '',
-			note_title       TEXT    NOT NULL DEFAULT '',
-			relation_type    TEXT    NOT NULL DEFAULT '',
-			notif_time_unix  INTEGER NOT NULL DEFAULT 0,
-			reply_content    TEXT    NOT NULL DEFAULT '',
-			updated_at       INTEGER NOT NULL DEFAULT 0,
-			created_at       INTEGER NOT NULL DEFAULT 0
-		);
-
-		CREATE INDEX IF NOT EXISTS idx_status ON notifications(status);
-		CREATE INDEX IF NOT EXISTS idx_notif_time ON notifications(notif_time_unix);
-		CREATE INDEX IF NOT EXISTS idx_updated_at ON notifications(updated_at);
-
-		CREATE TABLE IF NOT EXISTS meta (
-			key   TEXT PRIMARY KEY,
-			value TEXT NOT NULL
-		);
-	`)
-	return err
-}
-
-// UpsertNotifications 批量插入新通知（已存在的跳过，不覆盖已有状态）
-func (s *NotificationStore) UpsertNotifications(records []NotificationRecord) error {
+// UpsertNotifications 批量插入新通知（已存在的跳过，不覆盖已有状态），返回本次真正插入的记录。
+// 记录未指定 Status 时按 pending 插入
+func (s *NotificationStore) UpsertNotifications(records []NotificationRecord) ([]NotificationRecord, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	tx, err := s.db.Begin()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer tx.Rollback()
 
@@ -149,27 +199,41 @@ func (s *NotificationStore) UpsertNotifications(records []NotificationRecord) er
 		INSERT OR IGNORE INTO notifications
 		(id, status, feed_id, xsec_token, comment_id, parent_comment_id,
 		 comment_content, user_id, user_nickname, note_title, relation_type,
-		 notif_time_unix, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 notif_time_unix, created_at, updated_at, priority, suggested_reply, triage_rule)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer stmt.Close()
 
 	now := time.Now().Unix()
+	var inserted []NotificationRecord
 	for _, r := range records {
-		if _, err := stmt.Exec(
-			r.ID, string(StatusPending), r.FeedID, r.XsecToken,
+		if r.Status == "" {
+			r.Status = StatusPending
+		}
+		result, err := stmt.Exec(
+			r.ID, string(r.Status), r.FeedID, r.XsecToken,
 			r.CommentID, r.ParentCommentID, r.CommentContent,
 			r.UserID, r.UserNickname, r.NoteTitle, r.RelationType,
 			r.NotifTimeUnix, now, now,
-		); err != nil {
-			return fmt.Errorf("插入通知 %s 失败: %w", r.ID, err)
+			string(r.Priority), r.SuggestedReply, r.TriageRule,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("插入通知 %s 失败: %w", r.ID, err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			r.CreatedAt = now
+			r.UpdatedAt = now
+			inserted = append(inserted, r)
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return inserted, nil
 }
 
 // MarkResult 更新单条通知的处理结果
@@ -344,29 +408,14 @@ func (s *NotificationStore) GetRecord(id string) (*NotificationRecord, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	row := s.db.QueryRow(`
-		SELECT id, status, retry_count, feed_id, xsec_token, comment_id,
-		       parent_comment_id, comment_content, user_id, user_nickname,
-		       note_title, relation_type, notif_time_unix, reply_content,
-		       updated_at, created_at
-		FROM notifications WHERE id=?
-	`, id)
-
-	r := &NotificationRecord{}
-	var status string
-	err := row.Scan(
-		&r.ID, &status, &r.RetryCount, &r.FeedID, &r.XsecToken,
-		&r.CommentID, &r.ParentCommentID, &r.CommentContent,
-		&r.UserID, &r.UserNickname, &r.NoteTitle, &r.RelationType,
-		&r.NotifTimeUnix, &r.ReplyContent, &r.UpdatedAt, &r.CreatedAt,
-	)
+	row := s.db.QueryRow(`SELECT `+notificationColumns+` FROM notifications WHERE id=?`, id)
+	r, err := scanNotificationRecord(row)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	r.Status = NotificationStatus(status)
 	return r, nil
 }
 
@@ -376,10 +425,7 @@ func (s *NotificationStore) GetPendingRecords() ([]NotificationRecord, error) {
 	defer s.mu.Unlock()
 
 	rows, err := s.db.Query(`
-		SELECT id, status, retry_count, feed_id, xsec_token, comment_id,
-		       parent_comment_id, comment_content, user_id, user_nickname,
-		       note_title, relation_type, notif_time_unix, reply_content,
-		       updated_at, created_at
+		SELECT ` + notificationColumns + `
 		FROM notifications
 		WHERE status IN ('pending', 'retry', 'deleted_check')
 		ORDER BY notif_time_unix DESC
@@ -391,22 +437,151 @@ func (s *NotificationStore) GetPendingRecords() ([]NotificationRecord, error) {
 
 	var result []NotificationRecord
 	for rows.Next() {
-		r := NotificationRecord{}
-		var status string
-		if err := rows.Scan(
-			&r.ID, &status, &r.RetryCount, &r.FeedID, &r.XsecToken,
-			&r.CommentID, &r.ParentCommentID, &r.CommentContent,
-			&r.UserID, &r.UserNickname, &r.NoteTitle, &r.RelationType,
-			&r.NotifTimeUnix, &r.ReplyContent, &r.UpdatedAt, &r.CreatedAt,
-		); err != nil {
+		r, err := scanNotificationRecord(rows)
+		if err != nil {
 			return nil, err
 		}
-		r.Status = NotificationStatus(status)
-		result = append(result, r)
+		result = append(result, *r)
 	}
 	return result, rows.Err()
 }
 
+// NotificationQuery 通知历史查询条件，零值字段不参与过滤
+type NotificationQuery struct {
+	Statuses      []NotificationStatus
+	RelationTypes []string
+	UserID        string
+	FeedID        string
+	// SinceUnix / UntilUnix 通知时间范围（秒，闭区间）
+	SinceUnix int64
+	UntilUnix int64
+	// Keyword 在评论内容和回复内容中模糊匹配
+	Keyword string
+	Offset  int
+	// Limit 每页条数，<=0 时为 20，最大 100
+	Limit int
+}
+
+// ParseNotificationStatuses 校验并转换状态过滤条件
+func ParseNotificationStatuses(values []string) ([]NotificationStatus, error) {
+	statuses := make([]NotificationStatus, 0, len(values))
+	for _, v := range values {
+		switch st := NotificationStatus(v); st {
+		case StatusPending, StatusReplied, StatusSkipped, StatusRetry, StatusDeletedCheck, StatusInfo:
+			statuses = append(statuses, st)
+		default:
+			return nil, fmt.Errorf("无效的 status: %q，合法值：pending / replied / skipped / retry / deleted_check / info", v)
+		}
+	}
+	return statuses, nil
+}
+
+// NotificationSearchResult 通知历史查询结果，按通知时间倒序
+type NotificationSearchResult struct {
+	Records    []NotificationRecord `json:"records"`
+	Total      int                  `json:"total"`
+	Offset     int                  `json:"offset"`
+	NextOffset int                  `json:"next_offset"`
+	HasMore    bool                 `json:"has_more"`
+}
+
+// buildNotificationFilter 根据查询条件生成 WHERE 子句和参数
+func buildNotificationFilter(q NotificationQuery) (string, []any) {
+	var conds []string
+	var args []any
+
+	if len(q.Statuses) > 0 {
+		conds = append(conds, "status IN ("+placeholders(len(q.Statuses))+")")
+		for _, st := range q.Statuses {
+			args = append(args, string(st))
+		}
+	}
+	if len(q.RelationTypes) > 0 {
+		conds = append(conds, "relation_type IN ("+placeholders(len(q.RelationTypes))+")")
+		for _, rt := range q.RelationTypes {
+			args = append(args, rt)
+		}
+	}
+	if q.UserID != "" {
+		conds = append(conds, "user_id = ?")
+		args = append(args, q.UserID)
+	}
+	if q.FeedID != "" {
+		conds = append(conds, "feed_id = ?")
+		args = append(args, q.FeedID)
+	}
+	if q.SinceUnix > 0 {
+		conds = append(conds, "notif_time_unix >= ?")
+		args = append(args, q.SinceUnix)
+	}
+	if q.UntilUnix > 0 {
+		conds = append(conds, "notif_time_unix <= ?")
+		args = append(args, q.UntilUnix)
+	}
+	if kw := strings.TrimSpace(q.Keyword); kw != "" {
+		pattern := "%" + likeEscaper.Replace(kw) + "%"
+		conds = append(conds, `(comment_content LIKE ? ESCAPE '\' OR reply_content LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// SearchNotifications 按条件分页查询通知历史
+func (s *NotificationStore) SearchNotifications(q NotificationQuery) (*NotificationSearchResult, error) {
+	if q.Limit <= 0 {
+		q.Limit = 20
+	}
+	if q.Limit > 100 {
+		q.Limit = 100
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	where, args := buildNotificationFilter(q)
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM notifications`+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("统计通知失败: %w", err)
+	}
+
+	rows, err := s.db.Query(`SELECT `+notificationColumns+` FROM notifications`+where+
+		` ORDER BY notif_time_unix DESC, id DESC LIMIT ? OFFSET ?`,
+		append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("查询通知失败: %w", err)
+	}
+	defer rows.Close()
+
+	result := &NotificationSearchResult{Records: []NotificationRecord{}, Total: total, Offset: q.Offset}
+	for rows.Next() {
+		r, err := scanNotificationRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Records = append(result.Records, *r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	result.NextOffset = q.Offset + len(result.Records)
+	result.HasMore = result.NextOffset < total
+	return result, nil
+}
+
 // AutoSkipExcessiveRetries 将重试次数超过上限的通知自动标记为 skipped
 func (s *NotificationStore) AutoSkipExcessiveRetries(maxRetries int) (int, error) {
 	s.mu.Lock()
@@ -448,6 +623,187 @@ func (s *NotificationStore) Stats() (map[string]int, error) {
 	return result, rows.Err()
 }
 
+// RecordPostedComment 记录本服务发表的评论或回复（同一 ID 重复记录时覆盖）
+func (s *NotificationStore) RecordPostedComment(r PostedCommentRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.CreatedAt == 0 {
+		r.CreatedAt = time.Now().Unix()
+	}
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO posted_comments
+		(comment_id, feed_id, xsec_token, parent_comment_id, target_comment_id, content, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, r.CommentID, r.FeedID, r.XsecToken, r.ParentCommentID, r.TargetCommentID, r.Content, r.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("记录评论 %s 失败: %w", r.CommentID, err)
+	}
+	return nil
+}
+
+// GetPostedComment 获取本服务发表的评论记录，不存在时返回 nil
+func (s *NotificationStore) GetPostedComment(commentID string) (*PostedCommentRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := &PostedCommentRecord{}
+	err := s.db.QueryRow(`
+		SELECT comment_id, feed_id, xsec_token, parent_comment_id, target_comment_id, content, created_at
+		FROM posted_comments WHERE comment_id=?
+	`, commentID).Scan(&r.CommentID, &r.FeedID, &r.XsecToken, &r.ParentCommentID, &r.TargetCommentID, &r.Content, &r.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// RecordReply 直接记录一次回复，Status 为空时视为已发出
+func (s *NotificationStore) RecordReply(r ReplyGuardRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Status == "" {
+		r.Status = ReplyGuardPosted
+	}
+	if _, err := insertReplyGuard(s.db, r); err != nil {
+		return fmt.Errorf("记录回复失败: %w", err)
+	}
+	return nil
+}
+
+// ReserveReply 在发出回复前记录一次 pending 尝试，返回新记录的 ID 和此前对同一条评论的全部记录（按时间倒序）。
+// 此前已有记录且 allowDuplicate 为 false 时不写入，返回的 ID 为 0。检查和写入在同一个事务中完成。
+func (s *NotificationStore) ReserveReply(r ReplyGuardRecord, allowDuplicate bool) (int64, []ReplyGuardRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	previous, err := queryReplyGuard(tx, r.FeedID, r.TargetCommentID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(previous) > 0 && !allowDuplicate {
+		return 0, previous, nil
+	}
+
+	r.Status = ReplyGuardPending
+	id, err := insertReplyGuard(tx, r)
+	if err != nil {
+		return 0, nil, fmt.Errorf("记录回复尝试失败: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, nil, err
+	}
+	return id, previous, nil
+}
+
+// FinishReply 更新一次回复尝试的结果
+func (s *NotificationStore) FinishReply(id int64, status ReplyGuardStatus, replyCommentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.db.Exec(`UPDATE reply_guard SET status=?, reply_comment_id=? WHERE id=?`,
+		string(status), replyCommentID, id); err != nil {
+		return fmt.Errorf("更新回复记录失败: %w", err)
+	}
+	return nil
+}
+
+// CancelReply 删除一次确定没有发出的回复尝试
+func (s *NotificationStore) CancelReply(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.db.Exec(`DELETE FROM reply_guard WHERE id=?`, id); err != nil {
+		return fmt.Errorf("删除回复记录失败: %w", err)
+	}
+	return nil
+}
+
+// FindReplies 查找对同一条评论的回复记录（包括未确认的尝试），按时间倒序
+func (s *NotificationStore) FindReplies(feedID, targetCommentID string) ([]ReplyGuardRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return queryReplyGuard(s.db, feedID, targetCommentID)
+}
+
+type replyGuardExecer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+type replyGuardQueryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func insertReplyGuard(db replyGuardExecer, r ReplyGuardRecord) (int64, error) {
+	if r.CreatedAt == 0 {
+		r.CreatedAt = time.Now().Unix()
+	}
+	result, err := db.Exec(`
+		INSERT INTO reply_guard (feed_id, target_comment_id, content_hash, content, status, reply_comment_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, r.FeedID, r.TargetCommentID, r.ContentHash, r.Content, string(r.Status), r.ReplyCommentID, r.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func queryReplyGuard(db replyGuardQueryer, feedID, targetCommentID string) ([]ReplyGuardRecord, error) {
+	rows, err := db.Query(`
+		SELECT id, feed_id, target_comment_id, content_hash, content, status, reply_comment_id, created_at
+		FROM reply_guard
+		WHERE feed_id=? AND target_comment_id=?
+		ORDER BY created_at DESC, id DESC
+	`, feedID, targetCommentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []ReplyGuardRecord
+	for rows.Next() {
+		var r ReplyGuardRecord
+		var status string
+		if err := rows.Scan(&r.ID, &r.FeedID, &r.TargetCommentID, &r.ContentHash, &r.Content, &status, &r.ReplyCommentID, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		r.Status = ReplyGuardStatus(status)
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// MarkRepliedByComment 把针对该评论、仍在待处理状态（pending/retry/deleted_check）的通知标记为 replied，
+// 返回更新的条数。replyContent 为空时保留通知原有的 reply_content。
+// 旧数据可能没有 feed_id，这类记录只按 comment_id 匹配。
+func (s *NotificationStore) MarkRepliedByComment(feedID, commentID, replyContent string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`
+		UPDATE notifications
+		SET status='replied', reply_content=COALESCE(NULLIF(?, ''), reply_content), updated_at=?
+		WHERE comment_id=? AND (feed_id=? OR feed_id='')
+		  AND status IN ('pending', 'retry', 'deleted_check')
+	`, replyContent, time.Now().Unix(), commentID, feedID)
+	if err != nil {
+		return 0, fmt.Errorf("更新评论 %s 的通知状态失败: %w", commentID, err)
+	}
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
 // Close 关闭数据库连接
 func (s *NotificationStore) Close() error {
 	return s.db.Close()
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+// runStateCommand 执行通知状态的导出/导入子命令：
+//
+//	xiaohongshu-mcp export [-db-path 路径] [-format jsonl|csv] [-out 文件]
+//	xiaohongshu-mcp import [-db-path 路径] [-format jsonl|csv] -in 文件
+//
+// -out / -in 为 "-" 时使用标准输出/标准输入；导入时未指定 -format 则按文件扩展名判断。
+func runStateCommand(name string, args []string) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	var dbPath, format, file string
+	fs.StringVar(&dbPath, "db-path", "", "通知状态数据库路径，默认读取 XHS_DB_PATH，否则为二进制文件同目录的 notifications.db")
+	fs.StringVar(&format, "format", "", "文件格式：jsonl（默认）或 csv")
+	if name == "export" {
+		fs.StringVar(&file, "out", "-", "导出文件路径，- 表示标准输出")
+	} else {
+		fs.StringVar(&file, "in", "", "导入文件路径，- 表示标准输入")
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if dbPath == "" {
+		dbPath = os.Getenv("XHS_DB_PATH")
+	}
+	configs.SetDBPath(dbPath)
+
+	if format == "" && strings.EqualFold(filepath.Ext(file), ".csv") {
+		format = string(StateFormatCSV)
+	}
+	stateFormat, err := ParseStateFormat(format)
+	if err != nil {
+		return err
+	}
+
+	store, err := GetNotificationStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if name == "export" {
+		return exportStateFile(store, file, stateFormat)
+	}
+	if file == "" {
+		return fmt.Errorf("缺少 -in 参数")
+	}
+	return importStateFile(store, file, stateFormat)
+}
+
+func exportStateFile(store *NotificationStore, path string, format StateFormat) error {
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("创建导出文件失败: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	result, err := store.Export(w, format)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("导出完成：通知 %d 条，meta %d 项（%s）", result.Notifications, result.Meta, format)
+	return nil
+}
+
+func importStateFile(store *NotificationStore, path string, format StateFormat) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("打开导入文件失败: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	result, err := store.Import(r, format)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("导入完成：新增 %d 条，覆盖 %d 条，保留本地 %d 条，meta 更新 %d 项",
+		result.Inserted, result.Updated, result.Kept, result.Meta)
+	return nil
+}
@@ -0,0 +1,475 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// StateFormat 通知状态导出/导入的文件格式
+type StateFormat string
+
+const (
+	StateFormatJSONL StateFormat = "jsonl"
+	StateFormatCSV   StateFormat = "csv"
+)
+
+// ParseStateFormat 解析导出格式，空字符串视为 jsonl
+func ParseStateFormat(s string) (StateFormat, error) {
+	switch StateFormat(strings.ToLower(s)) {
+	case "", StateFormatJSONL:
+		return StateFormatJSONL, nil
+	case StateFormatCSV:
+		return StateFormatCSV, nil
+	}
+	return "", fmt.Errorf("无效的格式: %q，合法值：jsonl / csv", s)
+}
+
+// StateEntry 导出文件中的一行：notifications 表的一条记录或 meta 表的一个键值
+type StateEntry struct {
+	Table        string              `json:"table"` // notifications / meta
+	Notification *NotificationRecord `json:"notification,omitempty"`
+	Key          string              `json:"key,omitempty"`
+	Value        string              `json:"value,omitempty"`
+}
+
+// StateExportResult 导出的条数
+type StateExportResult struct {
+	Notifications int `json:"notifications"`
+	Meta          int `json:"meta"`
+}
+
+// StateImportResult 导入的结果
+type StateImportResult struct {
+	// Inserted 本地没有、新插入的通知
+	Inserted int `json:"inserted"`
+	// Updated 导入记录的处理进度比本地更靠后，覆盖了本地记录
+	Updated int `json:"updated"`
+	// Kept 本地记录更新或相同，保留本地
+	Kept int `json:"kept"`
+	// Meta 更新的 meta 键数
+	Meta int `json:"meta"`
+}
+
+// Export 把 notifications 和 meta 表导出为 JSONL 或 CSV。
+// 先在锁内读出全部数据再写出，避免写得慢（如 HTTP 下载）时一直占着数据库。
+func (s *NotificationStore) Export(w io.Writer, format StateFormat) (*StateExportResult, error) {
+	entries, err := s.exportEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StateExportResult{}
+	for _, e := range entries {
+		if e.Table == "meta" {
+			result.Meta++
+		} else {
+			result.Notifications++
+		}
+	}
+
+	switch format {
+	case StateFormatCSV:
+		err = writeStateCSV(w, entries)
+	default:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		for _, e := range entries {
+			if err = enc.Encode(e); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("写出导出数据失败: %w", err)
+	}
+	return result, nil
+}
+
+func (s *NotificationStore) exportEntries() ([]StateEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows, err := s.db.Query(`SELECT ` + notificationColumns + ` FROM notifications ORDER BY notif_time_unix, id`)
+	if err != nil {
+		return nil, fmt.Errorf("读取通知失败: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []StateEntry
+	for rows.Next() {
+		r, err := scanNotificationRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, StateEntry{Table: "notifications", Notification: r})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	metaRows, err := s.db.Query(`SELECT key, value FROM meta ORDER BY key`)
+	if err != nil {
+		return nil, fmt.Errorf("读取 meta 失败: %w", err)
+	}
+	defer metaRows.Close()
+	for metaRows.Next() {
+		e := StateEntry{Table: "meta"}
+		if err := metaRows.Scan(&e.Key, &e.Value); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, metaRows.Err()
+}
+
+// statusProgress 通知处理进度，导入时进度更靠后的记录优先：
+// 已回复/已跳过的通知不会被另一台机器上尚未处理的同一条通知覆盖
+func statusProgress(st NotificationStatus) int {
+	switch st {
+	case StatusReplied, StatusSkipped:
+		return 2
+	case StatusRetry, StatusDeletedCheck:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Import 按通知 ID 合并导入文件，整个文件在一个事务中导入，任何一行出错都不会写入。
+//
+// 本地不存在的通知直接插入；已存在时，导入记录处理进度更靠后（statusProgress），
+// 或进度相同但 updated_at 更新时才覆盖本地记录，否则保留本地。缺少 updated_at 的记录按 0 处理，进度相同时本地优先。
+// meta 中 last_fetch_time 和 retention_watermark 取较大值，schema_version 不导入，其余键本地没有时才写入。
+func (s *NotificationStore) Import(r io.Reader, format StateFormat) (*StateImportResult, error) {
+	entries, err := ReadStateEntries(r, format)
+	if err != nil {
+		return nil, err
+	}
+	return s.ImportEntries(entries)
+}
+
+// ReadStateEntries 读取并校验导入文件
+func ReadStateEntries(r io.Reader, format StateFormat) ([]StateEntry, error) {
+	if format == StateFormatCSV {
+		return readStateCSV(r)
+	}
+	return readStateJSONL(r)
+}
+
+// ImportEntries 合并已读取的导入数据，规则见 Import
+func (s *NotificationStore) ImportEntries(entries []StateEntry) (*StateImportResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &StateImportResult{}
+	for _, e := range entries {
+		switch e.Table {
+		case "notifications":
+			outcome, err := importNotification(tx, *e.Notification)
+			if err != nil {
+				return nil, err
+			}
+			switch outcome {
+			case "inserted":
+				result.Inserted++
+			case "updated":
+				result.Updated++
+			default:
+				result.Kept++
+			}
+		case "meta":
+			changed, err := importMeta(tx, e.Key, e.Value)
+			if err != nil {
+				return nil, err
+			}
+			if changed {
+				result.Meta++
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// importNotification 合并一条通知，返回 inserted / updated / kept
+func importNotification(tx *sql.Tx, r NotificationRecord) (string, error) {
+	var status string
+	var updatedAt, createdAt int64
+	var retryCount int
+	err := tx.QueryRow(`SELECT status, updated_at, created_at, retry_count FROM notifications WHERE id=?`, r.ID).
+		Scan(&status, &updatedAt, &createdAt, &retryCount)
+	if err == sql.ErrNoRows {
+		_, err = tx.Exec(`INSERT INTO notifications (`+notificationColumns+`) VALUES (`+placeholders(len(notificationColumnNames()))+`)`,
+			r.ID, string(r.Status), r.RetryCount, r.FeedID, r.XsecToken, r.CommentID,
+			r.ParentCommentID, r.CommentContent, r.UserID, r.UserNickname,
+			r.NoteTitle, r.RelationType, r.NotifTimeUnix, r.ReplyContent,
+			r.UpdatedAt, r.CreatedAt, string(r.Priority), r.SuggestedReply, r.TriageRule)
+		if err != nil {
+			return "", fmt.Errorf("导入通知 %s 失败: %w", r.ID, err)
+		}
+		return "inserted", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	local, imported := statusProgress(NotificationStatus(status)), statusProgress(r.Status)
+	if imported < local || (imported == local && r.UpdatedAt <= updatedAt) {
+		return "kept", nil
+	}
+
+	if createdAt > 0 && (r.CreatedAt == 0 || createdAt < r.CreatedAt) {
+		r.CreatedAt = createdAt
+	}
+	if retryCount > r.RetryCount {
+		r.RetryCount = retryCount
+	}
+	_, err = tx.Exec(`
+		UPDATE notifications SET
+			status=?, retry_count=?, feed_id=?, xsec_token=?, comment_id=?,
+			parent_comment_id=?, comment_content=?, user_id=?, user_nickname=?,
+			note_title=?, relation_type=?, notif_time_unix=?, reply_content=?,
+			updated_at=?, created_at=?, priority=?, suggested_reply=?, triage_rule=?
+		WHERE id=?
+	`, string(r.Status), r.RetryCount, r.FeedID, r.XsecToken, r.CommentID,
+		r.ParentCommentID, r.CommentContent, r.UserID, r.UserNickname,
+		r.NoteTitle, r.RelationType, r.NotifTimeUnix, r.ReplyContent,
+		r.UpdatedAt, r.CreatedAt, string(r.Priority), r.SuggestedReply, r.TriageRule, r.ID)
+	if err != nil {
+		return "", fmt.Errorf("导入通知 %s 失败: %w", r.ID, err)
+	}
+	return "updated", nil
+}
+
+// importMeta 合并一个 meta 键，返回是否修改了本地
+func importMeta(tx *sql.Tx, key, value string) (bool, error) {
+	if key == "schema_version" {
+		return false, nil
+	}
+
+	var local string
+	err := tx.QueryRow(`SELECT value FROM meta WHERE key=?`, key).Scan(&local)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+	exists := err == nil
+
+	switch key {
+	case "last_fetch_time", "retention_watermark":
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false, fmt.Errorf("meta %s 格式错误: %q", key, value)
+		}
+		if cur, err := strconv.ParseInt(local, 10, 64); exists && err == nil && cur >= v {
+			return false, nil
+		}
+	default:
+		if exists {
+			return false, nil
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO meta(key, value) VALUES(?, ?)
+		ON CONFLICT(key) DO UPDATE SET value=excluded.value
+	`, key, value); err != nil {
+		return false, fmt.Errorf("导入 meta %s 失败: %w", key, err)
+	}
+	return true, nil
+}
+
+// validateStateEntry 检查导入的一行并补全默认值
+func validateStateEntry(e *StateEntry) error {
+	switch e.Table {
+	case "notifications":
+		if e.Notification == nil || e.Notification.ID == "" {
+			return fmt.Errorf("通知记录缺少 id")
+		}
+		if e.Notification.Status == "" {
+			e.Notification.Status = StatusPending
+		}
+		if _, err := ParseNotificationStatuses([]string{string(e.Notification.Status)}); err != nil {
+			return fmt.Errorf("通知 %s: %w", e.Notification.ID, err)
+		}
+	case "meta":
+		if e.Key == "" {
+			return fmt.Errorf("meta 记录缺少 key")
+		}
+	default:
+		return fmt.Errorf("未知的表 %q", e.Table)
+	}
+	return nil
+}
+
+// maxStateLineSize JSONL 单行最大长度
+const maxStateLineSize = 4 << 20
+
+func readStateJSONL(r io.Reader) ([]StateEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxStateLineSize)
+
+	var entries []StateEntry
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var e StateEntry
+		if err := json.Unmarshal([]byte(text), &e); err != nil {
+			return nil, fmt.Errorf("第 %d 行解析失败: %w", line, err)
+		}
+		if err := validateStateEntry(&e); err != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取导入文件失败: %w", err)
+	}
+	return entries, nil
+}
+
+// stateCSVHeader CSV 的列：table、通知的全部列、meta 的 key 和 value
+var stateCSVHeader = append(append([]string{"table"}, notificationColumnNames()...), "key", "value")
+
+// notificationColumnNames notificationColumns 拆成列名
+func notificationColumnNames() []string {
+	var names []string
+	for _, name := range strings.Split(notificationColumns, ",") {
+		names = append(names, strings.TrimSpace(name))
+	}
+	return names
+}
+
+func writeStateCSV(w io.Writer, entries []StateEntry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(stateCSVHeader); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		row := make([]string, len(stateCSVHeader))
+		row[0] = e.Table
+		if r := e.Notification; r != nil {
+			copy(row[1:], []string{
+				r.ID, string(r.Status), strconv.Itoa(r.RetryCount), r.FeedID, r.XsecToken, r.CommentID,
+				r.ParentCommentID, r.CommentContent, r.UserID, r.UserNickname,
+				r.NoteTitle, r.RelationType, strconv.FormatInt(r.NotifTimeUnix, 10), r.ReplyContent,
+				strconv.FormatInt(r.UpdatedAt, 10), strconv.FormatInt(r.CreatedAt, 10),
+				string(r.Priority), r.SuggestedReply, r.TriageRule,
+			})
+		}
+		row[len(row)-2] = e.Key
+		row[len(row)-1] = e.Value
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// readStateCSV 按表头名读取，缺少的列（如旧版本导出的文件）取零值
+func readStateCSV(r io.Reader) ([]StateEntry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 CSV 表头失败: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	if _, ok := index["table"]; !ok {
+		return nil, fmt.Errorf("CSV 缺少 table 列")
+	}
+
+	var entries []StateEntry
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行解析失败: %w", line, err)
+		}
+		get := func(name string) string {
+			if i, ok := index[name]; ok && i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+		getInt := func(name string) (int64, error) {
+			v := get(name)
+			if v == "" {
+				return 0, nil
+			}
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("第 %d 行 %s 格式错误: %q", line, name, v)
+			}
+			return n, nil
+		}
+
+		e := StateEntry{Table: get("table"), Key: get("key"), Value: get("value")}
+		if e.Table == "notifications" {
+			rec := &NotificationRecord{
+				ID:              get("id"),
+				Status:          NotificationStatus(get("status")),
+				FeedID:          get("feed_id"),
+				XsecToken:       get("xsec_token"),
+				CommentID:       get("comment_id"),
+				ParentCommentID: get("parent_comment_id"),
+				CommentContent:  get("comment_content"),
+				UserID:          get("user_id"),
+				UserNickname:    get("user_nickname"),
+				NoteTitle:       get("note_title"),
+				RelationType:    get("relation_type"),
+				ReplyContent:    get("reply_content"),
+				Priority:        TriagePriority(get("priority")),
+				SuggestedReply:  get("suggested_reply"),
+				TriageRule:      get("triage_rule"),
+			}
+			retry, err := getInt("retry_count")
+			if err != nil {
+				return nil, err
+			}
+			rec.RetryCount = int(retry)
+			if rec.NotifTimeUnix, err = getInt("notif_time_unix"); err != nil {
+				return nil, err
+			}
+			if rec.UpdatedAt, err = getInt("updated_at"); err != nil {
+				return nil, err
+			}
+			if rec.CreatedAt, err = getInt("created_at"); err != nil {
+				return nil, err
+			}
+			e.Key, e.Value = "", ""
+			e.Notification = rec
+		}
+		if err := validateStateEntry(&e); err != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
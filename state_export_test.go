@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportMissingUpdatedAtKeepsLocal(t *testing.T) {
+	store := newTestStore(t)
+	insertTestNotification(t, store, NotificationRecord{ID: "same", Status: StatusPending, UpdatedAt: 1700000100})
+	insertTestNotification(t, store, NotificationRecord{ID: "behind", Status: StatusPending, UpdatedAt: 1700000100})
+
+	// 导入记录都没有 updated_at：进度相同时保留本地，进度更靠后时仍然覆盖，本地没有的按 0 插入
+	input := strings.Join([]string{
+		`{"table":"notifications","notification":{"id":"same","status":"pending","reply_content":"旧的"}}`,
+		`{"table":"notifications","notification":{"id":"behind","status":"replied","reply_content":"已回复"}}`,
+		`{"table":"notifications","notification":{"id":"new"}}`,
+	}, "\n")
+	result, err := store.Import(strings.NewReader(input), StateFormatJSONL)
+	require.NoError(t, err)
+	require.Equal(t, StateImportResult{Inserted: 1, Updated: 1, Kept: 1}, *result)
+
+	r, err := store.GetRecord("same")
+	require.NoError(t, err)
+	require.Empty(t, r.ReplyContent)
+	require.Equal(t, int64(1700000100), r.UpdatedAt)
+
+	r, err = store.GetRecord("behind")
+	require.NoError(t, err)
+	require.Equal(t, StatusReplied, r.Status)
+
+	r, err = store.GetRecord("new")
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, r.Status)
+	require.Zero(t, r.UpdatedAt)
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// schemaMigration 一次表结构升级。已发布的迁移不能修改，只能追加新版本。
+type schemaMigration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// execStatements 在事务中依次执行 SQL
+func execStatements(stmts ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, stmt := range stmts {
+			if _, err := tx.Exec(stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// schemaMigrations 按版本号递增排列。
+// 1~3 是引入版本号之前已有的表，使用 IF NOT EXISTS，对旧数据库重复执行是安全的；
+// 之后新增的迁移（如 ALTER TABLE ADD COLUMN）只会在未升级的数据库上执行一次。
+var schemaMigrations = []schemaMigration{
+	{
+		version:     1,
+		description: "通知状态表和 meta 表",
+		up: execStatements(
+			`CREATE TABLE IF NOT EXISTS notifications (
+				id               TEXT    PRIMARY KEY,
+				status           TEXT    NOT NULL DEFAULT 'pending',
+				retry_count      INTEGER NOT NULL DEFAULT 0,
+				feed_id          TEXT    NOT NULL DEFAULT '',
+				xsec_token       TEXT    NOT NULL DEFAULT '',
+				comment_id       TEXT    NOT NULL DEFAULT '',
+				parent_comment_id TEXT   NOT NULL DEFAULT '',
+				comment_content  TEXT    NOT NULL DEFAULT '',
+				user_id          TEXT    NOT NULL DEFAULT '',
+				user_nickname    TEXT    NOT NULL DEFAULT '',
+				note_title       TEXT    NOT NULL DEFAULT '',
+				relation_type    TEXT    NOT NULL DEFAULT '',
+				notif_time_unix  INTEGER NOT NULL DEFAULT 0,
+				reply_content    TEXT    NOT NULL DEFAULT '',
+				updated_at       INTEGER NOT NULL DEFAULT 0,
+				created_at       INTEGER NOT NULL DEFAULT 0
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_status ON notifications(status)`,
+			`CREATE INDEX IF NOT EXISTS idx_notif_time ON notifications(notif_time_unix)`,
+			`CREATE INDEX IF NOT EXISTS idx_updated_at ON notifications(updated_at)`,
+		),
+	},
+	{
+		version:     2,
+		description: "本服务发表的评论（posted_comments）",
+		up: execStatements(
+			`CREATE TABLE IF NOT EXISTS posted_comments (
+				comment_id        TEXT    PRIMARY KEY,
+				feed_id           TEXT    NOT NULL DEFAULT '',
+				xsec_token        TEXT    NOT NULL DEFAULT '',
+				parent_comment_id TEXT    NOT NULL DEFAULT '',
+				target_comment_id TEXT    NOT NULL DEFAULT '',
+				content           TEXT    NOT NULL DEFAULT '',
+				created_at        INTEGER NOT NULL DEFAULT 0
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_posted_feed ON posted_comments(feed_id)`,
+		),
+	},
+	{
+		version:     3,
+		description: "防重复回复记录（reply_guard）",
+		up: execStatements(
+			`CREATE TABLE IF NOT EXISTS reply_guard (
+				id                INTEGER PRIMARY KEY AUTOINCREMENT,
+				feed_id           TEXT    NOT NULL DEFAULT '',
+				target_comment_id TEXT    NOT NULL DEFAULT '',
+				content_hash      TEXT    NOT NULL DEFAULT '',
+				reply_comment_id  TEXT    NOT NULL DEFAULT '',
+				created_at        INTEGER NOT NULL DEFAULT 0
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_reply_guard_target ON reply_guard(feed_id, target_comment_id)`,
+		),
+	},
+	{
+		version:     4,
+		description: "通知分拣结果（priority / suggested_reply / triage_rule）",
+		up: execStatements(
+			`ALTER TABLE notifications ADD COLUMN priority TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE notifications ADD COLUMN suggested_reply TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE notifications ADD COLUMN triage_rule TEXT NOT NULL DEFAULT ''`,
+		),
+	},
+	{
+		version:     5,
+		description: "回复记录增加状态和内容（发出回复前先记录尝试）",
+		up: execStatements(
+			// 之前只记录成功的回复，已有记录都是 posted
+			`ALTER TABLE reply_guard ADD COLUMN status TEXT NOT NULL DEFAULT 'posted'`,
+			`ALTER TABLE reply_guard ADD COLUMN content TEXT NOT NULL DEFAULT ''`,
+		),
+	},
+}
+
+// latestSchemaVersion 当前代码对应的表结构版本
+func latestSchemaVersion() int {
+	return schemaMigrations[len(schemaMigrations)-1].version
+}
+
+// migrate 把数据库升级到最新版本。
+// 版本号记录在 meta.schema_version；升级已有数据的数据库前先备份到同目录，
+// 每个版本在独立事务中执行，失败时回滚该版本并返回错误，已完成的版本保留。
+func (s *NotificationStore) migrate() error {
+	// meta 表用来记录版本号，必须先于任何迁移存在
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS meta (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("创建 meta 表失败: %w", err)
+	}
+
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	latest := latestSchemaVersion()
+	if current > latest {
+		return fmt.Errorf("数据库版本 v%d 高于程序支持的 v%d，请升级程序", current, latest)
+	}
+	if current == latest {
+		return nil
+	}
+
+	hasData, err := s.hasExistingData()
+	if err != nil {
+		return err
+	}
+	if hasData {
+		backup, err := s.backup(fmt.Sprintf("v%d", current))
+		if err != nil {
+			return fmt.Errorf("迁移前备份失败: %w", err)
+		}
+		logrus.Infof("通知数据库迁移前已备份: %s", backup)
+	}
+
+	for _, m := range schemaMigrations {
+		if m.version <= current {
+			continue
+		}
+		if err := s.applyMigration(m); err != nil {
+			return fmt.Errorf("迁移到 v%d（%s）失败: %w", m.version, m.description, err)
+		}
+		logrus.Infof("通知数据库已迁移到 v%d：%s", m.version, m.description)
+	}
+	return nil
+}
+
+func (s *NotificationStore) applyMigration(m schemaMigration) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO meta(key, value) VALUES('schema_version', ?)
+		ON CONFLICT(key) DO UPDATE SET value=excluded.value
+	`, strconv.Itoa(m.version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SchemaVersion 返回数据库当前的表结构版本，未记录时为 0
+func (s *NotificationStore) SchemaVersion() (int, error) {
+	var val string
+	err := s.db.QueryRow(`SELECT value FROM meta WHERE key='schema_version'`).Scan(&val)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("读取数据库版本失败: %w", err)
+	}
+	v, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("数据库版本格式错误: %q", val)
+	}
+	return v, nil
+}
+
+// hasExistingData 数据库中是否已有业务表（新建的数据库不需要备份）
+func (s *NotificationStore) hasExistingData() (bool, error) {
+	var n int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE type='table' AND name NOT IN ('meta', 'sqlite_sequence')
+	`).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("检查数据库表失败: %w", err)
+	}
+	return n > 0, nil
+}
+
+// backup 用 VACUUM INTO 把数据库完整复制到 <dbPath>.<tag>-<时间>.bak，返回备份路径
+func (s *NotificationStore) backup(tag string) (string, error) {
+	path := fmt.Sprintf("%s.%s-%s.bak", s.dbPath, tag, time.Now().Format("20060102-150405"))
+	if _, err := s.db.Exec(`VACUUM INTO ?`, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// createBaselineDB 按引入版本号之前的表结构建库：只有 notifications 和 meta，meta 中没有 schema_version
+func createBaselineDB(t *testing.T, path string) {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE notifications (
+			id               TEXT    PRIMARY KEY,
+			status           TEXT    NOT NULL DEFAULT 'pending',
+			retry_count      INTEGER NOT NULL DEFAULT 0,
+			feed_id          TEXT    NOT NULL DEFAULT '',
+			xsec_token       TEXT    NOT NULL DEFAULT '',
+			comment_id       TEXT    NOT NULL DEFAULT '',
+			parent_comment_id TEXT   NOT NULL DEFAULT '',
+			comment_content  TEXT    NOT NULL DEFAULT '',
+			user_id          TEXT    NOT NULL DEFAULT '',
+			user_nickname    TEXT    NOT NULL DEFAULT '',
+			note_title       TEXT    NOT NULL DEFAULT '',
+			relation_type    TEXT    NOT NULL DEFAULT '',
+			notif_time_unix  INTEGER NOT NULL DEFAULT 0,
+			reply_content    TEXT    NOT NULL DEFAULT '',
+			updated_at       INTEGER NOT NULL DEFAULT 0,
+			created_at       INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE INDEX idx_status ON notifications(status);
+		CREATE TABLE meta (
+			key   TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		);
+		INSERT INTO notifications(id, status, feed_id, comment_id, comment_content, notif_time_unix, updated_at, created_at)
+		VALUES('n1', 'replied', 'f1', 'c1', '旧评论', 1700000000, 1700000100, 1700000000);
+		INSERT INTO meta(key, value) VALUES('last_fetch_time', '1700000000');
+	`)
+	require.NoError(t, err)
+}
+
+func TestMigrateBaselineDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.db")
+	createBaselineDB(t, path)
+
+	store, err := newNotificationStore(path)
+	require.NoError(t, err)
+
+	version, err := store.SchemaVersion()
+	require.NoError(t, err)
+	require.Equal(t, latestSchemaVersion(), version)
+
+	// 旧数据保留，新增列取默认值
+	r, err := store.GetRecord("n1")
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	require.Equal(t, StatusReplied, r.Status)
+	require.Equal(t, "旧评论", r.CommentContent)
+	require.Empty(t, r.Priority)
+	lastFetch, err := store.GetLastFetchTime()
+	require.NoError(t, err)
+	require.Equal(t, int64(1700000000), lastFetch)
+
+	// 之后版本新增的表可用
+	require.NoError(t, store.RecordReply(ReplyGuardRecord{FeedID: "f1", TargetCommentID: "c1", ContentHash: "h"}))
+
+	// 迁移前的备份是升级前的原样数据
+	backups, err := filepath.Glob(path + ".v0-*.bak")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	backupDB, err := sql.Open("sqlite", backups[0])
+	require.NoError(t, err)
+	defer backupDB.Close()
+	var content string
+	require.NoError(t, backupDB.QueryRow(`SELECT comment_content FROM notifications WHERE id='n1'`).Scan(&content))
+	require.Equal(t, "旧评论", content)
+	var n int
+	require.NoError(t, backupDB.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('notifications') WHERE name='priority'`).Scan(&n))
+	require.Zero(t, n, "备份应是迁移前的表结构")
+
+	// 已是最新版本时重新打开不再迁移、不再备份
+	require.NoError(t, store.Close())
+	store, err = newNotificationStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	backups, err = filepath.Glob(path + ".*.bak")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+}
+
+func TestMigrateNewDBSkipsBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.db")
+	store, err := newNotificationStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	version, err := store.SchemaVersion()
+	require.NoError(t, err)
+	require.Equal(t, latestSchemaVersion(), version)
+
+	backups, err := filepath.Glob(path + ".*.bak")
+	require.NoError(t, err)
+	require.Empty(t, backups, "新建的数据库不需要备份")
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RetentionMode 过期记录的处理方式
+type RetentionMode string
+
+const (
+	RetentionArchive RetentionMode = "archive" // 移到归档库 <db>.archive.db
+	RetentionPurge   RetentionMode = "purge"   // 直接删除
+)
+
+// retentionInterval 保留策略的执行间隔
+const retentionInterval = 24 * time.Hour
+
+// RetentionPolicy 已完成通知（replied / skipped）的保留策略
+type RetentionPolicy struct {
+	// Days 保留天数，<=0 时不清理
+	Days int
+	Mode RetentionMode
+}
+
+// RetentionResult 一次清理的结果
+type RetentionResult struct {
+	Removed int
+	// ArchivePath 归档库路径，purge 模式为空
+	ArchivePath string
+	// Watermark 本次清理后的水位（Unix 秒），见 RetentionWatermark
+	Watermark int64
+}
+
+// ParseRetentionMode 解析保留方式，空字符串视为 archive
+func ParseRetentionMode(s string) (RetentionMode, error) {
+	switch RetentionMode(s) {
+	case "", RetentionArchive:
+		return RetentionArchive, nil
+	case RetentionPurge:
+		return RetentionPurge, nil
+	}
+	return "", fmt.Errorf("无效的保留方式: %q，合法值：archive / purge", s)
+}
+
+// notificationIDUnix 从 notification_id 高 32 位取出 Unix 秒（与 extractSinceUnixFromIDs 一致），无法解析时为 0
+func notificationIDUnix(id string) int64 {
+	v, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return int64(v >> 32)
+}
+
+// retentionCondition 过期的已完成通知。notif_time_unix 缺失（mark_result 补插的旧记录）时按 updated_at 判断
+const retentionCondition = `status IN ('replied', 'skipped') AND (
+	(notif_time_unix > 0 AND notif_time_unix < ?) OR
+	(notif_time_unix = 0 AND updated_at < ?)
+)`
+
+// ApplyRetention 归档或删除 Days 天前的已完成通知，然后 VACUUM。
+//
+// 删除已完成记录会让 extractSinceUnixFromIDs 算出的扫描起点变晚，被删的旧通知如果再次被扫描到，
+// 会因为不在 processed 集合中而被当作全新通知重复处理。因此清理时把被删记录的最晚时间记为水位
+// （meta.retention_watermark），扫描时不早于水位，保证被清理的通知不会再回到待处理列表。
+func (s *NotificationStore) ApplyRetention(policy RetentionPolicy) (*RetentionResult, error) {
+	if policy.Days <= 0 {
+		return &RetentionResult{}, nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -policy.Days).Unix()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watermark, err := s.retentionWatermarkLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	// 计算本次要清理记录的最晚时间
+	rows, err := s.db.Query(`SELECT id, notif_time_unix FROM notifications WHERE `+retentionCondition, cutoff, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("查询过期通知失败: %w", err)
+	}
+	count := 0
+	for rows.Next() {
+		var id string
+		var t int64
+		if err := rows.Scan(&id, &t); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if t == 0 {
+			t = notificationIDUnix(id)
+		}
+		if t > watermark {
+			watermark = t
+		}
+		count++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return &RetentionResult{Watermark: watermark}, nil
+	}
+
+	result := &RetentionResult{Removed: count, Watermark: watermark}
+	if policy.Mode == RetentionArchive {
+		result.ArchivePath = s.dbPath + ".archive.db"
+		if _, err := s.db.Exec(`ATTACH DATABASE ? AS archive`, result.ArchivePath); err != nil {
+			return nil, fmt.Errorf("打开归档库失败: %w", err)
+		}
+		defer func() {
+			if _, err := s.db.Exec(`DETACH DATABASE archive`); err != nil {
+				logrus.Warnf("关闭归档库失败: %v", err)
+			}
+		}()
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if policy.Mode == RetentionArchive {
+		if _, err := tx.Exec(`CREATE TABLE IF NOT EXISTS archive.notifications AS SELECT ` + notificationColumns + ` FROM main.notifications WHERE 0`); err != nil {
+			return nil, fmt.Errorf("创建归档表失败: %w", err)
+		}
+		if err := syncArchiveColumns(tx); err != nil {
+			return nil, fmt.Errorf("升级归档表失败: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO archive.notifications (`+notificationColumns+`) SELECT `+notificationColumns+
+			` FROM main.notifications WHERE `+retentionCondition, cutoff, cutoff); err != nil {
+			return nil, fmt.Errorf("归档通知失败: %w", err)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM main.notifications WHERE `+retentionCondition, cutoff, cutoff); err != nil {
+		return nil, fmt.Errorf("删除过期通知失败: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO main.meta(key, value) VALUES('retention_watermark', ?)
+		ON CONFLICT(key) DO UPDATE SET value=excluded.value
+	`, strconv.FormatInt(watermark, 10)); err != nil {
+		return nil, fmt.Errorf("记录清理水位失败: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.db.Exec(`VACUUM main`); err != nil {
+		logrus.Warnf("VACUUM 失败: %v", err)
+	}
+	return result, nil
+}
+
+// syncArchiveColumns 归档表是按当时的 notifications 表结构建的，表结构升级后补上新增的列
+func syncArchiveColumns(tx *sql.Tx) error {
+	archived, err := tableColumns(tx, "archive")
+	if err != nil {
+		return err
+	}
+	rows, err := tx.Query(`SELECT name, type, dflt_value FROM pragma_table_info('notifications', 'main')`)
+	if err != nil {
+		return err
+	}
+	var alters []string
+	for rows.Next() {
+		var name, typ string
+		var dflt sql.NullString
+		if err := rows.Scan(&name, &typ, &dflt); err != nil {
+			rows.Close()
+			return err
+		}
+		if archived[name] {
+			continue
+		}
+		alter := fmt.Sprintf(`ALTER TABLE archive.notifications ADD COLUMN %s %s`, name, typ)
+		if dflt.Valid {
+			alter += " DEFAULT " + dflt.String
+		}
+		alters = append(alters, alter)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return execStatements(alters...)(tx)
+}
+
+// tableColumns 返回指定库中 notifications 表的列名
+func tableColumns(tx *sql.Tx, schema string) (map[string]bool, error) {
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info('notifications', ?)`, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
+
+// RetentionWatermark 被保留策略清理掉的已完成通知中最晚的时间（Unix 秒），从未清理时为 0。
+// 扫描通知时起点不早于水位，避免被清理的旧通知重新被当作全新通知。
+func (s *NotificationStore) RetentionWatermark() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.retentionWatermarkLocked()
+}
+
+func (s *NotificationStore) retentionWatermarkLocked() (int64, error) {
+	var val string
+	err := s.db.QueryRow(`SELECT value FROM meta WHERE key='retention_watermark'`).Scan(&val)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("读取清理水位失败: %w", err)
+	}
+	return strconv.ParseInt(val, 10, 64)
+}
+
+// runRetentionLoop 启动时执行一次保留策略，之后每天执行一次，直到 ctx 取消
+func runRetentionLoop(ctx context.Context, policy RetentionPolicy) {
+	logrus.Infof("通知保留策略已启用：保留 %d 天，过期记录 %s", policy.Days, policy.Mode)
+
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+
+	for {
+		store, err := GetNotificationStore()
+		if err != nil {
+			logrus.Errorf("通知保留策略：初始化状态数据库失败: %v", err)
+		} else if result, err := store.ApplyRetention(policy); err != nil {
+			logrus.Errorf("通知保留策略执行失败: %v", err)
+		} else if result.Removed > 0 {
+			logrus.Infof("通知保留策略：清理 %d 条已完成通知（%s），水位 %d", result.Removed, policy.Mode, result.Watermark)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
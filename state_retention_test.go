@@ -0,0 +1,145 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) *NotificationStore {
+	t.Helper()
+	store, err := newNotificationStore(filepath.Join(t.TempDir(), "notifications.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// insertTestNotification 直接写入一条通知，保留调用方给定的时间字段
+func insertTestNotification(t *testing.T, store *NotificationStore, r NotificationRecord) {
+	t.Helper()
+	_, err := store.db.Exec(`INSERT INTO notifications (id, status, feed_id, comment_id, comment_content, reply_content,
+		user_id, relation_type, notif_time_unix, updated_at, created_at, priority)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, r.ID, string(r.Status), r.FeedID, r.CommentID, r.CommentContent, r.ReplyContent,
+		r.UserID, r.RelationType, r.NotifTimeUnix, r.UpdatedAt, r.CreatedAt, string(r.Priority))
+	require.NoError(t, err)
+}
+
+func notificationIDs(t *testing.T, db *sql.DB, table string) []string {
+	t.Helper()
+	rows, err := db.Query(`SELECT id FROM ` + table + ` ORDER BY id`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		require.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	require.NoError(t, rows.Err())
+	return ids
+}
+
+// seedRetentionFixtures 写入保留策略的测试数据，返回应被清理记录中最晚的时间
+func seedRetentionFixtures(t *testing.T, store *NotificationStore) int64 {
+	now := time.Now()
+	old := now.AddDate(0, 0, -40).Unix()
+	older := now.AddDate(0, 0, -50).Unix()
+	recent := now.AddDate(0, 0, -5).Unix()
+	// notif_time_unix 缺失的记录，时间从 notification_id 高 32 位取
+	idTime := now.AddDate(0, 0, -35).Unix()
+	idWithTime := strconv.FormatUint(uint64(idTime)<<32|7, 10)
+
+	for _, r := range []NotificationRecord{
+		{ID: "a-old-replied", Status: StatusReplied, NotifTimeUnix: older, UpdatedAt: recent},
+		{ID: "b-old-skipped", Status: StatusSkipped, NotifTimeUnix: old, UpdatedAt: old, Priority: PriorityHigh},
+		{ID: "c-old-pending", Status: StatusPending, NotifTimeUnix: older, UpdatedAt: older},
+		{ID: "d-old-retry", Status: StatusRetry, NotifTimeUnix: older, UpdatedAt: older},
+		{ID: "e-recent-replied", Status: StatusReplied, NotifTimeUnix: recent, UpdatedAt: recent},
+		{ID: idWithTime, Status: StatusReplied, UpdatedAt: older},
+		{ID: "f-no-time-recent", Status: StatusReplied, UpdatedAt: recent},
+	} {
+		insertTestNotification(t, store, r)
+	}
+	return idTime
+}
+
+func TestApplyRetentionPurge(t *testing.T) {
+	store := newTestStore(t)
+	wantWatermark := seedRetentionFixtures(t, store)
+
+	result, err := store.ApplyRetention(RetentionPolicy{Days: 30, Mode: RetentionPurge})
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Removed)
+	require.Empty(t, result.ArchivePath)
+	require.Equal(t, wantWatermark, result.Watermark)
+
+	// 未完成的通知、未过期的通知、缺少通知时间但最近更新过的通知都保留
+	require.ElementsMatch(t, []string{"c-old-pending", "d-old-retry", "e-recent-replied", "f-no-time-recent"},
+		notificationIDs(t, store.db, "notifications"))
+
+	watermark, err := store.RetentionWatermark()
+	require.NoError(t, err)
+	require.Equal(t, wantWatermark, watermark)
+
+	// 没有新的过期记录时水位不回退
+	result, err = store.ApplyRetention(RetentionPolicy{Days: 30, Mode: RetentionPurge})
+	require.NoError(t, err)
+	require.Zero(t, result.Removed)
+	require.Equal(t, wantWatermark, result.Watermark)
+	watermark, err = store.RetentionWatermark()
+	require.NoError(t, err)
+	require.Equal(t, wantWatermark, watermark)
+}
+
+func TestApplyRetentionDisabled(t *testing.T) {
+	store := newTestStore(t)
+	seedRetentionFixtures(t, store)
+
+	result, err := store.ApplyRetention(RetentionPolicy{Days: 0, Mode: RetentionPurge})
+	require.NoError(t, err)
+	require.Zero(t, result.Removed)
+	require.Len(t, notificationIDs(t, store.db, "notifications"), 7)
+
+	watermark, err := store.RetentionWatermark()
+	require.NoError(t, err)
+	require.Zero(t, watermark, "从未清理时水位为 0")
+}
+
+func TestApplyRetentionArchiveSyncsColumns(t *testing.T) {
+	store := newTestStore(t)
+	archivePath := store.dbPath + ".archive.db"
+
+	// 旧版本建的归档表：没有之后新增的 priority 等列，已有一条归档记录
+	archiveDB, err := sql.Open("sqlite", archivePath)
+	require.NoError(t, err)
+	_, err = archiveDB.Exec(`CREATE TABLE notifications (
+		id TEXT, status TEXT, retry_count INTEGER, feed_id TEXT, xsec_token TEXT, comment_id TEXT,
+		parent_comment_id TEXT, comment_content TEXT, user_id TEXT, user_nickname TEXT,
+		note_title TEXT, relation_type TEXT, notif_time_unix INTEGER, reply_content TEXT,
+		updated_at INTEGER, created_at INTEGER
+	); INSERT INTO notifications (id, status) VALUES ('archived-before', 'replied')`)
+	require.NoError(t, err)
+	require.NoError(t, archiveDB.Close())
+
+	seedRetentionFixtures(t, store)
+	result, err := store.ApplyRetention(RetentionPolicy{Days: 30, Mode: RetentionArchive})
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Removed)
+	require.Equal(t, archivePath, result.ArchivePath)
+	require.Len(t, notificationIDs(t, store.db, "notifications"), 4)
+
+	archiveDB, err = sql.Open("sqlite", archivePath)
+	require.NoError(t, err)
+	defer archiveDB.Close()
+	require.Len(t, notificationIDs(t, archiveDB, "notifications"), 4)
+
+	var priority string
+	require.NoError(t, archiveDB.QueryRow(`SELECT priority FROM notifications WHERE id='b-old-skipped'`).Scan(&priority))
+	require.Equal(t, "high", priority, "新增的列应同步到归档表并带上数据")
+	require.NoError(t, archiveDB.QueryRow(`SELECT priority FROM notifications WHERE id='archived-before'`).Scan(&priority))
+	require.Empty(t, priority, "已有的归档记录取新列的默认值")
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveReply(t *testing.T) {
+	store := newTestStore(t)
+	target := ReplyGuardRecord{FeedID: "f1", TargetCommentID: "c1", ContentHash: replyContentHash("第一次"), Content: "第一次"}
+
+	id, previous, err := store.ReserveReply(target, false)
+	require.NoError(t, err)
+	require.NotZero(t, id)
+	require.Empty(t, previous)
+
+	// 已有记录（此时仍是 pending）时不写入
+	id2, previous, err := store.ReserveReply(target, false)
+	require.NoError(t, err)
+	require.Zero(t, id2)
+	require.Len(t, previous, 1)
+	require.Equal(t, ReplyGuardPending, previous[0].Status)
+
+	require.NoError(t, store.FinishReply(id, ReplyGuardPosted, "r1"))
+	id2, previous, err = store.ReserveReply(target, true)
+	require.NoError(t, err)
+	require.NotZero(t, id2, "allowDuplicate 时照常写入")
+	require.Len(t, previous, 1)
+	require.Equal(t, ReplyGuardPosted, previous[0].Status)
+	require.Equal(t, "r1", previous[0].ReplyCommentID)
+	require.Equal(t, "第一次", previous[0].Content)
+
+	require.NoError(t, store.CancelReply(id2))
+	replies, err := store.FindReplies("f1", "c1")
+	require.NoError(t, err)
+	require.Len(t, replies, 1)
+	require.Equal(t, id, replies[0].ID)
+
+	// 其他笔记下的同名评论互不影响
+	replies, err = store.FindReplies("f2", "c1")
+	require.NoError(t, err)
+	require.Empty(t, replies)
+}
+
+func TestMarkRepliedByComment(t *testing.T) {
+	store := newTestStore(t)
+	for _, r := range []NotificationRecord{
+		{ID: "pending", Status: StatusPending, FeedID: "f1", CommentID: "c1"},
+		{ID: "retry", Status: StatusRetry, FeedID: "f1", CommentID: "c1"},
+		{ID: "deleted-check", Status: StatusDeletedCheck, FeedID: "f1", CommentID: "c1"},
+		{ID: "no-feed", Status: StatusPending, CommentID: "c1", ReplyContent: "草稿"},
+		{ID: "skipped", Status: StatusSkipped, FeedID: "f1", CommentID: "c1"},
+		{ID: "other-feed", Status: StatusPending, FeedID: "f2", CommentID: "c1"},
+		{ID: "other-comment", Status: StatusPending, FeedID: "f1", CommentID: "c2"},
+	} {
+		insertTestNotification(t, store, r)
+	}
+
+	n, err := store.MarkRepliedByComment("f1", "c1", "")
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+
+	wantStatus := map[string]NotificationStatus{
+		"pending": StatusReplied, "retry": StatusReplied, "deleted-check": StatusReplied, "no-feed": StatusReplied,
+		"skipped": StatusSkipped, "other-feed": StatusPending, "other-comment": StatusPending,
+	}
+	for id, want := range wantStatus {
+		r, err := store.GetRecord(id)
+		require.NoError(t, err)
+		require.Equal(t, want, r.Status, id)
+	}
+
+	// 回复内容为空时保留通知原有的 reply_content
+	r, err := store.GetRecord("no-feed")
+	require.NoError(t, err)
+	require.Equal(t, "草稿", r.ReplyContent)
+
+	insertTestNotification(t, store, NotificationRecord{ID: "later", Status: StatusPending, CommentID: "c1"})
+	n, err = store.MarkRepliedByComment("f1", "c1", "谢谢")
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	r, err = store.GetRecord("later")
+	require.NoError(t, err)
+	require.Equal(t, "谢谢", r.ReplyContent)
+}
+
+func insertSearchFixtures(t *testing.T, store *NotificationStore) {
+	t.Helper()
+	for _, r := range []NotificationRecord{
+		{ID: "n1", Status: StatusPending, RelationType: "comment_on_my_note", UserID: "u1", FeedID: "f1", NotifTimeUnix: 100, CommentContent: "请问链接在哪"},
+		{ID: "n2", Status: StatusReplied, RelationType: "reply_to_my_comment", UserID: "u2", FeedID: "f1", NotifTimeUnix: 200, CommentContent: "好看", ReplyContent: "谢谢喜欢"},
+		{ID: "n3", Status: StatusSkipped, RelationType: "comment_on_my_note", UserID: "u1", FeedID: "f2", NotifTimeUnix: 300, CommentContent: "打 5 折吗"},
+		{ID: "n4", Status: StatusRetry, RelationType: "mentioned_me", UserID: "u3", FeedID: "f2", NotifTimeUnix: 400, CommentContent: "100% 同意"},
+		{ID: "n5", Status: StatusPending, RelationType: "comment_on_my_note", UserID: "u2", FeedID: "f1", NotifTimeUnix: 500, CommentContent: "user_name 是什么"},
+		{ID: "n6", Status: StatusDeletedCheck, RelationType: "comment_on_my_note", UserID: "u1", FeedID: "f3", NotifTimeUnix: 500, CommentContent: `路径 C:\temp`},
+	} {
+		insertTestNotification(t, store, r)
+	}
+}
+
+func TestSearchNotificationsFilters(t *testing.T) {
+	store := newTestStore(t)
+	insertSearchFixtures(t, store)
+
+	tests := []struct {
+		name  string
+		query NotificationQuery
+		want  []string
+	}{
+		{name: "无条件按时间倒序", query: NotificationQuery{}, want: []string{"n6", "n5", "n4", "n3", "n2", "n1"}},
+		{name: "状态", query: NotificationQuery{Statuses: []NotificationStatus{StatusPending, StatusRetry}}, want: []string{"n5", "n4", "n1"}},
+		{name: "通知类型", query: NotificationQuery{RelationTypes: []string{"reply_to_my_comment", "mentioned_me"}}, want: []string{"n4", "n2"}},
+		{name: "用户", query: NotificationQuery{UserID: "u2"}, want: []string{"n5", "n2"}},
+		{name: "笔记", query: NotificationQuery{FeedID: "f2"}, want: []string{"n4", "n3"}},
+		{name: "起始时间含边界", query: NotificationQuery{SinceUnix: 400}, want: []string{"n6", "n5", "n4"}},
+		{name: "结束时间含边界", query: NotificationQuery{UntilUnix: 200}, want: []string{"n2", "n1"}},
+		{name: "评论内容关键词", query: NotificationQuery{Keyword: "链接"}, want: []string{"n1"}},
+		{name: "回复内容关键词", query: NotificationQuery{Keyword: " 谢谢 "}, want: []string{"n2"}},
+		{name: "关键词 % 按字面匹配", query: NotificationQuery{Keyword: "0%"}, want: []string{"n4"}},
+		{name: "单独的 % 不匹配全部", query: NotificationQuery{Keyword: "%"}, want: []string{"n4"}},
+		{name: "关键词 _ 按字面匹配", query: NotificationQuery{Keyword: "r_n"}, want: []string{"n5"}},
+		{name: "单独的 _ 不匹配全部", query: NotificationQuery{Keyword: "_"}, want: []string{"n5"}},
+		{name: "关键词中的反斜杠", query: NotificationQuery{Keyword: `C:\t`}, want: []string{"n6"}},
+		{
+			name:  "组合条件同时满足",
+			query: NotificationQuery{Statuses: []NotificationStatus{StatusPending, StatusSkipped}, UserID: "u1", RelationTypes: []string{"comment_on_my_note"}, SinceUnix: 150},
+			want:  []string{"n3"},
+		},
+		{name: "组合条件无结果", query: NotificationQuery{FeedID: "f1", Keyword: "折"}, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := store.SearchNotifications(tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, recordIDs(result.Records))
+			require.Equal(t, len(tt.want), result.Total)
+			require.False(t, result.HasMore)
+		})
+	}
+}
+
+func TestSearchNotificationsPaging(t *testing.T) {
+	store := newTestStore(t)
+	insertSearchFixtures(t, store)
+
+	tests := []struct {
+		name           string
+		query          NotificationQuery
+		want           []string
+		wantTotal      int
+		wantOffset     int
+		wantNextOffset int
+		wantHasMore    bool
+	}{
+		{name: "第一页", query: NotificationQuery{Limit: 2}, want: []string{"n6", "n5"}, wantTotal: 6, wantNextOffset: 2, wantHasMore: true},
+		{name: "中间页", query: NotificationQuery{Limit: 2, Offset: 2}, want: []string{"n4", "n3"}, wantTotal: 6, wantOffset: 2, wantNextOffset: 4, wantHasMore: true},
+		{name: "最后一页", query: NotificationQuery{Limit: 4, Offset: 4}, want: []string{"n2", "n1"}, wantTotal: 6, wantOffset: 4, wantNextOffset: 6},
+		{name: "超出范围", query: NotificationQuery{Offset: 10}, want: []string{}, wantTotal: 6, wantOffset: 10, wantNextOffset: 10},
+		{name: "负 offset 按 0", query: NotificationQuery{Limit: 1, Offset: -3}, want: []string{"n6"}, wantTotal: 6, wantNextOffset: 1, wantHasMore: true},
+		{name: "总数按过滤条件统计", query: NotificationQuery{FeedID: "f1", Limit: 1, Offset: 1}, want: []string{"n2"}, wantTotal: 3, wantOffset: 1, wantNextOffset: 2, wantHasMore: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := store.SearchNotifications(tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, recordIDs(result.Records))
+			require.Equal(t, tt.wantTotal, result.Total)
+			require.Equal(t, tt.wantOffset, result.Offset)
+			require.Equal(t, tt.wantNextOffset, result.NextOffset)
+			require.Equal(t, tt.wantHasMore, result.HasMore)
+		})
+	}
+}
+
+func TestSearchNotificationsLimit(t *testing.T) {
+	store := newTestStore(t)
+	records := make([]NotificationRecord, 120)
+	for i := range records {
+		records[i] = NotificationRecord{ID: fmt.Sprintf("n%03d", i), NotifTimeUnix: int64(i)}
+	}
+	_, err := store.UpsertNotifications(records)
+	require.NoError(t, err)
+
+	result, err := store.SearchNotifications(NotificationQuery{})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 20, "默认每页 20 条")
+	require.Equal(t, 120, result.Total)
+
+	result, err = store.SearchNotifications(NotificationQuery{Limit: 500})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 100, "每页最多 100 条")
+	require.True(t, result.HasMore)
+}
+
+func TestBuildNotificationFilter(t *testing.T) {
+	where, args := buildNotificationFilter(NotificationQuery{})
+	require.Empty(t, where)
+	require.Empty(t, args)
+
+	where, args = buildNotificationFilter(NotificationQuery{Statuses: []NotificationStatus{StatusPending, StatusRetry}, FeedID: "f1", Keyword: `50%_\`})
+	require.Equal(t, ` WHERE status IN (?,?) AND feed_id = ? AND (comment_content LIKE ? ESCAPE '\' OR reply_content LIKE ? ESCAPE '\')`, where)
+	require.Equal(t, []any{"pending", "retry", "f1", `%50\%\_\\%`, `%50\%\_\\%`}, args)
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TriagePriority 分拣规则给通知打的优先级
+type TriagePriority string
+
+const (
+	PriorityHigh   TriagePriority = "high"
+	PriorityNormal TriagePriority = "normal"
+	PriorityLow    TriagePriority = "low"
+)
+
+// TriageAction 规则命中后的动作
+type TriageAction string
+
+const (
+	TriageActionSkip     TriageAction = "skip"     // 直接标记为 skipped，不进入待处理列表
+	TriageActionSuggest  TriageAction = "suggest"  // 按模板生成建议回复，仍为 pending
+	TriageActionPriority TriageAction = "priority" // 只打优先级，仍为 pending
+)
+
+// TriageRule 一条通知分拣规则。设置了的条件全部满足才算命中，规则按顺序匹配，第一条命中的生效。
+type TriageRule struct {
+	Name     string `json:"name"`
+	Disabled bool   `json:"disabled,omitempty"`
+
+	// Keywords 评论内容包含任一关键词（不区分大小写）
+	Keywords []string `json:"keywords,omitempty"`
+	// Pattern 评论内容匹配的正则
+	Pattern       string   `json:"pattern,omitempty"`
+	RelationTypes []string `json:"relation_types,omitempty"`
+	// UserIDs 只对这些用户生效（如拉黑名单）
+	UserIDs []string `json:"user_ids,omitempty"`
+	// ExcludeUserIDs 这些用户不受该规则影响（白名单）
+	ExcludeUserIDs []string `json:"exclude_user_ids,omitempty"`
+	FeedIDs        []string `json:"feed_ids,omitempty"`
+	// NoteKeywords 笔记标题包含任一关键词
+	NoteKeywords []string `json:"note_keywords,omitempty"`
+
+	Action TriageAction `json:"action"`
+	// Template suggest 的回复模板，可使用 {nickname}、{note_title}
+	Template string `json:"template,omitempty"`
+	// Priority 命中后的优先级，priority 动作必填，suggest 可选
+	Priority TriagePriority `json:"priority,omitempty"`
+}
+
+// triageRulesFile 规则文件的格式
+type triageRulesFile struct {
+	Rules []TriageRule `json:"rules"`
+}
+
+type compiledTriageRule struct {
+	TriageRule
+	pattern *regexp.Regexp
+}
+
+// compileTriageRule 校验规则并编译正则
+func compileTriageRule(rule TriageRule) (compiledTriageRule, error) {
+	c := compiledTriageRule{TriageRule: rule}
+	if strings.TrimSpace(rule.Name) == "" {
+		return c, errors.New("规则缺少 name")
+	}
+
+	switch rule.Action {
+	case TriageActionSkip:
+	case TriageActionSuggest:
+		if strings.TrimSpace(rule.Template) == "" {
+			return c, fmt.Errorf("规则 %s: suggest 动作需要 template", rule.Name)
+		}
+	case TriageActionPriority:
+		if rule.Priority == "" {
+			return c, fmt.Errorf("规则 %s: priority 动作需要 priority", rule.Name)
+		}
+	default:
+		return c, fmt.Errorf("规则 %s: 无效的 action %q，合法值：skip / suggest / priority", rule.Name, rule.Action)
+	}
+	switch rule.Priority {
+	case "", PriorityHigh, PriorityNormal, PriorityLow:
+	default:
+		return c, fmt.Errorf("规则 %s: 无效的 priority %q，合法值：high / normal / low", rule.Name, rule.Priority)
+	}
+
+	if len(rule.Keywords) == 0 && rule.Pattern == "" && len(rule.RelationTypes) == 0 &&
+		len(rule.UserIDs) == 0 && len(rule.FeedIDs) == 0 && len(rule.NoteKeywords) == 0 {
+		return c, fmt.Errorf("规则 %s 没有任何匹配条件", rule.Name)
+	}
+	if rule.Pattern != "" {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return c, fmt.Errorf("规则 %s: 正则无效: %w", rule.Name, err)
+		}
+		c.pattern = re
+	}
+	return c, nil
+}
+
+// matches 判断通知是否满足规则的全部条件
+func (c *compiledTriageRule) matches(r *NotificationRecord) bool {
+	if c.Disabled {
+		return false
+	}
+	if len(c.Keywords) > 0 && !containsAnyFold(r.CommentContent, c.Keywords) {
+		return false
+	}
+	if c.pattern != nil && !c.pattern.MatchString(r.CommentContent) {
+		return false
+	}
+	if len(c.RelationTypes) > 0 && !containsString(c.RelationTypes, r.RelationType) {
+		return false
+	}
+	if len(c.UserIDs) > 0 && !containsString(c.UserIDs, r.UserID) {
+		return false
+	}
+	if containsString(c.ExcludeUserIDs, r.UserID) {
+		return false
+	}
+	if len(c.FeedIDs) > 0 && !containsString(c.FeedIDs, r.FeedID) {
+		return false
+	}
+	if len(c.NoteKeywords) > 0 && !containsAnyFold(r.NoteTitle, c.NoteKeywords) {
+		return false
+	}
+	return true
+}
+
+// apply 把规则的动作写到通知记录上
+func (c *compiledTriageRule) apply(r *NotificationRecord) {
+	r.TriageRule = c.Name
+	r.Priority = c.Priority
+	switch c.Action {
+	case TriageActionSkip:
+		r.Status = StatusSkipped
+		r.Priority = ""
+	case TriageActionSuggest:
+		r.SuggestedReply = strings.NewReplacer(
+			"{nickname}", r.UserNickname,
+			"{note_title}", r.NoteTitle,
+		).Replace(c.Template)
+	}
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAnyFold(s string, keywords []string) bool {
+	s = strings.ToLower(s)
+	for _, kw := range keywords {
+		if kw != "" && strings.Contains(s, strings.ToLower(kw)) {
+			return true
+		}
+	}
+	return false
+}
+
+// TriageRules 通知分拣规则，保存在 JSON 文件中。
+// 手动修改文件后下次分拣时自动重新加载；通过 MCP 工具修改时立即写回文件。
+type TriageRules struct {
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	rules   []compiledTriageRule
+}
+
+// defaultTriageRulesPath 默认规则文件：与通知数据库同目录的 notification_rules.json
+func defaultTriageRulesPath() string {
+	return filepath.Join(filepath.Dir(getDBPath()), "notification_rules.json")
+}
+
+// LoadTriageRules 加载规则文件，文件不存在时为空规则集
+func LoadTriageRules(path string) (*TriageRules, error) {
+	t := &TriageRules{path: path}
+	if err := t.reloadLocked(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Path 规则文件路径
+func (t *TriageRules) Path() string {
+	return t.path
+}
+
+// reloadLocked 文件修改时间变化时重新加载
+func (t *TriageRules) reloadLocked() error {
+	info, err := os.Stat(t.path)
+	if os.IsNotExist(err) {
+		t.rules = nil
+		t.modTime = time.Time{}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取规则文件失败: %w", err)
+	}
+	if info.ModTime().Equal(t.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		return fmt.Errorf("读取规则文件失败: %w", err)
+	}
+	var file triageRulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("解析规则文件 %s 失败: %w", t.path, err)
+	}
+	rules := make([]compiledTriageRule, 0, len(file.Rules))
+	names := make(map[string]bool)
+	for _, rule := range file.Rules {
+		c, err := compileTriageRule(rule)
+		if err != nil {
+			return fmt.Errorf("规则文件 %s: %w", t.path, err)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("规则文件 %s: 规则名 %s 重复", t.path, rule.Name)
+		}
+		names[rule.Name] = true
+		rules = append(rules, c)
+	}
+
+	t.rules = rules
+	t.modTime = info.ModTime()
+	logrus.Infof("已加载 %d 条通知分拣规则: %s", len(rules), t.path)
+	return nil
+}
+
+// saveLocked 写临时文件后重命名，避免写到一半的文件被读到
+func (t *TriageRules) saveLocked() error {
+	file := triageRulesFile{Rules: make([]TriageRule, 0, len(t.rules))}
+	for _, c := range t.rules {
+		file.Rules = append(file.Rules, c.TriageRule)
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return fmt.Errorf("创建规则目录失败: %w", err)
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入规则文件失败: %w", err)
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		return fmt.Errorf("写入规则文件失败: %w", err)
+	}
+	if info, err := os.Stat(t.path); err == nil {
+		t.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// List 返回当前规则（按匹配顺序）
+func (t *TriageRules) List() ([]TriageRule, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.reloadLocked(); err != nil {
+		return nil, err
+	}
+	rules := make([]TriageRule, 0, len(t.rules))
+	for _, c := range t.rules {
+		rules = append(rules, c.TriageRule)
+	}
+	return rules, nil
+}
+
+// Set 按名称新增或替换规则并写回文件。新规则追加到末尾，已有规则保持原位置；返回是否为新增。
+// 规则文件有误时在上一次加载的规则上修改并覆盖该文件，以便通过 MCP 工具修复坏掉的文件
+func (t *TriageRules) Set(rule TriageRule) (bool, error) {
+	c, err := compileTriageRule(rule)
+	if err != nil {
+		return false, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.reloadForEditLocked()
+	for i := range t.rules {
+		if t.rules[i].Name == rule.Name {
+			t.rules[i] = c
+			return false, t.saveLocked()
+		}
+	}
+	t.rules = append(t.rules, c)
+	return true, t.saveLocked()
+}
+
+// Delete 删除规则并写回文件，规则不存在时返回 false。规则文件有误时同 Set
+func (t *TriageRules) Delete(name string) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.reloadForEditLocked()
+	for i := range t.rules {
+		if t.rules[i].Name == name {
+			t.rules = append(t.rules[:i], t.rules[i+1:]...)
+			return true, t.saveLocked()
+		}
+	}
+	return false, nil
+}
+
+// reloadForEditLocked 修改规则前重新加载，文件有误时保留上一次加载的规则，修改后写回会覆盖该文件
+func (t *TriageRules) reloadForEditLocked() {
+	if err := t.reloadLocked(); err != nil {
+		logrus.Warnf("重新加载分拣规则失败，将在已加载的规则上修改并覆盖规则文件: %v", err)
+	}
+}
+
+// Apply 对待入库的新通知执行分拣，直接修改 records 中命中规则的记录，返回命中的条数。
+// 只处理 pending 状态（或未指定状态）的记录；规则文件有误时保留上一次加载的规则。
+func (t *TriageRules) Apply(records []NotificationRecord) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.reloadLocked(); err != nil {
+		logrus.Warnf("重新加载分拣规则失败，继续使用已加载的规则: %v", err)
+	}
+
+	matched := 0
+	for i := range records {
+		r := &records[i]
+		if r.Status != "" && r.Status != StatusPending {
+			continue
+		}
+		for j := range t.rules {
+			if t.rules[j].matches(r) {
+				t.rules[j].apply(r)
+				matched++
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// triagePriorityRank 排序用：high 在前，low 在后，未设置视为 normal
+func triagePriorityRank(p TriagePriority) int {
+	switch p {
+	case PriorityHigh:
+		return 0
+	case PriorityLow:
+		return 2
+	default:
+		return 1
+	}
+}
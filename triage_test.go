@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileTriageRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    TriageRule
+		wantErr bool
+	}{
+		{name: "skip", rule: TriageRule{Name: "spam", Keywords: []string{"加v"}, Action: TriageActionSkip}},
+		{name: "suggest", rule: TriageRule{Name: "thanks", Keywords: []string{"谢谢"}, Action: TriageActionSuggest, Template: "不客气～"}},
+		{name: "priority", rule: TriageRule{Name: "vip", UserIDs: []string{"u1"}, Action: TriageActionPriority, Priority: PriorityHigh}},
+		{name: "缺少 name", rule: TriageRule{Keywords: []string{"a"}, Action: TriageActionSkip}, wantErr: true},
+		{name: "无效 action", rule: TriageRule{Name: "r", Keywords: []string{"a"}, Action: "delete"}, wantErr: true},
+		{name: "suggest 缺少模板", rule: TriageRule{Name: "r", Keywords: []string{"a"}, Action: TriageActionSuggest}, wantErr: true},
+		{name: "priority 缺少优先级", rule: TriageRule{Name: "r", Keywords: []string{"a"}, Action: TriageActionPriority}, wantErr: true},
+		{name: "无效 priority", rule: TriageRule{Name: "r", Keywords: []string{"a"}, Action: TriageActionPriority, Priority: "urgent"}, wantErr: true},
+		{name: "没有条件", rule: TriageRule{Name: "r", Action: TriageActionSkip}, wantErr: true},
+		{name: "只有排除名单不算条件", rule: TriageRule{Name: "r", ExcludeUserIDs: []string{"u1"}, Action: TriageActionSkip}, wantErr: true},
+		{name: "无效正则", rule: TriageRule{Name: "r", Pattern: "(", Action: TriageActionSkip}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileTriageRule(tt.rule)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTriageRulesApply(t *testing.T) {
+	rules := []TriageRule{
+		{Name: "spam", Pattern: `(?i)加\s*v`, ExcludeUserIDs: []string{"friend"}, Action: TriageActionSkip},
+		{Name: "vip", UserIDs: []string{"vip"}, Action: TriageActionPriority, Priority: PriorityHigh},
+		{Name: "off", Keywords: []string{"谢谢"}, Disabled: true, Action: TriageActionPriority, Priority: PriorityLow},
+		{Name: "thanks", Keywords: []string{"谢谢", "THX"}, Action: TriageActionSuggest, Template: "@{nickname} 谢谢支持《{note_title}》", Priority: PriorityNormal},
+		{Name: "tutorial", NoteKeywords: []string{"教程"}, RelationTypes: []string{"comment"}, FeedIDs: []string{"f1"}, Action: TriageActionPriority, Priority: PriorityLow},
+	}
+
+	tests := []struct {
+		name         string
+		record       NotificationRecord
+		wantRule     string
+		wantStatus   NotificationStatus
+		wantPriority TriagePriority
+		wantSuggest  string
+	}{
+		{
+			name:       "skip 标记为已跳过且不带优先级",
+			record:     NotificationRecord{UserID: "u1", CommentContent: "加 V 领资料"},
+			wantRule:   "spam",
+			wantStatus: StatusSkipped,
+		},
+		{
+			name:         "排除名单中的用户跳过该规则，继续匹配后面的规则",
+			record:       NotificationRecord{UserID: "friend", CommentContent: "加v，谢谢"},
+			wantRule:     "thanks",
+			wantPriority: PriorityNormal,
+			wantSuggest:  "@ 谢谢支持《》",
+		},
+		{
+			name:         "多条规则命中时第一条生效",
+			record:       NotificationRecord{UserID: "vip", CommentContent: "谢谢"},
+			wantRule:     "vip",
+			wantPriority: PriorityHigh,
+		},
+		{
+			name:         "停用的规则不匹配，关键词不区分大小写，模板替换昵称和标题",
+			record:       NotificationRecord{UserID: "u2", UserNickname: "小红", NoteTitle: "穿搭", CommentContent: "thx!"},
+			wantRule:     "thanks",
+			wantPriority: PriorityNormal,
+			wantSuggest:  "@小红 谢谢支持《穿搭》",
+		},
+		{
+			name:         "多个条件全部满足才命中",
+			record:       NotificationRecord{FeedID: "f1", RelationType: "comment", NoteTitle: "入门教程"},
+			wantRule:     "tutorial",
+			wantPriority: PriorityLow,
+		},
+		{
+			name:   "部分条件不满足",
+			record: NotificationRecord{FeedID: "f2", RelationType: "comment", NoteTitle: "入门教程"},
+		},
+		{
+			name:       "非 pending 的记录不处理",
+			record:     NotificationRecord{Status: StatusReplied, CommentContent: "加v"},
+			wantStatus: StatusReplied,
+		},
+	}
+
+	triage := newTestTriageRules(t, rules)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := []NotificationRecord{tt.record}
+			matched := triage.Apply(records)
+			r := records[0]
+			if tt.wantRule == "" {
+				require.Zero(t, matched)
+			} else {
+				require.Equal(t, 1, matched)
+			}
+			require.Equal(t, tt.wantRule, r.TriageRule)
+			require.Equal(t, tt.wantStatus, r.Status)
+			require.Equal(t, tt.wantPriority, r.Priority)
+			require.Equal(t, tt.wantSuggest, r.SuggestedReply)
+		})
+	}
+}
+
+func TestTriageRulesReloadAndSave(t *testing.T) {
+	triage := newTestTriageRules(t, []TriageRule{
+		{Name: "a", Keywords: []string{"a"}, Action: TriageActionSkip},
+	})
+
+	// 手动修改文件后重新加载
+	writeTriageRulesFile(t, triage.Path(), `{"rules":[{"name":"b","keywords":["b"],"action":"skip"}]}`)
+	requireTriageRuleNames(t, triage, "b")
+
+	created, err := triage.Set(TriageRule{Name: "c", Keywords: []string{"c"}, Action: TriageActionSkip})
+	require.NoError(t, err)
+	require.True(t, created)
+	created, err = triage.Set(TriageRule{Name: "b", Keywords: []string{"bb"}, Action: TriageActionSkip})
+	require.NoError(t, err)
+	require.False(t, created, "同名规则原位替换")
+	deleted, err := triage.Delete("missing")
+	require.NoError(t, err)
+	require.False(t, deleted)
+
+	// 写回的文件重新加载后内容一致
+	loaded, err := LoadTriageRules(triage.Path())
+	require.NoError(t, err)
+	rules, err := loaded.List()
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	require.Equal(t, "b", rules[0].Name)
+	require.Equal(t, []string{"bb"}, rules[0].Keywords)
+	require.Equal(t, "c", rules[1].Name)
+
+	deleted, err = triage.Delete("b")
+	require.NoError(t, err)
+	require.True(t, deleted)
+	requireTriageRuleNames(t, loaded, "c")
+}
+
+func TestTriageRulesEditOverwritesBrokenFile(t *testing.T) {
+	triage := newTestTriageRules(t, []TriageRule{
+		{Name: "a", Keywords: []string{"a"}, Action: TriageActionSkip},
+	})
+
+	writeTriageRulesFile(t, triage.Path(), `{"rules":[`)
+	_, err := triage.List()
+	require.Error(t, err)
+	_, err = LoadTriageRules(triage.Path())
+	require.Error(t, err)
+
+	// 文件有误时分拣继续使用上一次加载的规则
+	records := []NotificationRecord{{CommentContent: "a"}}
+	require.Equal(t, 1, triage.Apply(records))
+
+	// 修改规则在已加载的规则上进行并覆盖坏掉的文件
+	created, err := triage.Set(TriageRule{Name: "b", Keywords: []string{"b"}, Action: TriageActionSkip})
+	require.NoError(t, err)
+	require.True(t, created)
+	loaded, err := LoadTriageRules(triage.Path())
+	require.NoError(t, err)
+	requireTriageRuleNames(t, loaded, "a", "b")
+
+	writeTriageRulesFile(t, triage.Path(), `{"rules":[{"name":"x","action":"skip"}]}`)
+	deleted, err := triage.Delete("a")
+	require.NoError(t, err)
+	require.True(t, deleted)
+	loaded, err = LoadTriageRules(triage.Path())
+	require.NoError(t, err)
+	requireTriageRuleNames(t, loaded, "b")
+}
+
+func newTestTriageRules(t *testing.T, rules []TriageRule) *TriageRules {
+	t.Helper()
+	data, err := json.Marshal(triageRulesFile{Rules: rules})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "notification_rules.json")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	triage, err := LoadTriageRules(path)
+	require.NoError(t, err)
+	return triage
+}
+
+// writeTriageRulesFile 模拟手动修改规则文件，修改时间设到将来以确保触发重新加载
+func writeTriageRulesFile(t *testing.T, path, content string) {
+	t.Helper()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	modTime := info.ModTime().Add(time.Second)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func requireTriageRuleNames(t *testing.T, triage *TriageRules, want ...string) {
+	t.Helper()
+	rules, err := triage.List()
+	require.NoError(t, err)
+	names := make([]string, 0, len(rules))
+	for _, r := range rules {
+		names = append(names, r.Name)
+	}
+	require.Equal(t, want, names)
+}
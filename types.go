@@ -52,6 +52,42 @@ type FeedDetailRequest struct {
 	XsecToken       string             `json:"xsec_token" binding:"required"`
 	LoadAllComments bool               `json:"load_all_comments,omitempty"`
 	CommentConfig   *CommentLoadConfig `json:"comment_config,omitempty"`
+	// 是否将图片原图、实况图和视频下载到本地
+	DownloadMedia bool `json:"download_media,omitempty"`
+}
+
+// FeedDetailBatchItem 批量获取详情的单条笔记
+type FeedDetailBatchItem struct {
+	FeedID    string `json:"feed_id" binding:"required"`
+	XsecToken string `json:"xsec_token" binding:"required"`
+}
+
+// FeedDetailBatchRequest 批量获取Feed详情请求
+type FeedDetailBatchRequest struct {
+	Feeds []FeedDetailBatchItem `json:"feeds" binding:"required,min=1,max=50,dive"`
+	// 同时打开的页面数，默认 3，最大 5
+	Concurrency int `json:"concurrency,omitempty"`
+}
+
+// FeedDetailBatchResult 单条笔记的获取结果
+type FeedDetailBatchResult struct {
+	FeedID  string `json:"feed_id"`
+	Success bool   `json:"success"`
+	// 成功时为笔记详情
+	Data  *xiaohongshu.FeedDetailResponse `json:"data,omitempty"`
+	Media *xiaohongshu.NoteMedia          `json:"media,omitempty"`
+	// 失败原因，笔记不可访问时为页面上的提示（如"该笔记已被删除"）
+	Error string `json:"error,omitempty"`
+	// 类型化错误的稳定错误代码，如 NOTE_DELETED、NOTE_PRIVATE
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+// FeedDetailBatchResponse 批量获取Feed详情响应，结果顺序与请求一致
+type FeedDetailBatchResponse struct {
+	Results   []FeedDetailBatchResult `json:"results"`
+	Total     int                     `json:"total"`
+	Succeeded int                     `json:"succeeded"`
+	Failed    int                     `json:"failed"`
 }
 
 type SearchFeedsRequest struct {
@@ -63,6 +99,8 @@ type SearchFeedsRequest struct {
 type FeedDetailResponse struct {
 	FeedID string `json:"feed_id"`
 	Data   any    `json:"data"`
+	// 整理后的媒体资源：原图、实况图视频、视频流、封面帧
+	Media *xiaohongshu.NoteMedia `json:"media,omitempty"`
 }
 
 // PostCommentRequest 发表评论请求
@@ -70,13 +108,16 @@ type PostCommentRequest struct {
 	FeedID    string `json:"feed_id" binding:"required"`
 	XsecToken string `json:"xsec_token" binding:"required"`
 	Content   string `json:"content" binding:"required"`
+	Image     string `json:"image,omitempty"`
 }
 
 // PostCommentResponse 发表评论响应
 type PostCommentResponse struct {
-	FeedID  string `json:"feed_id"`
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	FeedID string `json:"feed_id"`
+	// CommentID 新评论的 ID
+	CommentID string `json:"comment_id,omitempty"`
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
 }
 
 // ReplyCommentRequest 回复评论请求
@@ -86,21 +127,82 @@ type ReplyCommentRequest struct {
 	CommentID string `json:"comment_id" binding:"required_without=UserID"`
 	UserID    string `json:"user_id" binding:"required_without=CommentID"`
 	Content   string `json:"content" binding:"required"`
+	Image     string `json:"image,omitempty"`
+	// AllowDuplicate 允许再次回复已经回复过的评论
+	AllowDuplicate bool `json:"allow_duplicate,omitempty"`
 }
 
 // ReplyCommentResponse 回复评论响应
 type ReplyCommentResponse struct {
-	FeedID          string `json:"feed_id"`
+	FeedID string `json:"feed_id"`
+	// CommentID 新回复的 ID
+	CommentID       string `json:"comment_id,omitempty"`
 	TargetCommentID string `json:"target_comment_id,omitempty"`
 	TargetUserID    string `json:"target_user_id,omitempty"`
-	Success         bool   `json:"success"`
-	Message         string `json:"message"`
+	// Warning 之前回复过该评论时的提示
+	Warning string `json:"warning,omitempty"`
+	// NotificationsMarked 自动标记为 replied 的通知条数
+	NotificationsMarked int    `json:"notifications_marked,omitempty"`
+	Success             bool   `json:"success"`
+	Message             string `json:"message"`
+}
+
+// DeleteCommentRequest 删除评论请求
+type DeleteCommentRequest struct {
+	FeedID          string `json:"feed_id" binding:"required"`
+	XsecToken       string `json:"xsec_token" binding:"required"`
+	CommentID       string `json:"comment_id" binding:"required"`
+	ParentCommentID string `json:"parent_comment_id,omitempty"`
+}
+
+// DeleteCommentResponse 删除评论响应
+type DeleteCommentResponse struct {
+	FeedID    string `json:"feed_id"`
+	CommentID string `json:"comment_id"`
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
 }
 
 // UserProfileRequest 用户主页请求
 type UserProfileRequest struct {
 	UserID    string `json:"user_id" binding:"required"`
 	XsecToken string `json:"xsec_token" binding:"required"`
+	// Tab 笔记标签页：notes / collected / liked，为空时只返回首屏笔记
+	Tab string `json:"tab,omitempty" binding:"omitempty,oneof=notes collected liked"`
+	// MaxNotes 最多返回的笔记数，大于 0 时滚动加载更多
+	MaxNotes int `json:"max_notes,omitempty" binding:"omitempty,min=0,max=500"`
+}
+
+// FollowUserRequest 关注用户请求
+type FollowUserRequest struct {
+	UserID    string `json:"user_id" binding:"required"`
+	XsecToken string `json:"xsec_token" binding:"required"`
+	// Unfollow 为 true 时取消关注
+	Unfollow bool `json:"unfollow,omitempty"`
+}
+
+// FollowUserResponse 关注用户响应
+type FollowUserResponse struct {
+	UserID string `json:"user_id"`
+	// Following 操作后是否处于已关注状态
+	Following bool   `json:"following"`
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+}
+
+// NotificationSearchRequest 通知历史查询请求，所有条件均可选
+type NotificationSearchRequest struct {
+	Status       []string `json:"status,omitempty"`
+	RelationType []string `json:"relation_type,omitempty"`
+	UserID       string   `json:"user_id,omitempty"`
+	FeedID       string   `json:"feed_id,omitempty"`
+	// SinceUnix / UntilUnix 通知时间范围（Unix 秒）
+	SinceUnix int64 `json:"since_unix,omitempty" binding:"omitempty,min=0"`
+	UntilUnix int64 `json:"until_unix,omitempty" binding:"omitempty,min=0"`
+	// Keyword 在评论内容和回复内容中模糊匹配
+	Keyword string `json:"keyword,omitempty"`
+	Offset  int    `json:"offset,omitempty" binding:"omitempty,min=0"`
+	Limit   int    `json:"limit,omitempty" binding:"omitempty,min=0,max=100"`
 }
 
 // ActionResult 通用动作响应（点赞/收藏等）
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// WebhookEventNewNotifications 新通知入库事件
+	WebhookEventNewNotifications = "notification.new"
+
+	webhookQueueSize      = 100
+	webhookDefaultRetries = 5
+	webhookDefaultTimeout = 10 * time.Second
+	webhookMaxBackoff     = time.Minute
+)
+
+// WebhookConfig webhook 推送配置
+type WebhookConfig struct {
+	// URLs 接收推送的地址，每个地址独立投递和重试
+	URLs []string
+	// Secret 签名密钥，为空时不签名
+	Secret string
+	// MaxRetries 失败后的最大重试次数，<=0 时使用默认值 5
+	MaxRetries int
+	// Timeout 单次请求超时，<=0 时使用默认值 10s
+	Timeout time.Duration
+}
+
+// WebhookEvent 推送给 webhook 的事件
+type WebhookEvent struct {
+	Event         string               `json:"event"`
+	DeliveryID    string               `json:"delivery_id"`
+	Timestamp     int64                `json:"timestamp"`
+	Notifications []NotificationRecord `json:"notifications"`
+}
+
+// WebhookNotifier 把新通知异步推送到配置的 webhook 地址。
+//
+// 请求头：
+//   - X-Xhs-Event: 事件类型
+//   - X-Xhs-Delivery: 投递 ID（重试时不变，接收方可据此去重）
+//   - X-Xhs-Timestamp: 签名时间戳（Unix 秒）
+//   - X-Xhs-Signature: sha256=<hex>，HMAC-SHA256(secret, timestamp + "." + body)
+//
+// 网络错误、429 和 5xx 会按指数退避重试，其他 4xx 视为接收方拒绝，不再重试。
+type WebhookNotifier struct {
+	cfg    WebhookConfig
+	client *http.Client
+	queue  chan WebhookEvent
+	wg     sync.WaitGroup
+	// backoff 第 attempt 次重试前的等待时间，默认 webhookBackoff
+	backoff func(attempt int) time.Duration
+}
+
+// NewWebhookNotifier 创建 webhook 推送器，没有配置地址时返回 nil
+func NewWebhookNotifier(cfg WebhookConfig) *WebhookNotifier {
+	if len(cfg.URLs) == 0 {
+		return nil
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = webhookDefaultRetries
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = webhookDefaultTimeout
+	}
+	return &WebhookNotifier{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		queue:   make(chan WebhookEvent, webhookQueueSize),
+		backoff: webhookBackoff,
+	}
+}
+
+// Start 启动投递协程，ctx 取消后处理完队列中已有的事件再退出（不再重试）
+func (w *WebhookNotifier) Start(ctx context.Context) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		for {
+			select {
+			case event := <-w.queue:
+				w.deliver(ctx, event)
+			case <-ctx.Done():
+				for {
+					select {
+					case event := <-w.queue:
+						w.deliver(ctx, event)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Wait 等待投递协程退出
+func (w *WebhookNotifier) Wait() {
+	w.wg.Wait()
+}
+
+// Enqueue 把一批新通知加入推送队列，队列满时丢弃并记录日志
+func (w *WebhookNotifier) Enqueue(records []NotificationRecord) {
+	if len(records) == 0 {
+		return
+	}
+	event := WebhookEvent{
+		Event:         WebhookEventNewNotifications,
+		DeliveryID:    newDeliveryID(),
+		Timestamp:     time.Now().Unix(),
+		Notifications: records,
+	}
+	select {
+	case w.queue <- event:
+	default:
+		logrus.Warnf("webhook 队列已满，丢弃 %d 条新通知的推送（delivery=%s）", len(records), event.DeliveryID)
+	}
+}
+
+// deliver 把事件投递到所有地址
+func (w *WebhookNotifier) deliver(ctx context.Context, event WebhookEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		logrus.Errorf("序列化 webhook 事件失败: %v", err)
+		return
+	}
+	for _, url := range w.cfg.URLs {
+		if err := w.deliverWithRetry(ctx, url, event, body); err != nil {
+			logrus.Errorf("webhook 推送失败（url=%s delivery=%s, %d 条通知）: %v",
+				url, event.DeliveryID, len(event.Notifications), err)
+			continue
+		}
+		logrus.Infof("webhook 推送成功（url=%s delivery=%s, %d 条通知）", url, event.DeliveryID, len(event.Notifications))
+	}
+}
+
+func (w *WebhookNotifier) deliverWithRetry(ctx context.Context, url string, event WebhookEvent, body []byte) error {
+	var lastErr error
+	for attempt := 0; attempt <= w.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := w.backoff(attempt)
+			logrus.Warnf("webhook 第 %d 次重试将在 %s 后进行（url=%s）: %v", attempt, backoff, url, lastErr)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return fmt.Errorf("服务关闭，放弃重试: %w", lastErr)
+			}
+		}
+
+		retryable, err := w.post(url, event, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable {
+			return err
+		}
+	}
+	return fmt.Errorf("重试 %d 次后仍失败: %w", w.cfg.MaxRetries, lastErr)
+}
+
+// post 发送一次请求，返回失败时是否值得重试
+func (w *WebhookNotifier) post(url string, event WebhookEvent, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("创建请求失败: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "xiaohongshu-mcp-webhook")
+	req.Header.Set("X-Xhs-Event", event.Event)
+	req.Header.Set("X-Xhs-Delivery", event.DeliveryID)
+	req.Header.Set("X-Xhs-Timestamp", timestamp)
+	if w.cfg.Secret != "" {
+		req.Header.Set("X-Xhs-Signature", signWebhookPayload(w.cfg.Secret, timestamp, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("接收方返回 HTTP %d", resp.StatusCode)
+}
+
+// signWebhookPayload 计算签名：sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+func signWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff 第 attempt 次重试前的等待时间：1s、2s、4s…，最长 1 分钟
+func webhookBackoff(attempt int) time.Duration {
+	backoff := time.Second << (attempt - 1)
+	if backoff <= 0 || backoff > webhookMaxBackoff {
+		return webhookMaxBackoff
+	}
+	return backoff
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
+// parseWebhookURLs 解析逗号分隔的地址列表，忽略空项
+func parseWebhookURLs(raw string) []string {
+	var urls []string
+	for _, u := range strings.Split(raw, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
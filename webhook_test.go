@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// webhookRequest 测试服务端收到的一次请求
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newWebhookServer 启动测试接收方，第 n 次请求（从 1 开始）按 handle 返回，所有请求都记录下来
+func newWebhookServer(t *testing.T, handle func(n int32, w http.ResponseWriter, r *http.Request)) (*httptest.Server, func() []webhookRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []webhookRequest
+	var count atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, webhookRequest{header: r.Header.Clone(), body: body})
+		mu.Unlock()
+		handle(count.Add(1), w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []webhookRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]webhookRequest(nil), requests...)
+	}
+}
+
+// newTestWebhookNotifier 创建推送器，重试不等待，并记录每次重试的序号
+func newTestWebhookNotifier(cfg WebhookConfig) (*WebhookNotifier, *[]int) {
+	w := NewWebhookNotifier(cfg)
+	var attempts []int
+	w.backoff = func(attempt int) time.Duration {
+		attempts = append(attempts, attempt)
+		return time.Millisecond
+	}
+	return w, &attempts
+}
+
+func testWebhookEvent(ids ...string) (WebhookEvent, []byte) {
+	event := WebhookEvent{Event: WebhookEventNewNotifications, DeliveryID: "d1", Timestamp: 1700000000}
+	for _, id := range ids {
+		event.Notifications = append(event.Notifications, NotificationRecord{ID: id})
+	}
+	body, _ := json.Marshal(event)
+	return event, body
+}
+
+func TestWebhookSignatureHeader(t *testing.T) {
+	srv, requests := newWebhookServer(t, func(int32, http.ResponseWriter, *http.Request) {})
+	w, _ := newTestWebhookNotifier(WebhookConfig{URLs: []string{srv.URL}, Secret: "s3cret"})
+	event, body := testWebhookEvent("n1")
+
+	require.NoError(t, w.deliverWithRetry(context.Background(), srv.URL, event, body))
+
+	reqs := requests()
+	require.Len(t, reqs, 1)
+	h := reqs[0].header
+	require.Equal(t, WebhookEventNewNotifications, h.Get("X-Xhs-Event"))
+	require.Equal(t, "d1", h.Get("X-Xhs-Delivery"))
+	require.Equal(t, "application/json", h.Get("Content-Type"))
+	require.Equal(t, body, reqs[0].body)
+
+	// 接收方按文档自行计算签名
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(h.Get("X-Xhs-Timestamp") + "." + string(reqs[0].body)))
+	require.NotEmpty(t, h.Get("X-Xhs-Timestamp"))
+	require.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), h.Get("X-Xhs-Signature"))
+
+	// 没有密钥时不签名
+	w, _ = newTestWebhookNotifier(WebhookConfig{URLs: []string{srv.URL}})
+	require.NoError(t, w.deliverWithRetry(context.Background(), srv.URL, event, body))
+	reqs = requests()
+	require.Len(t, reqs, 2)
+	require.Empty(t, reqs[1].header.Get("X-Xhs-Signature"))
+}
+
+func TestWebhookRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       []int // 依次返回的状态码，之后返回 200；0 表示不响应直到超时
+		wantErr      bool
+		wantRequests int
+		wantRetries  []int
+	}{
+		{name: "5xx 重试后成功", status: []int{500, 502}, wantRequests: 3, wantRetries: []int{1, 2}},
+		{name: "429 重试", status: []int{429}, wantRequests: 2, wantRetries: []int{1}},
+		{name: "超时重试", status: []int{0}, wantRequests: 2, wantRetries: []int{1}},
+		{name: "4xx 不重试", status: []int{400}, wantErr: true, wantRequests: 1},
+		{name: "重试次数用完", status: []int{500, 500, 500, 500}, wantErr: true, wantRequests: 3, wantRetries: []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := newWebhookServer(t, func(n int32, w http.ResponseWriter, r *http.Request) {
+				if int(n) > len(tt.status) {
+					return
+				}
+				if code := tt.status[n-1]; code != 0 {
+					w.WriteHeader(code)
+					return
+				}
+				<-r.Context().Done()
+			})
+			w, retries := newTestWebhookNotifier(WebhookConfig{
+				URLs:       []string{srv.URL},
+				MaxRetries: 2,
+				Timeout:    100 * time.Millisecond,
+			})
+			event, body := testWebhookEvent("n1")
+
+			err := w.deliverWithRetry(context.Background(), srv.URL, event, body)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			reqs := requests()
+			require.Len(t, reqs, tt.wantRequests)
+			require.Equal(t, tt.wantRetries, *retries)
+			for _, r := range reqs {
+				require.Equal(t, "d1", r.header.Get("X-Xhs-Delivery"), "重试时投递 ID 不变")
+			}
+		})
+	}
+}
+
+func TestWebhookRetryStopsOnShutdown(t *testing.T) {
+	srv, requests := newWebhookServer(t, func(_ int32, w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	w := NewWebhookNotifier(WebhookConfig{URLs: []string{srv.URL}})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	event, body := testWebhookEvent("n1")
+
+	err := w.deliverWithRetry(ctx, srv.URL, event, body)
+	require.ErrorContains(t, err, "服务关闭")
+	require.Len(t, requests(), 1)
+}
+
+func TestWebhookFanOut(t *testing.T) {
+	ok1, requests1 := newWebhookServer(t, func(int32, http.ResponseWriter, *http.Request) {})
+	rejected, requestsRejected := newWebhookServer(t, func(_ int32, w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	flaky, requestsFlaky := newWebhookServer(t, func(n int32, w http.ResponseWriter, _ *http.Request) {
+		if n == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	w, _ := newTestWebhookNotifier(WebhookConfig{URLs: []string{ok1.URL, rejected.URL, flaky.URL}, Secret: "s"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.Start(ctx)
+	w.Enqueue([]NotificationRecord{{ID: "n1"}, {ID: "n2"}})
+	w.Enqueue(nil)
+	require.Eventually(t, func() bool { return len(requests1()) == 1 && len(requestsFlaky()) == 2 },
+		5*time.Second, 10*time.Millisecond)
+	cancel()
+	w.Wait()
+
+	// 一个地址拒绝不影响其他地址，每个地址收到同一个事件
+	require.Len(t, requestsRejected(), 1)
+	var deliveries []string
+	for _, r := range [][]webhookRequest{requests1(), requestsRejected(), requestsFlaky()} {
+		var event WebhookEvent
+		require.NoError(t, json.Unmarshal(r[0].body, &event))
+		require.Equal(t, WebhookEventNewNotifications, event.Event)
+		require.Len(t, event.Notifications, 2)
+		deliveries = append(deliveries, event.DeliveryID)
+	}
+	require.Equal(t, deliveries[0], deliveries[1])
+	require.Equal(t, deliveries[0], deliveries[2])
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	require.Equal(t, time.Second, webhookBackoff(1))
+	require.Equal(t, 2*time.Second, webhookBackoff(2))
+	require.Equal(t, 4*time.Second, webhookBackoff(3))
+	require.Equal(t, webhookMaxBackoff, webhookBackoff(7))
+	require.Equal(t, webhookMaxBackoff, webhookBackoff(100))
+}
+
+func TestNewWebhookNotifierWithoutURLs(t *testing.T) {
+	require.Nil(t, NewWebhookNotifier(WebhookConfig{}))
+	require.Equal(t, []string{"http://a", "http://b"}, parseWebhookURLs(" http://a, ,http://b,"))
+}
@@ -10,8 +10,65 @@ import (
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/errors"
 )
 
+// commentAPIItem 评论 API 中单条评论的原始结构（顶级评论与子评论共用）
+type commentAPIItem struct {
+	ID         string   `json:"id"`
+	NoteID     string   `json:"note_id"`
+	Content    string   `json:"content"`
+	LikeCount  string   `json:"like_count"`
+	CreateTime int64    `json:"create_time"`
+	IPLocation string   `json:"ip_location"`
+	Liked      bool     `json:"liked"`
+	ShowTags   []string `json:"show_tags"`
+	UserInfo   struct {
+		UserID   string `json:"user_id"`
+		Nickname string `json:"nickname"`
+		Image    string `json:"image"`
+	} `json:"user_info"`
+	// 被回复的评论（仅子评论有）
+	TargetComment *struct {
+		ID       string `json:"id"`
+		UserInfo struct {
+			UserID   string `json:"user_id"`
+			Nickname string `json:"nickname"`
+			Image    string `json:"image"`
+		} `json:"user_info"`
+	} `json:"target_comment,omitempty"`
+}
+
+// toComment 将 API 原始评论转换为与 __INITIAL_STATE__ 一致的 Comment 结构
+func (c commentAPIItem) toComment() Comment {
+	comment := Comment{
+		ID:         c.ID,
+		NoteID:     c.NoteID,
+		Content:    c.Content,
+		LikeCount:  c.LikeCount,
+		CreateTime: c.CreateTime,
+		IPLocation: c.IPLocation,
+		Liked:      c.Liked,
+		ShowTags:   c.ShowTags,
+		UserInfo: User{
+			UserID:   c.UserInfo.UserID,
+			Nickname: c.UserInfo.Nickname,
+			Avatar:   c.UserInfo.Image,
+		},
+	}
+	if c.TargetComment != nil {
+		comment.TargetComment = &TargetComment{
+			ID: c.TargetComment.ID,
+			UserInfo: User{
+				UserID:   c.TargetComment.UserInfo.UserID,
+				Nickname: c.TargetComment.UserInfo.Nickname,
+				Avatar:   c.TargetComment.UserInfo.Image,
+			},
+		}
+	}
+	return comment
+}
+
 // commentPageAPIResponse 小红书评论列表 API 的原始响应结构
 type commentPageAPIResponse struct {
 	Code    int    `json:"code"`
@@ -19,13 +76,11 @@ type commentPageAPIResponse struct {
 	Msg     string `json:"msg"`
 	Data    struct {
 		Comments []struct {
-			ID              string `json:"id"`
-			Content         string `json:"content"`
-			SubCommentCount string `json:"sub_comment_count"` // 子评论总数（字符串格式）
-			SubComments     []struct {
-				ID      string `json:"id"`
-				Content string `json:"content"`
-			} `json:"sub_comments"`
+			commentAPIItem
+			SubCommentCount   string           `json:"sub_comment_count"` // 子评论总数（字符串格式）
+			SubComments       []commentAPIItem `json:"sub_comments"`
+			SubCommentCursor  string           `json:"sub_comment_cursor"`   // 预加载子评论之后的游标
+			SubCommentHasMore bool             `json:"sub_comment_has_more"` // 是否还有未预加载的子评论
 		} `json:"comments"`
 		HasMore bool   `json:"has_more"`
 		Cursor  string `json:"cursor"`
@@ -192,6 +247,34 @@ func findParentCommentIDWithScroll(page *rod.Page, apiEntries *[]commentAPIEntry
 	return ""
 }
 
+// openFeedDetailPage 预热 SPA 后打开笔记详情页，等待评论区渲染并检查笔记是否可访问。
+// 小红书是 SPA，直接打开帖子 URL 时路由初始化不完整，评论区不会渲染，
+// 需要先访问主页让 SPA 完全初始化，再导航到帖子页面。
+// 需要拦截 API 的调用方必须在调用本函数之前注册 HijackRequests。
+func openFeedDetailPage(page *rod.Page, url string) error {
+	logrus.Info("预热：先访问小红书主页初始化 SPA...")
+	page.MustNavigate("https://www.xiaohongshu.com/")
+	page.MustWaitDOMStable()
+	time.Sleep(2 * time.Second)
+
+	logrus.Infof("导航到帖子详情页: %s", url)
+	page.MustNavigate(url)
+	page.MustWaitDOMStable()
+
+	// 等待评论区容器渲染（最多 15 秒）
+	logrus.Info("等待评论区渲染...")
+	for i := 0; i < 15; i++ {
+		time.Sleep(1 * time.Second)
+		result := page.MustEval(`() => document.querySelector('#noteContainer, .note-container, .note-scroller, .comments-container') ? 1 : 0`)
+		if result.Int() == 1 {
+			logrus.Infof("评论区已渲染（%ds）", i+1)
+			break
+		}
+	}
+
+	return checkPageAccessible(page)
+}
+
 // CommentFeedAction 表示 Feed 评论动作
 type CommentFeedAction struct {
 	page *rod.Page
@@ -202,83 +285,80 @@ func NewCommentFeedAction(page *rod.Page) *CommentFeedAction {
 	return &CommentFeedAction{page: page}
 }
 
-// PostComment 发表评论到 Feed
-func (f *CommentFeedAction) PostComment(ctx context.Context, feedID, xsecToken, content string) error {
+// PostComment 发表评论到 Feed，返回平台创建的评论。
+// content 中的表情代码（如 [笑哭R]）通过表情面板插入，@{user_id} 或 @{user_id:昵称} 通过选人面板插入为真正的 @提及；
+// imagePath 为可选的本地图片路径。平台拒绝评论时返回 *errors.CommentRejectedError。
+func (f *CommentFeedAction) PostComment(ctx context.Context, feedID, xsecToken, content, imagePath string) (*PostedComment, error) {
 	// 不使用 Context(ctx)，避免继承外部 context 的超时
 	page := f.page.Timeout(5 * time.Minute)
 
 	url := makeFeedDetailURL(feedID, xsecToken)
 	logrus.Infof("打开 feed 详情页: %s", url)
 
-	// 小红书是 SPA，直接打开帖子 URL 时路由初始化不完整
-	// 需要先访问主页让 SPA 完全初始化，再导航到帖子页面
-	logrus.Info("预热：先访问小红书主页初始化 SPA...")
-	page.MustNavigate("https://www.xiaohongshu.com/")
-	page.MustWaitDOMStable()
-	time.Sleep(2 * time.Second)
+	postResp := make(chan commentPostAPIResponse, 1)
+	var mentions *mentionCandidates
 
-	logrus.Infof("导航到帖子详情页: %s", url)
-	page.MustNavigate(url)
-	page.MustWaitDOMStable()
-
-	for i := 0; i < 15; i++ {
-		time.Sleep(1 * time.Second)
-		result := page.MustEval(`() => document.querySelector('#noteContainer, .note-container, .note-scroller, .comments-container') ? 1 : 0`)
-		if result.Int() == 1 {
-			logrus.Infof("页面内容已渲染（%ds）", i+1)
-			break
-		}
+	router := page.HijackRequests()
+	addCommentPostHijack(router, postResp)
+	if hasMention(content) {
+		mentions = &mentionCandidates{}
+		addMentionSearchHijack(router, mentions)
 	}
+	go router.Run()
+	defer router.Stop()
 
-	if err := checkPageAccessible(page); err != nil {
-		return err
+	if err := openFeedDetailPage(page, url); err != nil {
+		return nil, err
 	}
 
-	elem, err := page.Element("div.input-box div.content-edit span")
+	elem, err := page.Timeout(30 * time.Second).Element("div.input-box div.content-edit span")
 	if err != nil {
 		logrus.Warnf("Failed to find comment input box: %v", err)
-		return fmt.Errorf("未找到评论输入框，该帖子可能不支持评论或网页端不可访问: %w", err)
+		if msg, closed := detectCommentsClosed(page); closed {
+			return nil, errors.NewCommentRejectedError(0, msg)
+		}
+		return nil, fmt.Errorf("未找到评论输入框，该帖子可能不支持评论或网页端不可访问: %w", err)
 	}
+	elem = elem.CancelTimeout()
 
 	if err := elem.Click(proto.InputMouseButtonLeft, 1); err != nil {
 		logrus.Warnf("Failed to click comment input box: %v", err)
-		return fmt.Errorf("无法点击评论输入框: %w", err)
+		return nil, fmt.Errorf("无法点击评论输入框: %w", err)
 	}
 
 	elem2, err := page.Element("div.input-box div.content-edit p.content-input")
 	if err != nil {
 		logrus.Warnf("Failed to find comment input field: %v", err)
-		return fmt.Errorf("未找到评论输入区域: %w", err)
+		return nil, fmt.Errorf("未找到评论输入区域: %w", err)
 	}
 
-	if err := elem2.Input(content); err != nil {
+	if err := fillCommentInput(page, elem2, content, mentions); err != nil {
 		logrus.Warnf("Failed to input comment content: %v", err)
-		return fmt.Errorf("无法输入评论内容: %w", err)
+		return nil, fmt.Errorf("无法输入评论内容: %w", err)
+	}
+
+	if imagePath != "" {
+		if err := attachCommentImage(page, imagePath); err != nil {
+			return nil, err
+		}
 	}
 
 	time.Sleep(1 * time.Second)
 
-	submitButton, err := page.Element("div.bottom button.submit")
+	posted, err := submitComment(page, postResp)
 	if err != nil {
-		logrus.Warnf("Failed to find submit button: %v", err)
-		return fmt.Errorf("未找到提交按钮: %w", err)
-	}
-
-	if err := submitButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
-		logrus.Warnf("Failed to click submit button: %v", err)
-		return fmt.Errorf("无法点击提交按钮: %w", err)
+		return nil, err
 	}
 
-	time.Sleep(1 * time.Second)
-
-	logrus.Infof("Comment posted successfully to feed: %s", feedID)
-	return nil
+	logrus.Infof("Comment posted to feed: %s, comment id: %s", feedID, posted.ID)
+	return posted, nil
 }
 
-// ReplyToComment 回复指定评论
+// ReplyToComment 回复指定评论，返回平台创建的回复。
 // parentCommentID 为可选参数：当目标评论是子评论时，传入父评论 ID，
 // 浏览器会先找到并展开父评论的"查看回复"列表，再定位目标子评论。
-func (f *CommentFeedAction) ReplyToComment(ctx context.Context, feedID, xsecToken, commentID, userID, parentCommentID, content string) error {
+// content 与 imagePath 的处理、拒绝时的错误同 PostComment。
+func (f *CommentFeedAction) ReplyToComment(ctx context.Context, feedID, xsecToken, commentID, userID, parentCommentID, content, imagePath string) (*PostedComment, error) {
 	// 注意：不使用 Context(ctx)，避免继承外部 context 的超时
 	page := f.page.Timeout(5 * time.Minute)
 	url := makeFeedDetailURL(feedID, xsecToken)
@@ -288,67 +368,111 @@ func (f *CommentFeedAction) ReplyToComment(ctx context.Context, feedID, xsecToke
 	// HijackRequests 必须在页面导航前注册，才能捕获页面加载时发出的 API 请求。
 	var commentAPIEntries []commentAPIEntry
 	var commentAPIMu sync.Mutex
-	var commentAPIWorked bool
+	var mentions *mentionCandidates
+	postResp := make(chan commentPostAPIResponse, 1)
 
+	router := page.HijackRequests()
+	addCommentPostHijack(router, postResp)
 	if commentID != "" {
-		router := page.HijackRequests()
-		go router.Run()
-		defer router.Stop()
-
-		router.MustAdd("*/api/sns/web/v2/comment/page*", func(ctx *rod.Hijack) {
-			ctx.MustLoadResponse()
-			body := ctx.Response.Body()
-			if body == "" {
-				return
-			}
-			var resp commentPageAPIResponse
-			if err := json.Unmarshal([]byte(body), &resp); err != nil || !resp.Success {
-				return
-			}
-			commentAPIMu.Lock()
-			commentAPIEntries = append(commentAPIEntries, commentAPIEntry{
-				body:    body,
-				hasMore: resp.Data.HasMore,
-			})
-			commentAPIMu.Unlock()
-			logrus.Infof("API预检：捕获到评论API响应（%d条评论，has_more=%v）",
-				len(resp.Data.Comments), resp.Data.HasMore)
-		})
-		logrus.Info("API预检：已注册评论API拦截器")
+		addCommentPageHijack(router, &commentAPIEntries, &commentAPIMu)
 	}
-
-	// 小红书是 SPA，直接打开帖子 URL 时路由初始化不完整，评论区不会渲染
-	// 需要先访问主页让 SPA 完全初始化，再导航到帖子页面
-	logrus.Info("预热：先访问小红书主页初始化 SPA...")
-	page.MustNavigate("https://www.xiaohongshu.com/")
-	page.MustWaitDOMStable()
-	time.Sleep(2 * time.Second)
-
-	// 再导航到帖子详情页（此时拦截器已就绪，会自动捕获评论API请求）
-	logrus.Infof("导航到帖子详情页: %s", url)
-	page.MustNavigate(url)
-	page.MustWaitDOMStable()
-
-	// 等待评论区容器渲染（最多 15 秒）
-	logrus.Info("等待评论区渲染...")
-	for i := 0; i < 15; i++ {
-		time.Sleep(1 * time.Second)
-		result := page.MustEval(`() => document.querySelector('#noteContainer, .note-container, .note-scroller, .comments-container') ? 1 : 0`)
-		if result.Int() == 1 {
-			logrus.Infof("评论区已渲染（%ds）", i+1)
-			break
-		}
+	if hasMention(content) {
+		mentions = &mentionCandidates{}
+		addMentionSearchHijack(router, mentions)
 	}
+	go router.Run()
+	defer router.Stop()
 
-	// 检测页面是否可访问
-	if err := checkPageAccessible(page); err != nil {
-		return err
+	// 导航到帖子详情页（此时拦截器已就绪，会自动捕获评论API请求）
+	if err := openFeedDetailPage(page, url); err != nil {
+		return nil, err
 	}
 
 	// 方案A+B：利用已拦截的 API 数据 + DOM 滚动联合查找评论。
 	// API 拦截器在导航前已注册，页面加载时会自动捕获评论 API 响应。
 	// findCommentElementWithAPICheck 会在每次滚动后同步检查 API 数据，
 	// 一旦 API 返回 has_more=false 且未找到目标评论，立即终止，无需滚到 DOM 底部。
+	commentEl, err := locateComment(page, commentID, userID, parentCommentID, &commentAPIEntries, &commentAPIMu)
+	if err != nil {
+		return nil, fmt.Errorf("无法找到评论: %w", err)
+	}
+
+	// 滚动到评论位置
+	logrus.Info("滚动到评论位置...")
+	commentEl.MustScrollIntoView()
+	time.Sleep(1 * time.Second)
+
+	logrus.Info("准备点击回复按钮")
+
+	// 查找并点击回复按钮
+	replyBtn, err := commentEl.Element(".right .interactions .reply")
+	if err != nil {
+		return nil, fmt.Errorf("无法找到回复按钮: %w", err)
+	}
+
+	if err := replyBtn.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return nil, fmt.Errorf("点击回复按钮失败: %w", err)
+	}
+
+	time.Sleep(1 * time.Second)
+
+	// 查找回复输入框
+	inputEl, err := page.Element("div.input-box div.content-edit p.content-input")
+	if err != nil {
+		return nil, fmt.Errorf("无法找到回复输入框: %w", err)
+	}
+
+	// 输入内容
+	if err := fillCommentInput(page, inputEl, content, mentions); err != nil {
+		return nil, fmt.Errorf("输入回复内容失败: %w", err)
+	}
+
+	if imagePath != "" {
+		if err := attachCommentImage(page, imagePath); err != nil {
+			return nil, err
+		}
+	}
+
+	time.Sleep(500 * time.Millisecond)
+
+	posted, err := submitComment(page, postResp)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("回复评论成功, reply id: %s", posted.ID)
+	return posted, nil
+}
+
+// addCommentPageHijack 在 router 上拦截评论列表 API（/api/sns/web/v2/comment/page），响应追加到 apiEntries。
+// router 必须在页面导航前开始运行，才能捕获页面加载时发出的请求。
+func addCommentPageHijack(router *rod.HijackRouter, apiEntries *[]commentAPIEntry, apiMu *sync.Mutex) {
+	router.MustAdd("*/api/sns/web/v2/comment/page*", func(ctx *rod.Hijack) {
+		ctx.MustLoadResponse()
+		body := ctx.Response.Body()
+		if body == "" {
+			return
+		}
+		var resp commentPageAPIResponse
+		if err := json.Unmarshal([]byte(body), &resp); err != nil || !resp.Success {
+			return
+		}
+		apiMu.Lock()
+		*apiEntries = append(*apiEntries, commentAPIEntry{
+			body:    body,
+			hasMore: resp.Data.HasMore,
+		})
+		apiMu.Unlock()
+		logrus.Infof("API预检：捕获到评论API响应（%d条评论，has_more=%v）",
+			len(resp.Data.Comments), resp.Data.HasMore)
+	})
+	logrus.Info("API预检：已注册评论API拦截器")
+}
+
+// locateComment 在已打开的详情页中定位目标评论（顶级评论或楼中楼子评论）。
+// parentCommentID 为可选参数：当目标评论是子评论时传入父评论 ID；
+// 传入的父评论 ID 不准确或缺失时，会依次利用 API 数据反查真正的父评论、逐一展开子评论查找。
+func locateComment(page *rod.Page, commentID, userID, parentCommentID string, apiEntries *[]commentAPIEntry, apiMu *sync.Mutex) (*rod.Element, error) {
 	var commentEl *rod.Element
 	var err error
 	if parentCommentID != "" {
@@ -359,35 +483,35 @@ func (f *CommentFeedAction) ReplyToComment(ctx context.Context, feedID, xsecToke
 		// 因此这里先尝试直接用 parentCommentID 走子评论路径，
 		// 若失败则检查 parentCommentID 本身是否是子评论，并反查其真正的顶级父评论。
 		logrus.Infof("目标是子评论，先找父评论 %s，然后展开子评论列表", parentCommentID)
-		commentEl, err = findSubComment(page, parentCommentID, commentID, userID, &commentAPIEntries, &commentAPIMu)
+		commentEl, err = findSubComment(page, parentCommentID, commentID, userID, apiEntries, apiMu)
 		if err != nil {
 			// 容错1：parentCommentID 找不到，可能它本身是子评论（来自通知 API 的 target_comment_id）。
 			// 小红书评论只有两层，尝试把 parentCommentID 当子评论 ID，从 API 数据中反查其顶级父评论。
 			// 使用带滚动的版本，确保 API 数据不足时能加载更多再重试。
 			logrus.Warnf("父评论 %s 未找到，尝试将其作为子评论 ID 反查顶级父评论（通知 API 的 target_comment_id 可能是子评论）", parentCommentID)
-			if trueParentID := findParentCommentIDWithScroll(page, &commentAPIEntries, &commentAPIMu, parentCommentID); trueParentID != "" {
+			if trueParentID := findParentCommentIDWithScroll(page, apiEntries, apiMu, parentCommentID); trueParentID != "" {
 				logrus.Infof("从API数据中发现 %s 是 %s 的子评论，使用真正的顶级父评论重试", parentCommentID, trueParentID)
-				commentEl, err = findSubComment(page, trueParentID, commentID, userID, &commentAPIEntries, &commentAPIMu)
+				commentEl, err = findSubComment(page, trueParentID, commentID, userID, apiEntries, apiMu)
 			}
 		}
 		if err != nil {
 			// 容错2：父评论路径全部失败。
 			// 先尝试从 API 数据中反查 commentID 的真正父评论（commentID 本身可能是子评论）。
 			logrus.Warnf("父评论路径全部失败，尝试从 API 数据中反查 %s 的真正父评论", commentID)
-			if foundParentID := findParentCommentIDWithScroll(page, &commentAPIEntries, &commentAPIMu, commentID); foundParentID != "" {
+			if foundParentID := findParentCommentIDWithScroll(page, apiEntries, apiMu, commentID); foundParentID != "" {
 				logrus.Infof("从API数据中发现 %s 是 %s 的子评论，切换到子评论查找路径", commentID, foundParentID)
-				commentEl, err = findSubComment(page, foundParentID, commentID, userID, &commentAPIEntries, &commentAPIMu)
+				commentEl, err = findSubComment(page, foundParentID, commentID, userID, apiEntries, apiMu)
 			}
 		}
 		if err != nil {
 			// 容错3：预加载子评论数据不完整，commentID 可能在某个顶级评论的深层子评论里。
 			// 遍历所有有子评论的顶级评论，逐一展开尝试找到目标。
 			logrus.Warnf("反查失败，遍历所有有子评论的顶级评论，逐一展开查找 %s", commentID)
-			topLevelIDs := getTopLevelCommentsWithSubComments(&commentAPIEntries, &commentAPIMu)
+			topLevelIDs := getTopLevelCommentsWithSubComments(apiEntries, apiMu)
 			logrus.Infof("找到 %d 个有子评论的顶级评论，逐一展开", len(topLevelIDs))
 			for _, topID := range topLevelIDs {
 				logrus.Infof("尝试展开顶级评论 %s 查找子评论 %s", topID, commentID)
-				commentEl, err = findSubComment(page, topID, commentID, userID, &commentAPIEntries, &commentAPIMu)
+				commentEl, err = findSubComment(page, topID, commentID, userID, apiEntries, apiMu)
 				if err == nil {
 					logrus.Infof("✓ 在顶级评论 %s 下找到目标子评论 %s", topID, commentID)
 					break
@@ -398,27 +522,27 @@ func (f *CommentFeedAction) ReplyToComment(ctx context.Context, feedID, xsecToke
 			// 容错4：所有子评论路径均失败，最后降级为直接查找 commentID 作为顶级评论处理。
 			// 此时回复会出现在评论区顶层，但至少能成功回复对方。
 			logrus.Warnf("所有子评论路径失败，最终降级：直接查找目标评论 %s 作为顶级评论处理", commentID)
-			commentEl, err = findCommentElementWithAPICheck(page, commentID, userID, &commentAPIEntries, &commentAPIMu)
+			commentEl, err = findCommentElementWithAPICheck(page, commentID, userID, apiEntries, apiMu)
 		}
 	} else {
-		commentEl, err = findCommentElementWithAPICheck(page, commentID, userID, &commentAPIEntries, &commentAPIMu)
+		commentEl, err = findCommentElementWithAPICheck(page, commentID, userID, apiEntries, apiMu)
 		if err != nil && commentID != "" {
 			// 容错1：顶级评论中没找到，可能是子评论但调用方未传 parentCommentID。
 			// 尝试从 API 数据中查找 commentID 所在的父评论，然后走子评论路径。
 			logrus.Warnf("顶级评论未找到 %s，尝试在API数据中搜索其父评论（调用方可能遗漏了 parent_comment_id）", commentID)
-			if foundParentID := findParentCommentIDWithScroll(page, &commentAPIEntries, &commentAPIMu, commentID); foundParentID != "" {
+			if foundParentID := findParentCommentIDWithScroll(page, apiEntries, apiMu, commentID); foundParentID != "" {
 				logrus.Infof("从API数据中发现 %s 是 %s 的子评论，切换到子评论查找路径", commentID, foundParentID)
-				commentEl, err = findSubComment(page, foundParentID, commentID, userID, &commentAPIEntries, &commentAPIMu)
+				commentEl, err = findSubComment(page, foundParentID, commentID, userID, apiEntries, apiMu)
 			}
 		}
 		if err != nil && commentID != "" {
 			// 容错2：预加载子评论数据不完整，遍历所有有子评论的顶级评论逐一展开。
 			logrus.Warnf("反查失败，遍历所有有子评论的顶级评论，逐一展开查找 %s", commentID)
-			topLevelIDs := getTopLevelCommentsWithSubComments(&commentAPIEntries, &commentAPIMu)
+			topLevelIDs := getTopLevelCommentsWithSubComments(apiEntries, apiMu)
 			logrus.Infof("找到 %d 个有子评论的顶级评论，逐一展开", len(topLevelIDs))
 			for _, topID := range topLevelIDs {
 				logrus.Infof("尝试展开顶级评论 %s 查找子评论 %s", topID, commentID)
-				commentEl, err = findSubComment(page, topID, commentID, userID, &commentAPIEntries, &commentAPIMu)
+				commentEl, err = findSubComment(page, topID, commentID, userID, apiEntries, apiMu)
 				if err == nil {
 					logrus.Infof("✓ 在顶级评论 %s 下找到目标子评论 %s", topID, commentID)
 					break
@@ -426,56 +550,7 @@ func (f *CommentFeedAction) ReplyToComment(ctx context.Context, feedID, xsecToke
 			}
 		}
 	}
-	if err != nil {
-		return fmt.Errorf("无法找到评论: %w", err)
-	}
-	_ = commentAPIWorked
-
-	// 滚动到评论位置
-	logrus.Info("滚动到评论位置...")
-	commentEl.MustScrollIntoView()
-	time.Sleep(1 * time.Second)
-
-	logrus.Info("准备点击回复按钮")
-
-	// 查找并点击回复按钮
-	replyBtn, err := commentEl.Element(".right .interactions .reply")
-	if err != nil {
-		return fmt.Errorf("无法找到回复按钮: %w", err)
-	}
-
-	if err := replyBtn.Click(proto.InputMouseButtonLeft, 1); err != nil {
-		return fmt.Errorf("点击回复按钮失败: %w", err)
-	}
-
-	time.Sleep(1 * time.Second)
-
-	// 查找回复输入框
-	inputEl, err := page.Element("div.input-box div.content-edit p.content-input")
-	if err != nil {
-		return fmt.Errorf("无法找到回复输入框: %w", err)
-	}
-
-	// 输入内容
-	if err := inputEl.Input(content); err != nil {
-		return fmt.Errorf("输入回复内容失败: %w", err)
-	}
-
-	time.Sleep(500 * time.Millisecond)
-
-	// 查找并点击提交按钮
-	submitBtn, err := page.Element("div.bottom button.submit")
-	if err != nil {
-		return fmt.Errorf("无法找到提交按钮: %w", err)
-	}
-
-	if err := submitBtn.Click(proto.InputMouseButtonLeft, 1); err != nil {
-		return fmt.Errorf("点击提交按钮失败: %w", err)
-	}
-
-	time.Sleep(2 * time.Second)
-	logrus.Infof("回复评论成功")
-	return nil
+	return commentEl, err
 }
 
 // findCommentElementWithAPICheck 查找指定评论元素，同时利用 API 拦截数据加速判断。
@@ -533,7 +608,7 @@ func findCommentElementWithAPICheck(page *rod.Page, commentID, userID string, ap
 				lastHasMore := initialEntries[len(initialEntries)-1].hasMore
 				if !lastHasMore {
 					logrus.Infof("API确认：评论API已无更多页且无未展开子评论，commentID=%s 不存在（已删除）", commentID)
-					return nil, fmt.Errorf("评论不存在（已被删除或不可见）: commentID=%s", commentID)
+					return nil, fmt.Errorf("%w: commentID=%s", errors.ErrCommentNotFound, commentID)
 				}
 				logrus.Infof("预检API数据未找到，评论可能在后续页，进入滚动查找（最多 %d 轮）", maxScrollRounds)
 			}
@@ -589,7 +664,7 @@ func findCommentElementWithAPICheck(page *rod.Page, commentID, userID string, ap
 					lastHasMore := currentEntries[len(currentEntries)-1].hasMore
 					if !lastHasMore {
 						logrus.Infof("API确认：所有评论页已加载完毕且无未展开子评论，commentID=%s 不存在（已删除）", commentID)
-						return nil, fmt.Errorf("评论不存在（已被删除或不可见）: commentID=%s", commentID)
+						return nil, fmt.Errorf("%w: commentID=%s", errors.ErrCommentNotFound, commentID)
 					}
 				}
 			}
@@ -766,5 +841,5 @@ func findSubComment(page *rod.Page, parentCommentID, commentID, userID string, a
 
 	// 确认评论不存在
 	logrus.Warnf("在父评论 %s 下未找到子评论 %s（已展开全部回复）", parentCommentID, commentID)
-	return parentEl, fmt.Errorf("子评论不存在（已被删除或不可见）: commentID=%s", commentID)
+	return parentEl, fmt.Errorf("%w: 子评论 commentID=%s", errors.ErrCommentNotFound, commentID)
 }
@@ -0,0 +1,198 @@
+package xiaohongshu
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+)
+
+// commentTokenRegex 评论内容中的特殊片段：
+//   - 平台表情代码，形如 [笑哭R]、[doge]。只匹配不含空白和括号的短代码，避免把普通的方括号文字当成表情；
+//   - @提及，形如 @{user_id} 或 @{user_id:昵称}。昵称用于在 @ 选人面板中搜索，不在面板默认列表中的用户需要提供。
+var commentTokenRegex = regexp.MustCompile(`\[[^\[\]\s]{1,8}\]|@\{([0-9a-zA-Z]+)(?::([^{}]+))?\}`)
+
+// commentSegmentKind 评论内容片段类型
+type commentSegmentKind int
+
+const (
+	segmentText commentSegmentKind = iota
+	segmentEmoji
+	segmentMention
+)
+
+// commentSegment 评论内容片段。表情片段的 Text 是表情代码，提及片段的 Text 是原始写法
+type commentSegment struct {
+	Kind     commentSegmentKind
+	Text     string
+	UserID   string // 仅提及片段
+	Nickname string // 仅提及片段，可能为空
+}
+
+// splitCommentContent 把评论内容拆成普通文字、表情代码和 @提及片段，保持原有顺序
+func splitCommentContent(content string) []commentSegment {
+	var segments []commentSegment
+	last := 0
+	for _, loc := range commentTokenRegex.FindAllStringSubmatchIndex(content, -1) {
+		if loc[0] > last {
+			segments = append(segments, commentSegment{Kind: segmentText, Text: content[last:loc[0]]})
+		}
+		seg := commentSegment{Kind: segmentEmoji, Text: content[loc[0]:loc[1]]}
+		if loc[2] >= 0 {
+			seg.Kind = segmentMention
+			seg.UserID = content[loc[2]:loc[3]]
+			if loc[4] >= 0 {
+				seg.Nickname = strings.TrimSpace(content[loc[4]:loc[5]])
+			}
+		}
+		segments = append(segments, seg)
+		last = loc[1]
+	}
+	if last < len(content) {
+		segments = append(segments, commentSegment{Kind: segmentText, Text: content[last:]})
+	}
+	return segments
+}
+
+// hasMention 判断评论内容是否包含 @提及
+func hasMention(content string) bool {
+	for _, seg := range splitCommentContent(content) {
+		if seg.Kind == segmentMention {
+			return true
+		}
+	}
+	return false
+}
+
+// fillCommentInput 向评论输入框输入内容。
+// 表情代码通过表情面板插入，使其显示为表情而不是文字，表情面板中找不到的代码按原文输入；
+// @提及通过 @ 选人面板插入，mentions 为选人面板搜索接口的拦截结果，提及无法确认时返回 ErrMentionNotResolved。
+func fillCommentInput(page *rod.Page, inputEl *rod.Element, content string, mentions *mentionCandidates) error {
+	for _, seg := range splitCommentContent(content) {
+		switch seg.Kind {
+		case segmentEmoji:
+			err := insertEmoji(page, inputEl, seg.Text)
+			if err == nil {
+				continue
+			}
+			logrus.Warnf("插入表情 %s 失败，按文字输入: %v", seg.Text, err)
+		case segmentMention:
+			if err := insertMention(page, inputEl, seg.UserID, seg.Nickname, mentions); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := inputEl.Input(seg.Text); err != nil {
+			return fmt.Errorf("输入评论内容失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// emojiPanelSelector 表情面板容器，不同版本的类名不一致
+const emojiPanelSelector = `[class*="emoji-panel"], [class*="emoji-list"], [class*="emoji-container"], [class*="emojis"]`
+
+// insertEmoji 打开评论框的表情面板，点击名称与 code 一致的表情
+func insertEmoji(page *rod.Page, inputEl *rod.Element, code string) error {
+	trigger, err := page.Timeout(3 * time.Second).Element("div.input-box .emoji, div.bottom .emoji, div.input-box [class*='emoji-btn']")
+	if err != nil {
+		return fmt.Errorf("未找到表情按钮: %w", err)
+	}
+	trigger = trigger.CancelTimeout()
+	if err := trigger.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return fmt.Errorf("打开表情面板失败: %w", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+
+	// 表情面板中的表情通常是 img，名称在 alt/title/data-* 属性上，带不带方括号的写法都有
+	clicked, err := page.Eval(`(code, emojiPanelSelector) => {
+		const bare = code.slice(1, -1);
+		const names = [code, bare];
+		const panels = document.querySelectorAll(emojiPanelSelector);
+		for (const panel of panels) {
+			for (const el of panel.querySelectorAll('img, span, div')) {
+				const attrs = [el.getAttribute('alt'), el.getAttribute('title'), el.getAttribute('data-name'), el.getAttribute('data-emoji')];
+				if (attrs.some(a => a && names.includes(a.trim()))) {
+					el.click();
+					return true;
+				}
+			}
+		}
+		return false;
+	}`, code, emojiPanelSelector)
+	if err != nil {
+		return fmt.Errorf("查找表情失败: %w", err)
+	}
+
+	// 再次点击表情按钮关闭面板（Esc 可能连评论框或笔记浮层一起关掉），并让光标回到输入框末尾，后续文字接在表情后面
+	if err := closeEmojiPanel(page, trigger); err != nil {
+		logrus.Warnf("关闭表情面板失败: %v", err)
+	}
+	if err := inputEl.Focus(); err != nil {
+		logrus.Warnf("表情插入后聚焦输入框失败: %v", err)
+	}
+	if _, err := inputEl.Eval(`function() {
+		const range = document.createRange();
+		range.selectNodeContents(this);
+		range.collapse(false);
+		const sel = window.getSelection();
+		sel.removeAllRanges();
+		sel.addRange(range);
+	}`); err != nil {
+		logrus.Warnf("移动光标失败: %v", err)
+	}
+
+	if !clicked.Value.Bool() {
+		return fmt.Errorf("表情面板中没有 %s", code)
+	}
+	time.Sleep(300 * time.Millisecond)
+	return nil
+}
+
+// closeEmojiPanel 表情面板仍可见时再次点击表情按钮将其收起，选中表情后面板已自动收起时不做操作
+func closeEmojiPanel(page *rod.Page, trigger *rod.Element) error {
+	open, err := page.Eval(`(selector) => Array.from(document.querySelectorAll(selector)).some(el => el.offsetParent !== null)`, emojiPanelSelector)
+	if err != nil {
+		return fmt.Errorf("检查表情面板失败: %w", err)
+	}
+	if !open.Value.Bool() {
+		return nil
+	}
+	if err := trigger.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return fmt.Errorf("点击表情按钮失败: %w", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	return nil
+}
+
+// attachCommentImage 为评论添加一张图片，imagePath 为本地文件路径
+func attachCommentImage(page *rod.Page, imagePath string) error {
+	if _, err := os.Stat(imagePath); err != nil {
+		return fmt.Errorf("评论图片不存在: %s: %w", imagePath, err)
+	}
+
+	fileInput, err := page.Timeout(5 * time.Second).Element(`div.input-box input[type="file"], div.bottom input[type="file"], .comment-image-upload input[type="file"]`)
+	if err != nil {
+		return fmt.Errorf("未找到评论图片上传入口，该笔记可能不支持图片评论: %w", err)
+	}
+	if err := fileInput.CancelTimeout().SetFiles([]string{imagePath}); err != nil {
+		return fmt.Errorf("上传评论图片失败: %w", err)
+	}
+
+	// 等待输入框出现图片预览，上传完成后提交按钮才会带上图片
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		result, err := page.Eval(`() => !!document.querySelector('div.input-box img[class*="image"], div.input-box [class*="image-item"] img, div.input-box [class*="preview"] img')`)
+		if err == nil && result.Value.Bool() {
+			logrus.Infof("评论图片上传完成: %s", imagePath)
+			return nil
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return fmt.Errorf("等待评论图片上传超时: %s", imagePath)
+}
@@ -0,0 +1,105 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCommentContent(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []commentSegment
+	}{
+		{
+			name: "文字与表情混排",
+			in:   "太好看了[笑哭R]下次还来[赞R]",
+			want: []commentSegment{
+				{Kind: segmentText, Text: "太好看了"},
+				{Kind: segmentEmoji, Text: "[笑哭R]"},
+				{Kind: segmentText, Text: "下次还来"},
+				{Kind: segmentEmoji, Text: "[赞R]"},
+			},
+		},
+		{
+			name: "连续表情",
+			in:   "[doge][派对R]",
+			want: []commentSegment{
+				{Kind: segmentEmoji, Text: "[doge]"},
+				{Kind: segmentEmoji, Text: "[派对R]"},
+			},
+		},
+		{
+			name: "含空白的方括号不是表情",
+			in:   "见 [第 2 张图]",
+			want: []commentSegment{{Kind: segmentText, Text: "见 [第 2 张图]"}},
+		},
+		{
+			name: "@提及",
+			in:   "@{5f1e2d3c4b5a}快来看@{6a7b8c: 小红 }[赞R]",
+			want: []commentSegment{
+				{Kind: segmentMention, Text: "@{5f1e2d3c4b5a}", UserID: "5f1e2d3c4b5a"},
+				{Kind: segmentText, Text: "快来看"},
+				{Kind: segmentMention, Text: "@{6a7b8c: 小红 }", UserID: "6a7b8c", Nickname: "小红"},
+				{Kind: segmentEmoji, Text: "[赞R]"},
+			},
+		},
+		{
+			name: "普通 @ 文字不是提及",
+			in:   "@小红 你好",
+			want: []commentSegment{{Kind: segmentText, Text: "@小红 你好"}},
+		},
+		{name: "空内容", in: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitCommentContent(tt.in))
+		})
+	}
+}
+
+func TestHasMention(t *testing.T) {
+	assert.True(t, hasMention("你好 @{5f1e2d3c4b5a}"))
+	assert.False(t, hasMention("你好 @小红 [赞R]"))
+}
+
+func TestParseMentionSearchResponse(t *testing.T) {
+	users, err := parseMentionSearchResponse(`{"success":true,"data":{"users":[
+		{"id":"u1","nickname":"小红"},
+		{"user_id":"u2","name":"小蓝"},
+		{"nickname":"没有ID"}
+	]}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []mentionUser{
+		{UserID: "u1", Nickname: "小红"},
+		{UserID: "u2", Nickname: "小蓝"},
+	}, users)
+
+	_, err = parseMentionSearchResponse(`{"success":false}`)
+	assert.Error(t, err)
+}
+
+func TestMentionCandidatesFind(t *testing.T) {
+	var c mentionCandidates
+	c.set(false, []mentionUser{{UserID: "u1", Nickname: "小红"}, {UserID: "u2", Nickname: "小蓝"}})
+	c.set(true, []mentionUser{{UserID: "u3", Nickname: "陌生人"}, {UserID: "u2", Nickname: "小蓝"}})
+
+	user, index, found := c.find("u3")
+	assert.True(t, found, "最近联系人以外的用户应能从搜索结果中找到")
+	assert.Equal(t, "陌生人", user.Nickname)
+	assert.Equal(t, 0, index)
+
+	_, index, _ = c.find("u2")
+	assert.Equal(t, 1, index, "搜索结果优先，位置取搜索结果中的位置")
+
+	_, index, found = c.find("u1")
+	assert.True(t, found)
+	assert.Equal(t, 0, index)
+
+	c.reset()
+	_, _, found = c.find("u1")
+	assert.False(t, found)
+}
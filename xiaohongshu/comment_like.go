@@ -0,0 +1,146 @@
+package xiaohongshu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+)
+
+// SelectorCommentLike 评论的点赞按钮（相对评论元素）
+const SelectorCommentLike = ".right .interactions .like .like-wrapper"
+
+// LikeComment 点赞或取消点赞指定评论（顶级评论或楼中楼回复），已是目标状态时直接返回。
+// parentCommentID 为可选参数，操作楼中楼回复时传入可加快定位。
+// 当前状态优先取评论 API 数据中的 liked 字段，取不到时读取点赞按钮的样式；
+// 点击后以点赞接口的响应确认结果，未捕获到响应时再读一次按钮样式。
+func (f *CommentFeedAction) LikeComment(ctx context.Context, feedID, xsecToken, commentID, parentCommentID string, targetLiked bool) error {
+	actionType := actionLike
+	if !targetLiked {
+		actionType = actionUnlike
+	}
+
+	// 不使用 Context(ctx)，避免继承外部 context 的超时
+	page := f.page.Timeout(5 * time.Minute)
+	url := makeFeedDetailURL(feedID, xsecToken)
+	logrus.Infof("打开 feed 详情页%s评论: %s, commentID=%s", actionType, url, commentID)
+
+	var commentAPIEntries []commentAPIEntry
+	var commentAPIMu sync.Mutex
+	likeResp := make(chan commentActionAPIResponse, 1)
+
+	router := page.HijackRequests()
+	addCommentPageHijack(router, &commentAPIEntries, &commentAPIMu)
+	for _, pattern := range []string{"*/api/sns/web/v1/comment/like*", "*/api/sns/web/v1/comment/dislike*"} {
+		router.MustAdd(pattern, func(ctx *rod.Hijack) {
+			ctx.MustLoadResponse()
+			var resp commentActionAPIResponse
+			if err := json.Unmarshal([]byte(ctx.Response.Body()), &resp); err != nil {
+				logrus.Warnf("评论%s：解析接口响应失败: %v", actionType, err)
+				return
+			}
+			select {
+			case likeResp <- resp:
+			default:
+			}
+		})
+	}
+	go router.Run()
+	defer router.Stop()
+
+	if err := openFeedDetailPage(page, url); err != nil {
+		return err
+	}
+
+	commentEl, err := locateComment(page, commentID, "", parentCommentID, &commentAPIEntries, &commentAPIMu)
+	if err != nil {
+		return fmt.Errorf("无法找到评论: %w", err)
+	}
+
+	commentAPIMu.Lock()
+	item, found := findCommentInEntries(commentAPIEntries, commentID)
+	commentAPIMu.Unlock()
+
+	liked := item.Liked
+	if !found {
+		if liked, err = isCommentLikeActive(commentEl); err != nil {
+			return fmt.Errorf("无法确认评论当前的点赞状态: %w", err)
+		}
+	}
+	if liked == targetLiked {
+		logrus.Infof("评论 %s 已是%s状态，跳过点击", commentID, actionType)
+		return nil
+	}
+
+	commentEl.MustScrollIntoView()
+	time.Sleep(500 * time.Millisecond)
+	likeBtn, err := commentEl.Element(SelectorCommentLike)
+	if err != nil {
+		return fmt.Errorf("无法找到评论点赞按钮: %w", err)
+	}
+	if err := likeBtn.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return fmt.Errorf("点击评论点赞按钮失败: %w", err)
+	}
+
+	select {
+	case resp := <-likeResp:
+		if !resp.Success {
+			return fmt.Errorf("评论%s失败: %s (code=%d)", actionType, resp.Msg, resp.Code)
+		}
+		logrus.Infof("评论 %s %s成功", commentID, actionType)
+		return nil
+	case <-time.After(5 * time.Second):
+	}
+
+	logrus.Warnf("评论%s：未捕获到接口响应，检查按钮状态", actionType)
+	liked, err = isCommentLikeActive(commentEl)
+	if err != nil {
+		return fmt.Errorf("评论%s结果未确认：未捕获到接口响应且%w", actionType, err)
+	}
+	if liked != targetLiked {
+		return fmt.Errorf("评论%s失败：点击后状态未改变", actionType)
+	}
+	return nil
+}
+
+// isCommentLikeActive 根据点赞按钮样式判断评论是否已点赞，找不到点赞按钮时返回错误
+func isCommentLikeActive(commentEl *rod.Element) (bool, error) {
+	result, err := commentEl.Eval(`function() {
+		const el = this.querySelector('.right .interactions .like');
+		if (!el) return null;
+		return el.querySelector('.like-active, .liked') !== null || el.className.includes('active');
+	}`)
+	if err != nil {
+		return false, fmt.Errorf("读取点赞按钮状态失败: %w", err)
+	}
+	if result.Value.Nil() {
+		return false, fmt.Errorf("未找到评论点赞按钮")
+	}
+	return result.Value.Bool(), nil
+}
+
+// findCommentInEntries 在评论 API 数据中查找评论（包括预加载的楼中楼回复）
+func findCommentInEntries(entries []commentAPIEntry, commentID string) (commentAPIItem, bool) {
+	for _, entry := range entries {
+		var resp commentPageAPIResponse
+		if err := json.Unmarshal([]byte(entry.body), &resp); err != nil {
+			continue
+		}
+		for _, c := range resp.Data.Comments {
+			if c.ID == commentID {
+				return c.commentAPIItem, true
+			}
+			for _, sub := range c.SubComments {
+				if sub.ID == commentID {
+					return sub, true
+				}
+			}
+		}
+	}
+	return commentAPIItem{}, false
+}
@@ -0,0 +1,25 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindCommentInEntries(t *testing.T) {
+	entries := []commentAPIEntry{
+		{body: `{"success":true,"data":{"comments":[{"id":"c1","liked":false}]}}`},
+		{body: `{"success":true,"data":{"comments":[{"id":"c2","liked":false,"sub_comments":[{"id":"s1","liked":true}]}]}}`},
+	}
+
+	item, found := findCommentInEntries(entries, "s1")
+	assert.True(t, found)
+	assert.True(t, item.Liked)
+
+	item, found = findCommentInEntries(entries, "c2")
+	assert.True(t, found)
+	assert.False(t, item.Liked)
+
+	_, found = findCommentInEntries(entries, "missing")
+	assert.False(t, found)
+}
@@ -0,0 +1,544 @@
+package xiaohongshu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/errors"
+)
+
+// commentActionAPIResponse 评论操作类接口（删除、置顶、点赞等）的响应
+type commentActionAPIResponse struct {
+	Code    int    `json:"code"`
+	Success bool   `json:"success"`
+	Msg     string `json:"msg"`
+}
+
+// DeleteComment 删除当前账号发表的评论或楼中楼回复。
+// 点击删除前从评论 API 数据中核对评论作者，不是当前账号发表的返回 errors.ErrNotCommentAuthor
+// （自己笔记下的他人评论菜单中同样有"删除"，需使用 DeleteCommentAsOwner）。
+// parentCommentID 为可选参数，删除子评论时传入可加快定位；
+// 删除接口（/api/sns/web/v1/comment/delete）返回失败时直接报错，否则重新加载评论 API
+// （子评论再展开子评论分页 API）确认该评论已不存在。
+func (f *CommentFeedAction) DeleteComment(ctx context.Context, feedID, xsecToken, commentID, parentCommentID string) error {
+	return f.performCommentMenuOp(feedID, xsecToken, commentID, parentCommentID, opDeleteOwnComment)
+}
+
+// PinComment 置顶自己笔记下的一条顶级评论（仅笔记作者可用），操作后重新打开评论菜单确认已置顶
+func (f *CommentFeedAction) PinComment(ctx context.Context, feedID, xsecToken, commentID string) error {
+	return f.performCommentMenuOp(feedID, xsecToken, commentID, "", opPinComment)
+}
+
+// UnpinComment 取消置顶自己笔记下的评论（仅笔记作者可用），操作后重新打开评论菜单确认已取消
+func (f *CommentFeedAction) UnpinComment(ctx context.Context, feedID, xsecToken, commentID string) error {
+	return f.performCommentMenuOp(feedID, xsecToken, commentID, "", opUnpinComment)
+}
+
+// DeleteCommentAsOwner 以笔记作者身份删除自己笔记下他人的评论或回复
+func (f *CommentFeedAction) DeleteCommentAsOwner(ctx context.Context, feedID, xsecToken, commentID, parentCommentID string) error {
+	return f.performCommentMenuOp(feedID, xsecToken, commentID, parentCommentID, opDeleteAsOwner)
+}
+
+// pinState 评论的置顶状态，以评论菜单中出现"置顶"还是"取消置顶"为准
+type pinState int
+
+const (
+	pinNone     pinState = iota // 不是置顶类操作
+	pinPinned                   // 菜单中有"取消置顶"
+	pinUnpinned                 // 菜单中有"置顶"
+)
+
+// commentMenuScopes 评论操作菜单弹层的常见容器。菜单项只在这些容器内查找，
+// 避免命中评论上的"置顶"标签等同名文字
+var commentMenuScopes = []string{
+	"[role=menu]", ".reds-dropdown", ".reds-popover", "[class*=dropdown]", "[class*=popover]", "[class*=menu-list]",
+}
+
+// commentMenuOp 通过评论操作菜单完成的一类操作
+type commentMenuOp struct {
+	name     string // 操作名，用于日志和错误信息
+	menuText string // 菜单项文字
+	// pinTarget 置顶类操作完成后评论应处的状态，操作前后都从评论菜单读取实际状态；
+	// 为 pinNone 时是删除类操作，操作后重新加载评论 API 确认评论已不存在
+	pinTarget     pinState
+	apiPatterns   []string // 操作接口，返回失败时直接报错
+	requireOwner  bool     // 是否要求当前账号是笔记作者
+	requireAuthor bool     // 是否要求当前账号是评论作者
+	topLevelOnly  bool     // 是否只能作用于顶级评论
+}
+
+var (
+	opDeleteOwnComment = commentMenuOp{
+		name:          "删除评论",
+		menuText:      "删除",
+		apiPatterns:   []string{"*/api/sns/web/v1/comment/delete*"},
+		requireAuthor: true,
+	}
+	opDeleteAsOwner = commentMenuOp{
+		name:         "删除评论",
+		menuText:     "删除",
+		apiPatterns:  []string{"*/api/sns/web/v1/comment/delete*"},
+		requireOwner: true,
+	}
+	opPinComment = commentMenuOp{
+		name:         "置顶评论",
+		menuText:     "置顶",
+		pinTarget:    pinPinned,
+		apiPatterns:  []string{"*/api/sns/web/v1/comment/sticky*", "*/api/sns/web/v1/comment/top*"},
+		requireOwner: true,
+		topLevelOnly: true,
+	}
+	opUnpinComment = commentMenuOp{
+		name:         "取消置顶评论",
+		menuText:     "取消置顶",
+		pinTarget:    pinUnpinned,
+		apiPatterns:  []string{"*/api/sns/web/v1/comment/sticky*", "*/api/sns/web/v1/comment/top*"},
+		requireOwner: true,
+		topLevelOnly: true,
+	}
+)
+
+// performCommentMenuOp 打开详情页、定位评论并执行菜单操作
+func (f *CommentFeedAction) performCommentMenuOp(feedID, xsecToken, commentID, parentCommentID string, op commentMenuOp) error {
+	if op.topLevelOnly && parentCommentID != "" {
+		return fmt.Errorf("%s失败：只能作用于顶级评论", op.name)
+	}
+
+	// 不使用 Context(ctx)，避免继承外部 context 的超时
+	page := f.page.Timeout(5 * time.Minute)
+	url := makeFeedDetailURL(feedID, xsecToken)
+	logrus.Infof("打开 feed 详情页%s: %s, commentID=%s", op.name, url, commentID)
+
+	var commentAPIEntries []commentAPIEntry
+	var commentAPIMu sync.Mutex
+	subPages := newSubCommentPages()
+	opResp := make(chan commentActionAPIResponse, 1)
+
+	router := page.HijackRequests()
+	addCommentPageHijack(router, &commentAPIEntries, &commentAPIMu)
+	subPages.addHijack(router)
+	for _, pattern := range op.apiPatterns {
+		router.MustAdd(pattern, func(ctx *rod.Hijack) {
+			ctx.MustLoadResponse()
+			var resp commentActionAPIResponse
+			if err := json.Unmarshal([]byte(ctx.Response.Body()), &resp); err != nil {
+				logrus.Warnf("%s：解析接口响应失败: %v", op.name, err)
+				return
+			}
+			select {
+			case opResp <- resp:
+			default:
+			}
+		})
+	}
+	go router.Run()
+	defer router.Stop()
+
+	if err := openFeedDetailPage(page, url); err != nil {
+		return err
+	}
+
+	if op.requireOwner {
+		if err := checkNoteOwner(page, feedID); err != nil {
+			return err
+		}
+	}
+
+	commentEl, err := locateComment(page, commentID, "", parentCommentID, &commentAPIEntries, &commentAPIMu)
+	if err != nil {
+		return fmt.Errorf("无法找到评论: %w", err)
+	}
+	rootID, err := commentRootID(commentEl)
+	if err != nil {
+		return err
+	}
+	if op.requireAuthor {
+		commentAPIMu.Lock()
+		author, found := findCommentAuthor(commentAPIEntries, subPages, commentID)
+		commentAPIMu.Unlock()
+		if err := checkCommentAuthor(page, commentID, author, found); err != nil {
+			return err
+		}
+	}
+
+	if err := openCommentMenu(commentEl); err != nil {
+		return err
+	}
+	if op.pinTarget != pinNone {
+		state, err := readMenuPinState(page)
+		if err != nil {
+			return fmt.Errorf("%s失败：%w，当前账号可能没有该评论的操作权限", op.name, err)
+		}
+		if state == op.pinTarget {
+			logrus.Infof("评论 %s 已是%s后的状态，跳过", commentID, op.name)
+			return nil
+		}
+	}
+	if err := clickCommentMenuItem(page, op.menuText); err != nil {
+		return fmt.Errorf("无法在评论菜单中找到\"%s\"，当前账号可能没有该评论的操作权限: %w", op.menuText, err)
+	}
+	if err := confirmDialog(page); err != nil {
+		return fmt.Errorf("确认%s失败: %w", op.name, err)
+	}
+
+	select {
+	case resp := <-opResp:
+		if !resp.Success {
+			return fmt.Errorf("%s失败: %s (code=%d)", op.name, resp.Msg, resp.Code)
+		}
+		logrus.Infof("%s：接口返回成功 commentID=%s", op.name, commentID)
+	case <-time.After(10 * time.Second):
+		logrus.Warnf("%s：未捕获到接口响应", op.name)
+	}
+
+	if op.pinTarget != pinNone {
+		if err := confirmPinState(page, url, commentID, op.pinTarget, &commentAPIEntries, &commentAPIMu); err != nil {
+			return fmt.Errorf("%s后确认失败: %w", op.name, err)
+		}
+		logrus.Infof("%s：评论菜单确认评论 %s 已是目标状态", op.name, commentID)
+		return nil
+	}
+
+	if err := confirmCommentGone(page, url, commentID, rootID, &commentAPIEntries, &commentAPIMu, subPages); err != nil {
+		return fmt.Errorf("%s后确认失败: %w", op.name, err)
+	}
+	logrus.Infof("%s：评论 API 确认评论 %s 已不存在", op.name, commentID)
+	return nil
+}
+
+// confirmPinState 重新加载详情页，从评论菜单读取 commentID 的置顶状态并与 want 比较，
+// 状态不符或读取不到时返回错误
+func confirmPinState(page *rod.Page, url, commentID string, want pinState, apiEntries *[]commentAPIEntry, apiMu *sync.Mutex) error {
+	apiMu.Lock()
+	*apiEntries = nil
+	apiMu.Unlock()
+	if err := openFeedDetailPage(page, url); err != nil {
+		return err
+	}
+
+	commentEl, err := findCommentElementWithAPICheck(page, commentID, "", apiEntries, apiMu)
+	if err != nil {
+		return fmt.Errorf("无法重新定位评论: %w", err)
+	}
+	if err := openCommentMenu(commentEl); err != nil {
+		return err
+	}
+	got, err := readMenuPinState(page)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		if want == pinPinned {
+			return fmt.Errorf("评论 %s 仍未置顶", commentID)
+		}
+		return fmt.Errorf("评论 %s 仍处于置顶状态", commentID)
+	}
+	return nil
+}
+
+// readMenuPinState 从已打开的评论菜单中读取置顶状态
+func readMenuPinState(page *rod.Page) (pinState, error) {
+	if _, err := findVisibleElementByText(page, "取消置顶", commentMenuScopes...); err == nil {
+		return pinPinned, nil
+	}
+	if _, err := findVisibleElementByText(page, "置顶", commentMenuScopes...); err == nil {
+		return pinUnpinned, nil
+	}
+	return pinNone, fmt.Errorf("评论菜单中没有置顶选项，无法确认置顶状态")
+}
+
+// commentRootID 返回评论元素所属的顶级评论 ID，元素本身是顶级评论时返回它自己的 ID
+func commentRootID(commentEl *rod.Element) (string, error) {
+	result, err := commentEl.Eval(`function() {
+		const parent = this.closest('.parent-comment');
+		const root = parent && parent.querySelector('.comment-item');
+		return (root || this).id.replace(/^comment-/, '');
+	}`)
+	if err != nil {
+		return "", fmt.Errorf("读取评论所属的顶级评论失败: %w", err)
+	}
+	return result.Value.String(), nil
+}
+
+// confirmCommentGone 重新加载详情页，通过评论列表 API 确认 commentID 已不存在。
+// rootID 与 commentID 不同时目标是子评论：先找到顶级评论，再沿子评论分页 API 逐页检查。
+// 评论仍存在或无法加载到足够的数据时返回错误。
+func confirmCommentGone(page *rod.Page, url, commentID, rootID string, apiEntries *[]commentAPIEntry, apiMu *sync.Mutex, subPages *subCommentPages) error {
+	apiMu.Lock()
+	*apiEntries = nil
+	apiMu.Unlock()
+	if err := openFeedDetailPage(page, url); err != nil {
+		return err
+	}
+
+	rootFound, err := waitTopLevelCommentInAPI(page, rootID, apiEntries, apiMu)
+	if err != nil {
+		return err
+	}
+	if !rootFound {
+		// 顶级评论不存在时其下的子评论也随之不存在
+		return nil
+	}
+	if rootID == commentID {
+		return fmt.Errorf("评论 %s 仍在评论 API 中", commentID)
+	}
+
+	apiMu.Lock()
+	current, _, _ := findPreloadedReplies(*apiEntries, rootID)
+	apiMu.Unlock()
+	if current.hasMore {
+		if _, err := findCommentElementWithAPICheck(page, rootID, "", apiEntries, apiMu); err != nil {
+			return fmt.Errorf("无法定位顶级评论 %s: %w", rootID, err)
+		}
+	}
+	for {
+		if replyPageContains(current, commentID) {
+			return fmt.Errorf("子评论 %s 仍在评论 API 中", commentID)
+		}
+		if !current.hasMore {
+			return nil
+		}
+		if current, err = subPages.fetchNext(page, rootID, current); err != nil {
+			return fmt.Errorf("无法加载全部子评论: %w", err)
+		}
+	}
+}
+
+// waitTopLevelCommentInAPI 滚动评论区直到评论 API 中出现顶级评论 commentID 或全部评论页加载完毕，
+// 返回该顶级评论是否存在；加载不到完整数据时返回错误
+func waitTopLevelCommentInAPI(page *rod.Page, commentID string, apiEntries *[]commentAPIEntry, apiMu *sync.Mutex) (bool, error) {
+	const maxScrollRounds = 100
+
+	scrollToCommentsArea(page)
+	for round := 0; round < maxScrollRounds; round++ {
+		apiMu.Lock()
+		entries := make([]commentAPIEntry, len(*apiEntries))
+		copy(entries, *apiEntries)
+		apiMu.Unlock()
+
+		found, complete := topLevelCommentInEntries(entries, commentID)
+		if found || complete {
+			return found, nil
+		}
+
+		scrollToLastComment(page)
+		_, _ = page.Eval(`() => { window.scrollBy(0, window.innerHeight * 0.8); return true; }`)
+		for i := 0; i < 5; i++ {
+			time.Sleep(1 * time.Second)
+			apiMu.Lock()
+			newCount := len(*apiEntries)
+			apiMu.Unlock()
+			if newCount > len(entries) {
+				break
+			}
+		}
+	}
+	return false, fmt.Errorf("滚动 %d 轮后评论 API 仍未加载完毕，无法确认评论 %s 的状态", maxScrollRounds, commentID)
+}
+
+// topLevelCommentInEntries 检查评论 API 响应中是否有顶级评论 commentID；
+// complete 表示已捕获到最后一页（has_more=false），此时 found=false 可以断定评论不存在
+func topLevelCommentInEntries(entries []commentAPIEntry, commentID string) (found, complete bool) {
+	for _, entry := range entries {
+		var resp commentPageAPIResponse
+		if err := json.Unmarshal([]byte(entry.body), &resp); err != nil {
+			continue
+		}
+		for _, c := range resp.Data.Comments {
+			if c.ID == commentID {
+				return true, false
+			}
+		}
+	}
+	return false, len(entries) > 0 && !entries[len(entries)-1].hasMore
+}
+
+// replyPageContains 检查一页子评论中是否有 commentID
+func replyPageContains(p replyPage, commentID string) bool {
+	for _, r := range p.replies {
+		if r.ID == commentID {
+			return true
+		}
+	}
+	return false
+}
+
+// findCommentAuthor 从已捕获的评论列表 API 和子评论分页 API 中取出 commentID 的作者
+func findCommentAuthor(entries []commentAPIEntry, subPages *subCommentPages, commentID string) (string, bool) {
+	for _, entry := range entries {
+		var resp commentPageAPIResponse
+		if err := json.Unmarshal([]byte(entry.body), &resp); err != nil {
+			continue
+		}
+		for _, c := range resp.Data.Comments {
+			if c.ID == commentID {
+				return c.UserInfo.UserID, true
+			}
+			for _, sub := range c.SubComments {
+				if sub.ID == commentID {
+					return sub.UserInfo.UserID, true
+				}
+			}
+		}
+	}
+
+	subPages.mu.Lock()
+	defer subPages.mu.Unlock()
+	for _, resp := range subPages.pages {
+		for _, c := range resp.Data.Comments {
+			if c.ID == commentID {
+				return c.UserInfo.UserID, true
+			}
+		}
+	}
+	return "", false
+}
+
+// checkCommentAuthor 检查评论作者是否为当前登录账号，无法确认时同样拒绝，避免误删他人评论
+func checkCommentAuthor(page *rod.Page, commentID, author string, found bool) error {
+	if !found || author == "" {
+		return fmt.Errorf("评论 API 中没有评论 %s 的作者信息，无法确认是否为当前账号发表", commentID)
+	}
+	me, err := loggedInUserID(page)
+	if err != nil {
+		return err
+	}
+	if me != author {
+		logrus.Warnf("评论 %s 的作者 %s 不是当前用户 %s", commentID, author, me)
+		return errors.ErrNotCommentAuthor
+	}
+	return nil
+}
+
+// loggedInUserID 从页面状态中读取当前登录用户 ID
+func loggedInUserID(page *rod.Page) (string, error) {
+	result, err := page.Eval(`() => {
+		const state = window.__INITIAL_STATE__;
+		const unwrap = v => (v && (v.value !== undefined ? v.value : v._value)) || v;
+		const userInfo = state && state.user && unwrap(state.user.userInfo);
+		return (userInfo && (userInfo.userId || userInfo.user_id)) || "";
+	}`)
+	if err != nil {
+		return "", fmt.Errorf("读取登录用户失败: %w", err)
+	}
+	me := result.Value.String()
+	if me == "" {
+		return "", fmt.Errorf("无法读取当前登录用户，请确认已登录")
+	}
+	return me, nil
+}
+
+// checkNoteOwner 检查当前登录账号是否为笔记作者
+func checkNoteOwner(page *rod.Page, feedID string) error {
+	result, err := page.Eval(`(feedID) => {
+		const state = window.__INITIAL_STATE__;
+		if (!state) return {};
+		const unwrap = v => (v && (v.value !== undefined ? v.value : v._value)) || v;
+		const userInfo = state.user && unwrap(state.user.userInfo);
+		const noteMap = state.note && unwrap(state.note.noteDetailMap);
+		const detail = noteMap && noteMap[feedID];
+		return {
+			me: (userInfo && (userInfo.userId || userInfo.user_id)) || "",
+			author: (detail && detail.note && detail.note.user && detail.note.user.userId) || "",
+		};
+	}`, feedID)
+	if err != nil {
+		return fmt.Errorf("读取登录用户和笔记作者失败: %w", err)
+	}
+
+	me := result.Value.Get("me").String()
+	author := result.Value.Get("author").String()
+	if me == "" || author == "" {
+		return fmt.Errorf("无法确认笔记作者（当前用户=%q，作者=%q），请确认已登录", me, author)
+	}
+	if me != author {
+		logrus.Warnf("当前用户 %s 不是笔记 %s 的作者 %s", me, feedID, author)
+		return errors.ErrNotNoteOwner
+	}
+	return nil
+}
+
+// openCommentMenu 悬停评论并打开其操作菜单
+func openCommentMenu(commentEl *rod.Element) error {
+	commentEl.MustScrollIntoView()
+	time.Sleep(500 * time.Millisecond)
+	if err := commentEl.Hover(); err != nil {
+		return fmt.Errorf("悬停评论失败: %w", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+
+	// 操作菜单入口只在悬停时出现，不同版本的类名不一致，按常见类名依次尝试
+	opened, err := commentEl.Eval(`function() {
+		const trigger = this.querySelector('.right .menu, .right .more, .right .operation, .right [class*="more"], .right [class*="menu"]');
+		if (!trigger) return false;
+		trigger.click();
+		return true;
+	}`)
+	if err != nil {
+		return fmt.Errorf("打开评论菜单失败: %w", err)
+	}
+	if !opened.Value.Bool() {
+		logrus.Info("评论菜单：未找到菜单入口，直接查找菜单项")
+	}
+	time.Sleep(500 * time.Millisecond)
+	return nil
+}
+
+// clickCommentMenuItem 在已打开的评论菜单中点击文字为 itemText 的菜单项（如"删除"、"置顶"）。
+// 菜单只对有权限的评论显示，找不到菜单项时返回错误。
+func clickCommentMenuItem(page *rod.Page, itemText string) error {
+	item, err := findVisibleElementByText(page, itemText, commentMenuScopes...)
+	if err != nil {
+		return err
+	}
+	if err := item.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return fmt.Errorf("点击菜单项 %s 失败: %w", itemText, err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	return nil
+}
+
+// confirmDialog 点击确认弹窗中的确认按钮，页面没有弹窗时直接返回
+func confirmDialog(page *rod.Page) error {
+	for _, text := range []string{"确定", "确认", "删除"} {
+		btn, err := findVisibleElementByText(page, text, ".reds-modal", ".modal", "[role=dialog]", ".dialog")
+		if err != nil {
+			continue
+		}
+		if err := btn.Click(proto.InputMouseButtonLeft, 1); err != nil {
+			return fmt.Errorf("点击确认按钮失败: %w", err)
+		}
+		time.Sleep(1 * time.Second)
+		return nil
+	}
+	logrus.Info("确认弹窗：未出现确认弹窗，跳过")
+	return nil
+}
+
+// findVisibleElementByText 查找文字恰好为 text 的可见元素（取最内层的一个）。
+// scopes 非空时只在这些容器内查找。
+func findVisibleElementByText(page *rod.Page, text string, scopes ...string) (*rod.Element, error) {
+	el, err := page.Timeout(3 * time.Second).ElementByJS(rod.Eval(`(text, scopes) => {
+		const roots = scopes.length > 0
+			? scopes.flatMap(s => Array.from(document.querySelectorAll(s)))
+			: [document.body];
+		for (const root of roots) {
+			const candidates = Array.from(root.querySelectorAll('*')).filter(el =>
+				el.textContent.trim() === text &&
+				el.offsetParent !== null &&
+				!Array.from(el.children).some(c => c.textContent.trim() === text));
+			if (candidates.length > 0) return candidates[candidates.length - 1];
+		}
+		return null;
+	}`, text, append([]string{}, scopes...)))
+	if err != nil {
+		return nil, fmt.Errorf("未找到 %s: %w", text, err)
+	}
+	return el.CancelTimeout(), nil
+}
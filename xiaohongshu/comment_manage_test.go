@@ -0,0 +1,54 @@
+package xiaohongshu
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopLevelCommentInEntries(t *testing.T) {
+	page1 := commentAPIEntry{hasMore: true, body: `{"code":0,"success":true,"data":{"has_more":true,"comments":[
+		{"id":"c1","sub_comments":[{"id":"s1"}]}]}}`}
+	page2 := commentAPIEntry{hasMore: false, body: `{"code":0,"success":true,"data":{"has_more":false,"comments":[
+		{"id":"c2"}]}}`}
+
+	found, complete := topLevelCommentInEntries([]commentAPIEntry{page1}, "c2")
+	require.False(t, found)
+	require.False(t, complete, "还有后续页时不能断定评论不存在")
+
+	found, _ = topLevelCommentInEntries([]commentAPIEntry{page1, page2}, "c2")
+	require.True(t, found)
+
+	found, complete = topLevelCommentInEntries([]commentAPIEntry{page1, page2}, "s1")
+	require.False(t, found, "子评论不算顶级评论")
+	require.True(t, complete)
+
+	_, complete = topLevelCommentInEntries(nil, "c1")
+	require.False(t, complete, "没有捕获到任何响应时不能断定评论不存在")
+}
+
+func TestFindCommentAuthor(t *testing.T) {
+	entries := []commentAPIEntry{{body: `{"code":0,"success":true,"data":{"comments":[
+		{"id":"c1","user_info":{"user_id":"me"},"sub_comments":[{"id":"s1","user_info":{"user_id":"other"}}]}]}}`}}
+	subPages := newSubCommentPages()
+	var resp subCommentPageAPIResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"success":true,"data":{"comments":[{"id":"s9","user_info":{"user_id":"me"}}]}}`), &resp))
+	subPages.pages[subCommentPageKey{root: "c1", cursor: "cur"}] = resp
+
+	tests := []struct {
+		commentID  string
+		wantAuthor string
+		wantFound  bool
+	}{
+		{commentID: "c1", wantAuthor: "me", wantFound: true},
+		{commentID: "s1", wantAuthor: "other", wantFound: true},
+		{commentID: "s9", wantAuthor: "me", wantFound: true},
+		{commentID: "missing"},
+	}
+	for _, tt := range tests {
+		author, found := findCommentAuthor(entries, subPages, tt.commentID)
+		require.Equal(t, tt.wantFound, found, tt.commentID)
+		require.Equal(t, tt.wantAuthor, author, tt.commentID)
+	}
+}
@@ -0,0 +1,184 @@
+package xiaohongshu
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/errors"
+)
+
+// mentionUser @ 选人面板中的一个候选用户
+type mentionUser struct {
+	UserID   string
+	Nickname string
+}
+
+// mentionCandidates 记录 @ 选人面板接口返回的候选用户，按接口分别保存最近一次响应。
+// 面板刚唤起时显示最近联系人（intimacy_list），输入昵称后显示按昵称搜索的结果，
+// 最近联系人以外的用户只会出现在搜索结果中。
+type mentionCandidates struct {
+	mu sync.Mutex
+	// searched 按昵称搜索的结果
+	searched []mentionUser
+	// recent 最近联系人列表
+	recent []mentionUser
+}
+
+// mentionSearchAPIResponse @ 选人面板接口（最近联系人 / 按昵称搜索）的响应
+type mentionSearchAPIResponse struct {
+	Success bool `json:"success"`
+	Data    struct {
+		Users []struct {
+			ID       string `json:"id"`
+			UserID   string `json:"user_id"`
+			Nickname string `json:"nickname"`
+			Name     string `json:"name"`
+		} `json:"users"`
+	} `json:"data"`
+}
+
+// parseMentionSearchResponse 解析选人面板接口的响应，兼容 id/user_id、nickname/name 两种字段
+func parseMentionSearchResponse(body string) ([]mentionUser, error) {
+	var resp mentionSearchAPIResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("选人面板接口返回失败")
+	}
+
+	users := make([]mentionUser, 0, len(resp.Data.Users))
+	for _, u := range resp.Data.Users {
+		user := mentionUser{UserID: u.UserID, Nickname: u.Nickname}
+		if user.UserID == "" {
+			user.UserID = u.ID
+		}
+		if user.Nickname == "" {
+			user.Nickname = u.Name
+		}
+		if user.UserID != "" {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// addMentionSearchHijack 在 router 上拦截 @ 选人面板的最近联系人接口和按昵称搜索接口。
+// intimacy_list 与 intimacy_list/search 共用一个拦截规则（rod 会对同一请求执行所有匹配的规则），按路径区分来源。
+func addMentionSearchHijack(router *rod.HijackRouter, candidates *mentionCandidates) {
+	capture := func(ctx *rod.Hijack) {
+		ctx.MustLoadResponse()
+		users, err := parseMentionSearchResponse(ctx.Response.Body())
+		if err != nil {
+			logrus.Warnf("解析 @ 选人面板接口响应失败: %v", err)
+			return
+		}
+		candidates.set(strings.Contains(ctx.Request.URL().Path, "search"), users)
+	}
+	router.MustAdd("*/api/sns/web/v1/intimacy/intimacy_list*", capture)
+	router.MustAdd("*/api/sns/web/v1/search/usersearch*", capture)
+}
+
+// set 保存一次接口响应，searched 表示来自按昵称搜索的接口
+func (c *mentionCandidates) set(searched bool, users []mentionUser) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if searched {
+		c.searched = users
+	} else {
+		c.recent = users
+	}
+}
+
+// find 查找用户，返回用户及其在所属列表中的位置。
+// 已输入昵称时面板显示的是搜索结果，因此优先在搜索结果中查找，其次是最近联系人。
+func (c *mentionCandidates) find(userID string) (mentionUser, int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, list := range [][]mentionUser{c.searched, c.recent} {
+		for i, u := range list {
+			if u.UserID == userID {
+				return u, i, true
+			}
+		}
+	}
+	return mentionUser{}, -1, false
+}
+
+// reset 清空候选用户，避免上一次搜索的结果被误用
+func (c *mentionCandidates) reset() {
+	c.mu.Lock()
+	c.searched = nil
+	c.recent = nil
+	c.mu.Unlock()
+}
+
+// insertMention 在输入框中输入 "@昵称" 唤起选人面板，等待接口返回包含 userID 的候选列表后点击该用户，
+// 最后确认输入框中生成了提及节点。任何一步无法确认时返回 ErrMentionNotResolved，调用方不应提交评论。
+func insertMention(page *rod.Page, inputEl *rod.Element, userID, nickname string, candidates *mentionCandidates) error {
+	if candidates == nil {
+		return fmt.Errorf("%w: 未拦截选人面板接口", errors.ErrMentionNotResolved)
+	}
+	candidates.reset()
+
+	if err := inputEl.Input("@" + nickname); err != nil {
+		return fmt.Errorf("输入 @ 失败: %w", err)
+	}
+
+	var user mentionUser
+	index := -1
+	deadline := time.Now().Add(8 * time.Second)
+	for time.Now().Before(deadline) {
+		var found bool
+		if user, index, found = candidates.find(userID); found {
+			break
+		}
+		time.Sleep(300 * time.Millisecond)
+	}
+	if index < 0 {
+		hint := ""
+		if nickname == "" {
+			hint = "，可使用 @{user_id:昵称} 提供昵称以便搜索"
+		}
+		return fmt.Errorf("%w: 选人面板中没有用户 %s%s", errors.ErrMentionNotResolved, userID, hint)
+	}
+
+	// 选人面板的条目与接口返回顺序一致，优先按位置点击，位置上的昵称对不上时再按昵称查找
+	item, err := page.Timeout(3 * time.Second).ElementByJS(rod.Eval(`(index, name) => {
+		const panels = document.querySelectorAll('[class*="mention"], [class*="at-user"], [class*="user-list"], [class*="intimacy"]');
+		for (const panel of panels) {
+			if (panel.offsetParent === null) continue;
+			const items = Array.from(panel.querySelectorAll('[class*="item"], li')).filter(el => el.offsetParent !== null);
+			if (items.length === 0) continue;
+			if (items[index] && items[index].textContent.includes(name)) return items[index];
+			const byName = items.find(el => el.textContent.trim().includes(name));
+			if (byName) return byName;
+		}
+		return null;
+	}`, index, user.Nickname))
+	if err != nil {
+		return fmt.Errorf("%w: 选人面板中找不到 %s(%s)", errors.ErrMentionNotResolved, user.Nickname, userID)
+	}
+	if err := item.CancelTimeout().Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return fmt.Errorf("点击提及用户 %s 失败: %w", user.Nickname, err)
+	}
+	time.Sleep(500 * time.Millisecond)
+
+	// 提及成功时输入框中会出现一个文字为 "@昵称" 的节点，纯文字的 @ 不会触发通知
+	resolved, err := inputEl.Eval(`function(name) {
+		return Array.from(this.querySelectorAll('*')).some(el =>
+			el !== this && el.textContent.trim() === '@' + name);
+	}`, user.Nickname)
+	if err != nil || !resolved.Value.Bool() {
+		return fmt.Errorf("%w: 输入框中未生成 @%s 的提及", errors.ErrMentionNotResolved, user.Nickname)
+	}
+
+	logrus.Infof("已提及用户 %s(%s)", user.Nickname, userID)
+	return nil
+}
@@ -0,0 +1,115 @@
+package xiaohongshu
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/errors"
+)
+
+// PostedComment 由发表接口确认的评论或回复
+type PostedComment struct {
+	// ID 平台分配的评论 ID
+	ID         string `json:"id,omitempty"`
+	Content    string `json:"content,omitempty"`
+	CreateTime int64  `json:"create_time,omitempty"`
+}
+
+// commentPostAPIResponse 发表评论接口（/api/sns/web/v1/comment/post）的响应
+type commentPostAPIResponse struct {
+	Code    int    `json:"code"`
+	Success bool   `json:"success"`
+	Msg     string `json:"msg"`
+	Data    struct {
+		Comment commentAPIItem `json:"comment"`
+	} `json:"data"`
+}
+
+// parseCommentPostResponse 解析发表接口的响应，平台拒绝时返回 *errors.CommentRejectedError
+func parseCommentPostResponse(resp commentPostAPIResponse) (*PostedComment, error) {
+	if !resp.Success {
+		return nil, errors.NewCommentRejectedError(resp.Code, resp.Msg)
+	}
+	c := resp.Data.Comment
+	return &PostedComment{ID: c.ID, Content: c.Content, CreateTime: c.CreateTime}, nil
+}
+
+// addCommentPostHijack 在 router 上拦截发表评论接口，响应写入 postResp（只保留第一条）
+func addCommentPostHijack(router *rod.HijackRouter, postResp chan<- commentPostAPIResponse) {
+	router.MustAdd("*/api/sns/web/v1/comment/post*", func(ctx *rod.Hijack) {
+		ctx.MustLoadResponse()
+		var resp commentPostAPIResponse
+		if err := json.Unmarshal([]byte(ctx.Response.Body()), &resp); err != nil {
+			logrus.Warnf("解析发表评论接口响应失败: %v", err)
+			return
+		}
+		select {
+		case postResp <- resp:
+		default:
+		}
+	})
+}
+
+// submitComment 点击提交按钮，并以发表接口的响应确认结果。
+// 未捕获到响应时检查页面提示，有拒绝提示则按拒绝处理，否则返回 ErrCommentUnconfirmed，
+// 调用方不能把这种情况当作已发表。
+func submitComment(page *rod.Page, postResp <-chan commentPostAPIResponse) (*PostedComment, error) {
+	submitButton, err := page.Element("div.bottom button.submit")
+	if err != nil {
+		logrus.Warnf("Failed to find submit button: %v", err)
+		return nil, fmt.Errorf("未找到提交按钮: %w", err)
+	}
+
+	if err := submitButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		logrus.Warnf("Failed to click submit button: %v", err)
+		return nil, fmt.Errorf("无法点击提交按钮: %w", err)
+	}
+
+	select {
+	case resp := <-postResp:
+		return parseCommentPostResponse(resp)
+	case <-time.After(10 * time.Second):
+	}
+
+	logrus.Warn("发表评论：未捕获到接口响应，检查页面提示")
+	if msg, ok := findCommentRejectToast(page); ok {
+		return nil, errors.NewCommentRejectedError(0, msg)
+	}
+	return nil, errors.ErrCommentUnconfirmed
+}
+
+// findCommentRejectToast 查找页面上可以归类的拒绝提示（toast / 弹窗）
+func findCommentRejectToast(page *rod.Page) (string, bool) {
+	result, err := page.Eval(`() => {
+		const els = document.querySelectorAll('[class*="toast"], [class*="Toast"], [class*="message"], [role=alert]');
+		return Array.from(els).filter(el => el.offsetParent !== null).map(el => el.innerText.trim()).filter(Boolean);
+	}`)
+	if err != nil {
+		return "", false
+	}
+	for _, v := range result.Value.Arr() {
+		if msg := v.String(); errors.ClassifyCommentRejection(msg) != errors.RejectUnknown {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// detectCommentsClosed 检查笔记是否关闭了评论（评论区显示关闭提示，没有输入框）
+func detectCommentsClosed(page *rod.Page) (string, bool) {
+	result, err := page.Eval(`() => {
+		const root = document.querySelector('#noteContainer, .note-container') || document.body;
+		const text = root.innerText || '';
+		const keywords = ['评论已关闭', '作者关闭了评论', '已关闭评论', '暂不支持评论', '仅作者关注的人可评论'];
+		return keywords.find(k => text.includes(k)) || '';
+	}`)
+	if err != nil {
+		return "", false
+	}
+	msg := result.Value.String()
+	return msg, msg != ""
+}
@@ -0,0 +1,25 @@
+package xiaohongshu
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xpzouying/xiaohongshu-mcp/errors"
+)
+
+func TestParseCommentPostResponse(t *testing.T) {
+	var resp commentPostAPIResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"code":0,"success":true,"data":{"comment":{"id":"c1","content":"好看","create_time":1700000000000}}}`), &resp))
+
+	posted, err := parseCommentPostResponse(resp)
+	require.NoError(t, err)
+	assert.Equal(t, &PostedComment{ID: "c1", Content: "好看", CreateTime: 1700000000000}, posted)
+
+	_, err = parseCommentPostResponse(commentPostAPIResponse{Code: -9011, Msg: "评论内容包含敏感词"})
+	rejectErr, ok := errors.AsCommentRejected(err)
+	require.True(t, ok)
+	assert.Equal(t, errors.RejectSensitive, rejectErr.Reason)
+	assert.Equal(t, -9011, rejectErr.APICode)
+}
@@ -0,0 +1,313 @@
+package xiaohongshu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/sirupsen/logrus"
+)
+
+// maxReplyPages 单次最多返回的子评论页数（从请求的 cursor 所在页开始计数），
+// 超过后返回 next cursor 由调用方继续翻页
+const maxReplyPages = 30
+
+// subCommentPageAPIResponse 小红书子评论（楼中楼）分页 API 的原始响应结构
+type subCommentPageAPIResponse struct {
+	Code    int    `json:"code"`
+	Success bool   `json:"success"`
+	Msg     string `json:"msg"`
+	Data    struct {
+		Comments []commentAPIItem `json:"comments"`
+		HasMore  bool             `json:"has_more"`
+		Cursor   string           `json:"cursor"`
+	} `json:"data"`
+}
+
+// CommentRepliesResult 单条评论下的楼中楼回复
+type CommentRepliesResult struct {
+	CommentID string `json:"comment_id"`
+	Total     string `json:"total"` // 平台给出的子评论总数
+	// Parent 顶级评论本身（不含预加载的子评论）
+	Parent  *Comment  `json:"parent,omitempty"`
+	Replies []Comment `json:"replies"` // 按时间顺序排列
+	// 下一页游标，HasMore 为 true 时传给 cursor 参数继续获取
+	Cursor  string `json:"cursor,omitempty"`
+	HasMore bool   `json:"has_more"`
+}
+
+// subCommentPageKey 子评论分页响应的索引：所属顶级评论 + 请求游标
+type subCommentPageKey struct {
+	root   string
+	cursor string
+}
+
+// subCommentPages 收集页面上发出的子评论分页 API（/api/sns/web/v2/comment/sub/page）响应
+type subCommentPages struct {
+	mu    sync.Mutex
+	pages map[subCommentPageKey]subCommentPageAPIResponse
+}
+
+func newSubCommentPages() *subCommentPages {
+	return &subCommentPages{pages: make(map[subCommentPageKey]subCommentPageAPIResponse)}
+}
+
+// addHijack 在 router 上拦截子评论分页 API，router 必须在页面导航前开始运行
+func (s *subCommentPages) addHijack(router *rod.HijackRouter) {
+	router.MustAdd("*/api/sns/web/v2/comment/sub/page*", func(ctx *rod.Hijack) {
+		ctx.MustLoadResponse()
+		query := ctx.Request.URL().Query()
+		var resp subCommentPageAPIResponse
+		if err := json.Unmarshal([]byte(ctx.Response.Body()), &resp); err != nil || !resp.Success {
+			return
+		}
+		s.mu.Lock()
+		s.pages[subCommentPageKey{root: query.Get("root_comment_id"), cursor: query.Get("cursor")}] = resp
+		s.mu.Unlock()
+		logrus.Infof("子评论：捕获到分页响应（%d条，has_more=%v）", len(resp.Data.Comments), resp.Data.HasMore)
+	})
+}
+
+// fetchNext 点击顶级评论 rootID 下的"展开更多回复"，等待 current.next 对应的分页响应
+// 之前定位子评论时已经展开过的页直接从已捕获的响应中取，不再点击
+func (s *subCommentPages) fetchNext(page *rod.Page, rootID string, current replyPage) (replyPage, error) {
+	key := subCommentPageKey{root: rootID, cursor: current.next}
+	if next, ok := s.lookup(key); ok {
+		return next, nil
+	}
+
+	clicked, err := clickShowMoreReplies(page, rootID)
+	if err != nil {
+		return replyPage{}, fmt.Errorf("展开更多回复失败: %w", err)
+	}
+	if !clicked {
+		return replyPage{}, fmt.Errorf("未找到\"展开更多回复\"按钮")
+	}
+
+	for i := 0; i < 10; i++ {
+		time.Sleep(500 * time.Millisecond)
+		if next, ok := s.lookup(key); ok {
+			return next, nil
+		}
+	}
+	return replyPage{}, fmt.Errorf("等待 cursor=%s 的分页响应超时", current.next)
+}
+
+// rootOf 在已捕获的子评论分页中查找 commentID，返回其所属的顶级评论 ID，找不到时为空
+func (s *subCommentPages) rootOf(commentID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, resp := range s.pages {
+		for _, c := range resp.Data.Comments {
+			if c.ID == commentID {
+				return key.root
+			}
+		}
+	}
+	return ""
+}
+
+// lookup 取出已捕获的一页子评论
+func (s *subCommentPages) lookup(key subCommentPageKey) (replyPage, bool) {
+	s.mu.Lock()
+	resp, ok := s.pages[key]
+	s.mu.Unlock()
+	if !ok {
+		return replyPage{}, false
+	}
+	p := replyPage{cursor: key.cursor, next: resp.Data.Cursor, hasMore: resp.Data.HasMore}
+	for _, c := range resp.Data.Comments {
+		p.replies = append(p.replies, c.toComment())
+	}
+	return p, true
+}
+
+// replyPage 一页子评论数据
+type replyPage struct {
+	cursor  string // 请求该页时使用的游标（预加载页为空）
+	replies []Comment
+	next    string
+	hasMore bool
+}
+
+// GetCommentReplies 获取指定顶级评论下的全部子评论。
+// 预加载的子评论来自评论列表 API（sub_comments），之后通过点击"展开更多回复"
+// 触发子评论分页 API（/api/sns/web/v2/comment/sub/page），按游标顺序拼接。
+// cursor 为空时从第一条回复开始；非空时返回该游标之后的回复。
+func (f *FeedDetailAction) GetCommentReplies(ctx context.Context, feedID, xsecToken, commentID, cursor string) (*CommentRepliesResult, error) {
+	return f.getCommentReplies(ctx, feedID, xsecToken, commentID, cursor, false)
+}
+
+// getCommentReplies resolveRoot 为 true 时 commentID 可以是楼中楼回复，
+// 定位到该回复后改为获取其所在楼层的顶级评论；结果中的 CommentID 是实际使用的顶级评论
+func (f *FeedDetailAction) getCommentReplies(ctx context.Context, feedID, xsecToken, commentID, cursor string, resolveRoot bool) (*CommentRepliesResult, error) {
+	page := f.page.Context(ctx).Timeout(5 * time.Minute)
+	url := makeFeedDetailURL(feedID, xsecToken)
+	logrus.Infof("打开 feed 详情页获取子评论: %s, commentID=%s, cursor=%s", url, commentID, cursor)
+
+	var commentAPIEntries []commentAPIEntry
+	var commentAPIMu sync.Mutex
+	subPages := newSubCommentPages()
+
+	router := page.HijackRequests()
+	addCommentPageHijack(router, &commentAPIEntries, &commentAPIMu)
+	subPages.addHijack(router)
+	go router.Run()
+	defer router.Stop()
+
+	if err := openFeedDetailPage(page, url); err != nil {
+		return nil, err
+	}
+
+	if resolveRoot {
+		commentEl, err := locateComment(page, commentID, "", "", &commentAPIEntries, &commentAPIMu)
+		if err != nil {
+			return nil, fmt.Errorf("无法找到评论: %w", err)
+		}
+		// 顶级评论优先取评论 API 的归属（预加载的 sub_comments 或子评论分页的 root_comment_id），取不到时看页面结构
+		rootID := findParentCommentIDFromAPIEntries(&commentAPIEntries, &commentAPIMu, commentID)
+		if rootID == "" {
+			rootID = subPages.rootOf(commentID)
+		}
+		if rootID == "" {
+			if rootID, err = commentRootID(commentEl); err != nil {
+				return nil, err
+			}
+		}
+		if rootID != commentID {
+			logrus.Infof("子评论：%s 是楼中楼回复，改为获取顶级评论 %s 的楼层", commentID, rootID)
+			commentID = rootID
+		}
+	} else if _, err := findCommentElementWithAPICheck(page, commentID, "", &commentAPIEntries, &commentAPIMu); err != nil {
+		return nil, fmt.Errorf("无法找到评论: %w", err)
+	}
+
+	commentAPIMu.Lock()
+	first, parent, found := findPreloadedReplies(commentAPIEntries, commentID)
+	commentAPIMu.Unlock()
+	if !found {
+		return nil, fmt.Errorf("评论 %s 不是顶级评论，请传入其父评论 ID", commentID)
+	}
+
+	pages := []replyPage{first}
+	current := first
+	for current.hasMore && needMoreReplyPages(pages, cursor) {
+		next, err := subPages.fetchNext(page, commentID, current)
+		if err != nil {
+			logrus.Warnf("子评论：%v，返回已获取部分", err)
+			break
+		}
+		current = next
+		pages = append(pages, current)
+	}
+
+	result, err := assembleReplyPages(pages, cursor)
+	if err != nil {
+		return nil, err
+	}
+	result.CommentID = commentID
+	result.Total = parent.SubCommentCount
+	result.Parent = &parent
+
+	logrus.Infof("子评论：commentID=%s 共获取 %d 条回复（总数 %s），has_more=%v",
+		commentID, len(result.Replies), result.Total, result.HasMore)
+	return result, nil
+}
+
+// findPreloadedReplies 从评论列表 API 数据中找到顶级评论及其预加载的子评论（作为第一页），
+// 返回的顶级评论带有 SubCommentCount
+func findPreloadedReplies(entries []commentAPIEntry, commentID string) (replyPage, Comment, bool) {
+	for _, entry := range entries {
+		var resp commentPageAPIResponse
+		if err := json.Unmarshal([]byte(entry.body), &resp); err != nil {
+			continue
+		}
+		for _, c := range resp.Data.Comments {
+			if c.ID != commentID {
+				continue
+			}
+			first := replyPage{next: c.SubCommentCursor, hasMore: c.SubCommentHasMore}
+			for _, sub := range c.SubComments {
+				first.replies = append(first.replies, sub.toComment())
+			}
+			parent := c.toComment()
+			parent.SubCommentCount = c.SubCommentCount
+			return first, parent, true
+		}
+	}
+	return replyPage{}, Comment{}, false
+}
+
+// needMoreReplyPages 判断是否还需继续展开子评论：
+// 尚未走到 cursor 对应的页时一直展开；走到之后从该页起最多返回 maxReplyPages 页，
+// 这样超过上限时返回的 next cursor 在下一次调用中一定能被走到
+func needMoreReplyPages(pages []replyPage, cursor string) bool {
+	for i, p := range pages {
+		if p.cursor == cursor {
+			return len(pages)-i < maxReplyPages
+		}
+	}
+	return true
+}
+
+// assembleReplyPages 按顺序拼接子评论分页，跳过 cursor 之前的页并按 ID 去重
+func assembleReplyPages(pages []replyPage, cursor string) (*CommentRepliesResult, error) {
+	result := &CommentRepliesResult{Replies: []Comment{}}
+	started := cursor == ""
+	seen := make(map[string]bool)
+
+	for _, p := range pages {
+		if !started && p.cursor == cursor {
+			started = true
+		}
+		if !started {
+			continue
+		}
+		for _, r := range p.replies {
+			if seen[r.ID] {
+				continue
+			}
+			seen[r.ID] = true
+			result.Replies = append(result.Replies, r)
+		}
+	}
+
+	if !started {
+		return nil, fmt.Errorf("未找到游标 %s 对应的子评论页，游标可能已失效", cursor)
+	}
+
+	if len(pages) > 0 {
+		last := pages[len(pages)-1]
+		result.HasMore = last.hasMore
+		if last.hasMore {
+			result.Cursor = last.next
+		}
+	}
+	return result, nil
+}
+
+// clickShowMoreReplies 点击顶级评论下的"展开 N 条回复"/"展开更多回复"按钮。
+// 返回 false 表示已无可点击的按钮。
+func clickShowMoreReplies(page *rod.Page, parentCommentID string) (bool, error) {
+	result, err := page.Eval(fmt.Sprintf(`() => {
+		const parentEl = document.getElementById('comment-%s');
+		if (!parentEl || !parentEl.parentElement) return '';
+		const showMore = parentEl.parentElement.querySelector('.show-more');
+		if (!showMore) return '';
+		showMore.scrollIntoView({block: 'center'});
+		showMore.click();
+		return showMore.textContent.trim();
+	}`, parentCommentID))
+	if err != nil {
+		return false, err
+	}
+	text := result.Value.String()
+	if text == "" {
+		return false, nil
+	}
+	logrus.Infof("子评论：点击了 '%s'", text)
+	return true, nil
+}
@@ -0,0 +1,76 @@
+package xiaohongshu
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindPreloadedReplies(t *testing.T) {
+	body := `{"code":0,"success":true,"data":{"has_more":false,"comments":[
+		{"id":"c1","content":"顶级","sub_comment_count":"3","sub_comment_cursor":"s2","sub_comment_has_more":true,
+		 "sub_comments":[{"id":"s1","content":"回复","user_info":{"user_id":"u1","nickname":"A"},
+		 "target_comment":{"id":"c1","user_info":{"user_id":"u0","nickname":"B"}}}]}
+	]}}`
+
+	first, parent, found := findPreloadedReplies([]commentAPIEntry{{body: body}}, "c1")
+	require.True(t, found)
+	require.Equal(t, "c1", parent.ID)
+	require.Equal(t, "顶级", parent.Content)
+	require.Equal(t, "3", parent.SubCommentCount)
+	require.Empty(t, parent.SubComments)
+	require.Equal(t, "s2", first.next)
+	require.True(t, first.hasMore)
+	require.Len(t, first.replies, 1)
+	require.Equal(t, "A", first.replies[0].UserInfo.Nickname)
+	require.NotNil(t, first.replies[0].TargetComment)
+	require.Equal(t, "u0", first.replies[0].TargetComment.UserInfo.UserID)
+
+	_, _, found = findPreloadedReplies([]commentAPIEntry{{body: body}}, "s1")
+	require.False(t, found, "子评论 ID 不应被当作顶级评论")
+}
+
+func TestAssembleReplyPages(t *testing.T) {
+	pages := []replyPage{
+		{cursor: "", replies: []Comment{{ID: "r1"}, {ID: "r2"}}, next: "p2", hasMore: true},
+		{cursor: "p2", replies: []Comment{{ID: "r2"}, {ID: "r3"}}, next: "p3", hasMore: true},
+		{cursor: "p3", replies: []Comment{{ID: "r4"}}, next: "p4", hasMore: true},
+	}
+
+	result, err := assembleReplyPages(pages, "")
+	require.NoError(t, err)
+	require.Len(t, result.Replies, 4)
+	require.True(t, result.HasMore)
+	require.Equal(t, "p4", result.Cursor)
+
+	result, err = assembleReplyPages(pages, "p3")
+	require.NoError(t, err)
+	require.Len(t, result.Replies, 1)
+	require.Equal(t, "r4", result.Replies[0].ID)
+
+	_, err = assembleReplyPages(pages, "unknown")
+	require.Error(t, err)
+
+	pages[2].hasMore = false
+	result, err = assembleReplyPages(pages, "")
+	require.NoError(t, err)
+	require.False(t, result.HasMore)
+	require.Empty(t, result.Cursor)
+}
+
+func TestNeedMoreReplyPages(t *testing.T) {
+	pages := []replyPage{{cursor: "", next: "p1", hasMore: true}}
+	for i := 1; i < maxReplyPages; i++ {
+		pages = append(pages, replyPage{cursor: fmt.Sprintf("p%d", i), next: fmt.Sprintf("p%d", i+1), hasMore: true})
+	}
+
+	require.False(t, needMoreReplyPages(pages, ""), "从第一页起已满 maxReplyPages 页")
+	require.True(t, needMoreReplyPages(pages, "p1"), "上限应从请求的 cursor 所在页开始计数")
+	require.True(t, needMoreReplyPages(pages, "p99"), "尚未走到 cursor 时应继续展开")
+
+	// 模拟上一次调用返回的 next cursor：继续展开到它之后仍能返回完整一批
+	next := pages[len(pages)-1].next
+	pages = append(pages, replyPage{cursor: next, next: "x", hasMore: true})
+	require.True(t, needMoreReplyPages(pages, next))
+}
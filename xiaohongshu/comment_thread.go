@@ -0,0 +1,67 @@
+package xiaohongshu
+
+import (
+	"context"
+	"fmt"
+)
+
+// noteSummaryDescLimit 笔记摘要中正文保留的最大字数
+const noteSummaryDescLimit = 500
+
+// NoteSummary 笔记摘要，回复评论时用来了解笔记在说什么
+type NoteSummary struct {
+	NoteID   string   `json:"note_id"`
+	Title    string   `json:"title"`
+	Desc     string   `json:"desc"` // 正文，超过 500 字时截断
+	Type     string   `json:"type"`
+	AuthorID string   `json:"author_id"`
+	Author   string   `json:"author"`
+	Tags     []string `json:"tags,omitempty"`
+	Time     int64    `json:"time"`
+}
+
+// CommentThreadResult 一条顶级评论的完整楼层及所在笔记
+type CommentThreadResult struct {
+	Note    NoteSummary           `json:"note"`
+	Replies *CommentRepliesResult `json:"replies"`
+}
+
+// GetCommentThread 获取评论所在楼层的顶级评论及其全部子评论，并在同一页面上读取笔记摘要。
+// commentID 可以是顶级评论或楼中楼回复，回复时从页面上找到其顶级评论（Replies.CommentID）；
+// 楼层超过 maxReplyPages 页时 Replies.HasMore 为 true。
+func (f *FeedDetailAction) GetCommentThread(ctx context.Context, feedID, xsecToken, commentID string) (*CommentThreadResult, error) {
+	replies, err := f.getCommentReplies(ctx, feedID, xsecToken, commentID, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	detail, err := f.extractFeedDetail(f.page.Context(ctx), feedID, xsecToken)
+	if err != nil {
+		return nil, fmt.Errorf("读取笔记内容失败: %w", err)
+	}
+
+	return &CommentThreadResult{
+		Note:    summarizeNote(&detail.Note),
+		Replies: replies,
+	}, nil
+}
+
+// summarizeNote 从笔记详情中取出回复评论需要的字段
+func summarizeNote(note *FeedDetail) NoteSummary {
+	summary := NoteSummary{
+		NoteID:   note.NoteID,
+		Title:    note.Title,
+		Desc:     note.Desc,
+		Type:     note.Type,
+		AuthorID: note.User.UserID,
+		Author:   note.User.Nickname,
+		Time:     note.Time,
+	}
+	if desc := []rune(note.Desc); len(desc) > noteSummaryDescLimit {
+		summary.Desc = string(desc[:noteSummaryDescLimit]) + "..."
+	}
+	for _, tag := range note.TagList {
+		summary.Tags = append(summary.Tags, tag.Name)
+	}
+	return summary
+}
@@ -0,0 +1,30 @@
+package xiaohongshu
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeNote(t *testing.T) {
+	note := &FeedDetail{
+		NoteID:  "n1",
+		Title:   "周末露营",
+		Desc:    "装备清单 #露营[话题]#",
+		Type:    "normal",
+		User:    User{UserID: "u1", Nickname: "作者"},
+		TagList: []NoteTag{{Name: "露营"}, {Name: "周末"}},
+	}
+
+	summary := summarizeNote(note)
+	assert.Equal(t, "n1", summary.NoteID)
+	assert.Equal(t, "作者", summary.Author)
+	assert.Equal(t, "u1", summary.AuthorID)
+	assert.Equal(t, note.Desc, summary.Desc)
+	assert.Equal(t, []string{"露营", "周末"}, summary.Tags)
+
+	note.Desc = strings.Repeat("字", noteSummaryDescLimit+10)
+	summary = summarizeNote(note)
+	assert.Equal(t, strings.Repeat("字", noteSummaryDescLimit)+"...", summary.Desc)
+}
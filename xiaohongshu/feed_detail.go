@@ -130,7 +130,7 @@ func (f *FeedDetailAction) GetFeedDetailWithConfig(ctx context.Context, feedID,
 		}
 	}
 
-	return f.extractFeedDetail(page, feedID)
+	return f.extractFeedDetail(page, feedID, xsecToken)
 }
 
 // ========== 评论加载器 ==========
@@ -769,6 +769,8 @@ func checkEndContainer(page *rod.Page) bool {
 
 // ========== 页面检查 ==========
 
+// checkPageAccessible 检查笔记详情页或用户主页是否显示了错误提示，
+// 不可访问时返回 *errors.NoteInaccessibleError，原因由提示文本归类。
 func checkPageAccessible(page *rod.Page) error {
 	time.Sleep(500 * time.Millisecond)
 
@@ -786,40 +788,19 @@ func checkPageAccessible(page *rod.Page) error {
 		return nil
 	}
 
-	// 检查关键词
-	keywords := []string{
-		"当前笔记暂时无法浏览",
-		"该内容因违规已被删除",
-		"该笔记已被删除",
-		"内容不存在",
-		"笔记不存在",
-		"已失效",
-		"私密笔记",
-		"仅作者可见",
-		"因用户设置，你无法查看",
-		"因违规无法查看",
-	}
-
-	for _, kw := range keywords {
-		if strings.Contains(text, kw) {
-			logrus.Warnf("笔记不可访问: %s", kw)
-			return fmt.Errorf("笔记不可访问: %s", kw)
-		}
-	}
-
-	// 如果有文本但不匹配关键词，返回未知错误
 	trimmedText := strings.TrimSpace(text)
-	if trimmedText != "" {
-		logrus.Warnf("笔记不可访问（未知原因）: %s", trimmedText)
-		return fmt.Errorf("笔记不可访问: %s", trimmedText)
+	if trimmedText == "" {
+		return nil
 	}
 
-	return nil
+	accessErr := errors.NewNoteInaccessibleError(trimmedText)
+	logrus.Warnf("笔记不可访问（%s）: %s", accessErr.Reason, trimmedText)
+	return accessErr
 }
 
 // ========== 数据提取 ==========
 
-func (f *FeedDetailAction) extractFeedDetail(page *rod.Page, feedID string) (*FeedDetailResponse, error) {
+func (f *FeedDetailAction) extractFeedDetail(page *rod.Page, feedID, xsecToken string) (*FeedDetailResponse, error) {
 	var result string
 
 	// 使用retry-go来处理可能的DOM查询失败。
@@ -873,12 +854,42 @@ func (f *FeedDetailAction) extractFeedDetail(page *rod.Page, feedID string) (*Fe
 		return nil, fmt.Errorf("feed %s not found in noteDetailMap", feedID)
 	}
 
+	note := noteDetail.Note
+	if note.XsecToken == "" {
+		note.XsecToken = xsecToken
+	}
+	enrichFeedDetail(&note)
+
 	return &FeedDetailResponse{
-		Note:     noteDetail.Note,
+		Note:     note,
 		Comments: noteDetail.Comments,
 	}, nil
 }
 
+// descTopicRegex 匹配正文中的话题标记，如 "#旅行[话题]#"
+var descTopicRegex = regexp.MustCompile(`#([^#\[\]\s]+)\[话题\]#`)
+
+// enrichFeedDetail 补充页面数据中缺失或需要推导的字段：
+// 旧笔记的 tagList 可能为空，此时从正文的话题标记中解析；分享链接按笔记 ID 拼接。
+func enrichFeedDetail(note *FeedDetail) {
+	if len(note.TagList) == 0 {
+		for _, m := range descTopicRegex.FindAllStringSubmatch(note.Desc, -1) {
+			note.TagList = append(note.TagList, NoteTag{Name: m[1], Type: "topic"})
+		}
+	}
+
+	if !note.ShareInfo.UnShare && note.NoteID != "" {
+		note.ShareLink = makeShareLink(note.NoteID, note.XsecToken)
+	}
+}
+
+func makeShareLink(feedID, xsecToken string) string {
+	if xsecToken == "" {
+		return fmt.Sprintf("https://www.xiaohongshu.com/discovery/item/%s", feedID)
+	}
+	return fmt.Sprintf("https://www.xiaohongshu.com/discovery/item/%s?xsec_token=%s&xsec_source=pc_share", feedID, xsecToken)
+}
+
 func makeFeedDetailURL(feedID, xsecToken string) string {
 	return fmt.Sprintf("https://www.xiaohongshu.com/explore/%s?xsec_token=%s&xsec_source=pc_feed", feedID, xsecToken)
 }
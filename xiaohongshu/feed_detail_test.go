@@ -0,0 +1,34 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnrichFeedDetail(t *testing.T) {
+	note := &FeedDetail{
+		NoteID:    "abc",
+		XsecToken: "tok",
+		Desc:      "周末去爬山 #徒步[话题]# #户外 运动[话题]# #秋天[话题]#",
+	}
+	enrichFeedDetail(note)
+
+	assert.Equal(t, []NoteTag{
+		{Name: "徒步", Type: "topic"},
+		{Name: "秋天", Type: "topic"},
+	}, note.TagList)
+	assert.Equal(t, "https://www.xiaohongshu.com/discovery/item/abc?xsec_token=tok&xsec_source=pc_share", note.ShareLink)
+
+	// 页面已有 tagList 时不覆盖；关闭分享时不生成链接
+	note = &FeedDetail{
+		NoteID:    "abc",
+		Desc:      "#徒步[话题]#",
+		TagList:   []NoteTag{{ID: "t1", Name: "徒步", Type: "topic"}},
+		ShareInfo: NoteShareInfo{UnShare: true},
+	}
+	enrichFeedDetail(note)
+
+	assert.Equal(t, "t1", note.TagList[0].ID)
+	assert.Empty(t, note.ShareLink)
+}
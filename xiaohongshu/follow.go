@@ -0,0 +1,187 @@
+package xiaohongshu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+)
+
+// SelectorFollowButton 用户主页的关注按钮
+const SelectorFollowButton = ".user-info .follow-button, .info-part .follow-button, .user-info button.follow"
+
+// followStatus 当前账号与目标用户的关注关系（__INITIAL_STATE__ 中的 fstatus）
+type followStatus string
+
+const (
+	followStatusNone    followStatus = "none"    // 互不关注
+	followStatusFollows followStatus = "follows" // 我关注了对方
+	followStatusFans    followStatus = "fans"    // 对方关注了我（按钮显示"回关"）
+	followStatusBoth    followStatus = "both"    // 互相关注
+)
+
+// following 当前账号是否已关注对方
+func (s followStatus) following() bool {
+	return s == followStatusFollows || s == followStatusBoth
+}
+
+// followStatusFromButtonText 根据关注按钮文字推断关注关系，忽略空白和图标前的 "+"
+func followStatusFromButtonText(text string) (followStatus, bool) {
+	text = strings.TrimPrefix(strings.Join(strings.Fields(text), ""), "+")
+	switch text {
+	case "关注":
+		return followStatusNone, true
+	case "回关":
+		return followStatusFans, true
+	case "已关注":
+		return followStatusFollows, true
+	case "互相关注":
+		return followStatusBoth, true
+	}
+	return "", false
+}
+
+// FollowAction 负责关注/取消关注用户
+type FollowAction struct {
+	page *rod.Page
+}
+
+// NewFollowAction 创建关注动作
+func NewFollowAction(page *rod.Page) *FollowAction {
+	return &FollowAction{page: page}
+}
+
+// Follow 关注指定用户，已关注时直接返回
+func (a *FollowAction) Follow(ctx context.Context, userID, xsecToken string) error {
+	return a.perform(ctx, userID, xsecToken, true)
+}
+
+// Unfollow 取消关注指定用户，未关注时直接返回
+func (a *FollowAction) Unfollow(ctx context.Context, userID, xsecToken string) error {
+	return a.perform(ctx, userID, xsecToken, false)
+}
+
+func (a *FollowAction) perform(ctx context.Context, userID, xsecToken string, targetFollowing bool) error {
+	actionType := "关注"
+	if !targetFollowing {
+		actionType = "取消关注"
+	}
+
+	page := a.page.Context(ctx).Timeout(2 * time.Minute)
+	url := makeUserProfileURL(userID, xsecToken)
+	logrus.Infof("打开用户主页%s: %s", actionType, url)
+
+	page.MustNavigate(url)
+	page.MustWaitDOMStable()
+	time.Sleep(1 * time.Second)
+
+	if err := checkPageAccessible(page); err != nil {
+		return err
+	}
+
+	status, err := a.getInitialFollowStatus(page)
+	if err != nil {
+		return fmt.Errorf("读取关注状态失败: %w", err)
+	}
+	if status.following() == targetFollowing {
+		logrus.Infof("用户 %s 已是%s状态（%s），跳过点击", userID, actionType, status)
+		return nil
+	}
+
+	// 与点赞一致：点击后校验状态，未变化时再点一次
+	for attempt := 1; attempt <= 2; attempt++ {
+		if err := a.clickFollowButton(page, targetFollowing); err != nil {
+			return err
+		}
+		time.Sleep(2 * time.Second)
+
+		// 点击后只认按钮文字：fstatus 是页面加载时的旧状态，按它再点可能把已经成功的操作点回去；
+		// 读不到状态时同样不能再点
+		status, err = a.getButtonFollowStatus(page)
+		if err != nil {
+			return fmt.Errorf("%s后无法确认关注状态: %w", actionType, err)
+		}
+		if status.following() == targetFollowing {
+			logrus.Infof("用户 %s %s成功（%s）", userID, actionType, status)
+			return nil
+		}
+		logrus.Warnf("用户 %s 第 %d 次%s后状态未变化（%s）", userID, attempt, actionType, status)
+	}
+
+	return fmt.Errorf("%s失败：点击后关注状态未变化", actionType)
+}
+
+// clickFollowButton 点击关注按钮；取消关注时处理"确定不再关注"的确认弹窗
+func (a *FollowAction) clickFollowButton(page *rod.Page, targetFollowing bool) error {
+	btn, err := page.Timeout(10 * time.Second).Element(SelectorFollowButton)
+	if err != nil {
+		return fmt.Errorf("未找到关注按钮，可能是自己的主页: %w", err)
+	}
+	btn = btn.CancelTimeout()
+	if err := btn.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return fmt.Errorf("点击关注按钮失败: %w", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+
+	if !targetFollowing {
+		for _, text := range []string{"不再关注", "取消关注", "确定", "确认"} {
+			confirm, err := findVisibleElementByText(page, text, ".reds-modal", ".modal", "[role=dialog]", ".dialog", ".reds-popover")
+			if err != nil {
+				continue
+			}
+			if err := confirm.Click(proto.InputMouseButtonLeft, 1); err != nil {
+				return fmt.Errorf("确认取消关注失败: %w", err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// getButtonFollowStatus 根据关注按钮文字判断关注关系，点击后按钮文字会立即更新。
+// 找不到按钮或文字无法识别时返回错误
+func (a *FollowAction) getButtonFollowStatus(page *rod.Page) (followStatus, error) {
+	btn, err := page.Timeout(5 * time.Second).Element(SelectorFollowButton)
+	if err != nil {
+		return "", fmt.Errorf("未找到关注按钮: %w", err)
+	}
+	text, err := btn.CancelTimeout().Text()
+	if err != nil {
+		return "", fmt.Errorf("读取关注按钮文字失败: %w", err)
+	}
+	status, ok := followStatusFromButtonText(text)
+	if !ok {
+		return "", fmt.Errorf("无法识别关注按钮文字: %q", text)
+	}
+	return status, nil
+}
+
+// getInitialFollowStatus 点击前读取关注关系：优先根据按钮文字判断，
+// 无法识别时读取 __INITIAL_STATE__ 中的 fstatus（页面加载时的状态，点击后不会更新，只能在点击前使用）
+func (a *FollowAction) getInitialFollowStatus(page *rod.Page) (followStatus, error) {
+	status, err := a.getButtonFollowStatus(page)
+	if err == nil {
+		return status, nil
+	}
+	logrus.Warnf("%v，改读 fstatus", err)
+
+	result, err := page.Eval(`() => {
+		const state = window.__INITIAL_STATE__;
+		if (!state || !state.user || !state.user.userPageData) return "";
+		const pageData = state.user.userPageData;
+		const data = pageData.value !== undefined ? pageData.value : pageData._value;
+		return (data && data.extraInfo && data.extraInfo.fstatus) || "";
+	}`)
+	if err != nil {
+		return "", fmt.Errorf("读取 fstatus 失败: %w", err)
+	}
+	status = followStatus(result.Value.String())
+	if status == "" {
+		return "", fmt.Errorf("页面上没有关注按钮和 fstatus，可能是自己的主页或未登录")
+	}
+	return status, nil
+}
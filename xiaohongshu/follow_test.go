@@ -0,0 +1,31 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollowStatusFromButtonText(t *testing.T) {
+	tests := []struct {
+		text      string
+		want      followStatus
+		following bool
+	}{
+		{"关注", followStatusNone, false},
+		{" + 关注 ", followStatusNone, false},
+		{"回关", followStatusFans, false},
+		{"已关注", followStatusFollows, true},
+		{"互相\n关注", followStatusBoth, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := followStatusFromButtonText(tt.text)
+		assert.True(t, ok, tt.text)
+		assert.Equal(t, tt.want, got, tt.text)
+		assert.Equal(t, tt.following, got.following(), tt.text)
+	}
+
+	_, ok := followStatusFromButtonText("发消息")
+	assert.False(t, ok)
+}
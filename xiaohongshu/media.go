@@ -0,0 +1,183 @@
+package xiaohongshu
+
+import (
+	"net/url"
+	"strings"
+)
+
+// xhsImageCDN 不带处理参数的图片 CDN，拼接 fileId / traceId 后即为原图
+const xhsImageCDN = "https://sns-img-qc.xhscdn.com/"
+
+// NoteMedia 笔记中可直接使用的媒体资源
+type NoteMedia struct {
+	Images []MediaImage  `json:"images,omitempty"`
+	Videos []MediaStream `json:"videos,omitempty"` // 视频笔记的全部流，按编码和清晰度排列
+	Covers []MediaCover  `json:"covers,omitempty"` // 视频封面 / 首帧
+	// 仅 download_media 模式下有：下载失败的资源及原因
+	DownloadErrors []string `json:"download_errors,omitempty"`
+}
+
+// MediaImage 单张图片
+type MediaImage struct {
+	Index       int    `json:"index"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	OriginalURL string `json:"original_url"` // 原图（无压缩、无水印处理参数）
+	DefaultURL  string `json:"default_url"`  // 详情页展示图
+	PreviewURL  string `json:"preview_url,omitempty"`
+	LocalPath   string `json:"local_path,omitempty"`
+
+	// 实况图视频，仅 live photo 有
+	LivePhoto          bool   `json:"live_photo,omitempty"`
+	LivePhotoURL       string `json:"live_photo_url,omitempty"`
+	LivePhotoLocalPath string `json:"live_photo_local_path,omitempty"`
+}
+
+// MediaStream 单路视频流
+type MediaStream struct {
+	Codec      string   `json:"codec"` // h264 / h265 / h266 / av1
+	Width      int      `json:"width"`
+	Height     int      `json:"height"`
+	Bitrate    int      `json:"bitrate"` // 平均码率 bps
+	Fps        int      `json:"fps,omitempty"`
+	DurationMs int      `json:"duration_ms,omitempty"`
+	Size       int64    `json:"size,omitempty"`
+	Quality    string   `json:"quality,omitempty"`
+	MasterURL  string   `json:"master_url"`
+	BackupURLs []string `json:"backup_urls,omitempty"`
+	LocalPath  string   `json:"local_path,omitempty"`
+}
+
+// MediaCover 视频封面帧
+type MediaCover struct {
+	Kind      string `json:"kind"` // cover / first_frame / thumbnail
+	URL       string `json:"url"`
+	LocalPath string `json:"local_path,omitempty"`
+}
+
+// ExtractNoteMedia 从笔记详情中整理出图片原图、实况图视频、视频流和封面帧
+func ExtractNoteMedia(note *FeedDetail) *NoteMedia {
+	media := &NoteMedia{}
+
+	for i, img := range note.ImageList {
+		item := MediaImage{
+			Index:       i,
+			Width:       img.Width,
+			Height:      img.Height,
+			DefaultURL:  img.URLDefault,
+			PreviewURL:  img.URLPre,
+			OriginalURL: originalImageURL(img.URLDefault),
+			LivePhoto:   img.LivePhoto,
+		}
+		if item.DefaultURL == "" {
+			for _, info := range img.InfoList {
+				if info.ImageScene == "WB_DFT" {
+					item.DefaultURL = info.URL
+					item.OriginalURL = originalImageURL(info.URL)
+				}
+			}
+		}
+		if img.Stream != nil {
+			if streams := collectStreams(img.Stream); len(streams) > 0 {
+				item.LivePhoto = true
+				item.LivePhotoURL = streams[0].MasterURL
+			}
+		}
+		media.Images = append(media.Images, item)
+	}
+
+	if note.Video == nil {
+		return media
+	}
+
+	media.Videos = collectStreams(&note.Video.Media.Stream)
+
+	// 视频笔记的 imageList 第一张即封面
+	if len(media.Images) > 0 {
+		media.Covers = append(media.Covers, MediaCover{Kind: "cover", URL: media.Images[0].OriginalURL})
+	}
+	if id := note.Video.Image.FirstFrameFileID; id != "" {
+		media.Covers = append(media.Covers, MediaCover{Kind: "first_frame", URL: xhsImageCDN + id})
+	}
+	if id := note.Video.Image.ThumbnailFileID; id != "" {
+		media.Covers = append(media.Covers, MediaCover{Kind: "thumbnail", URL: xhsImageCDN + id})
+	}
+	return media
+}
+
+// BestVideo 返回最适合下载的视频流：优先兼容性最好的 h264，其次按分辨率取最高
+func (m *NoteMedia) BestVideo() *MediaStream {
+	var best *MediaStream
+	for i := range m.Videos {
+		s := &m.Videos[i]
+		if s.MasterURL == "" {
+			continue
+		}
+		if best == nil ||
+			(s.Codec == "h264" && best.Codec != "h264") ||
+			(s.Codec == best.Codec && s.Width*s.Height > best.Width*best.Height) {
+			best = s
+		}
+	}
+	return best
+}
+
+// collectStreams 将按编码分组的流展开为列表，跳过没有地址的流
+func collectStreams(set *VideoStreamSet) []MediaStream {
+	groups := []struct {
+		codec   string
+		streams []VideoStreamInfo
+	}{
+		{"h264", set.H264},
+		{"h265", set.H265},
+		{"h266", set.H266},
+		{"av1", set.AV1},
+	}
+
+	var streams []MediaStream
+	for _, g := range groups {
+		for _, s := range g.streams {
+			if s.MasterURL == "" {
+				continue
+			}
+			bitrate := s.AvgBitrate
+			if bitrate == 0 {
+				bitrate = s.VideoBitrate
+			}
+			streams = append(streams, MediaStream{
+				Codec:      g.codec,
+				Width:      s.Width,
+				Height:     s.Height,
+				Bitrate:    bitrate,
+				Fps:        s.Fps,
+				DurationMs: s.Duration,
+				Size:       s.Size,
+				Quality:    s.QualityType,
+				MasterURL:  s.MasterURL,
+				BackupURLs: s.BackupURLs,
+			})
+		}
+	}
+	return streams
+}
+
+// originalImageURL 将详情页图片地址还原为原图地址。
+// 详情页地址形如 http://sns-webpic-qc.xhscdn.com/{时间戳}/{签名}/{traceId}!nd_dft_wlteh_webp_3，
+// 去掉签名前缀和 "!" 之后的处理参数，拼到无处理的 CDN 上即得原图。
+func originalImageURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	path := strings.TrimPrefix(u.Path, "/")
+	if i := strings.Index(path, "!"); i >= 0 {
+		path = path[:i]
+	}
+
+	parts := strings.SplitN(path, "/", 3)
+	if len(parts) < 3 || parts[2] == "" {
+		return rawURL
+	}
+	return xhsImageCDN + parts[2]
+}
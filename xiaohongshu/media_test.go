@@ -0,0 +1,75 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOriginalImageURL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "详情页展示图",
+			in:   "http://sns-webpic-qc.xhscdn.com/202410181234/5f1e2d/1040g2sg31abc!nd_dft_wlteh_webp_3",
+			want: "https://sns-img-qc.xhscdn.com/1040g2sg31abc",
+		},
+		{
+			name: "带目录的 traceId",
+			in:   "https://sns-webpic-qc.xhscdn.com/202410181234/5f1e2d/spectrum/1040g0k0abc!nd_prv_wlteh_webp_3",
+			want: "https://sns-img-qc.xhscdn.com/spectrum/1040g0k0abc",
+		},
+		{
+			name: "无法识别的地址原样返回",
+			in:   "https://example.com/a.jpg",
+			want: "https://example.com/a.jpg",
+		},
+		{name: "空地址", in: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, originalImageURL(tt.in))
+		})
+	}
+}
+
+func TestExtractNoteMedia(t *testing.T) {
+	note := &FeedDetail{
+		ImageList: []DetailImageInfo{
+			{
+				URLDefault: "http://sns-webpic-qc.xhscdn.com/1/2/cover!nd_dft",
+				LivePhoto:  true,
+				Stream:     &VideoStreamSet{H265: []VideoStreamInfo{{MasterURL: "https://v/live.mp4"}}},
+			},
+		},
+		Video: &DetailVideo{},
+	}
+	note.Video.Media.Stream = VideoStreamSet{
+		H264: []VideoStreamInfo{{MasterURL: "https://v/720.mp4", Width: 720, Height: 1280, AvgBitrate: 1000}},
+		H265: []VideoStreamInfo{
+			{MasterURL: "https://v/1080.mp4", Width: 1080, Height: 1920},
+			{Width: 1440, Height: 2560},
+		},
+	}
+	note.Video.Image.FirstFrameFileID = "frame"
+
+	media := ExtractNoteMedia(note)
+
+	assert.Len(t, media.Images, 1)
+	assert.Equal(t, "https://sns-img-qc.xhscdn.com/cover", media.Images[0].OriginalURL)
+	assert.Equal(t, "https://v/live.mp4", media.Images[0].LivePhotoURL)
+
+	// 没有地址的流被跳过
+	assert.Len(t, media.Videos, 2)
+	assert.Equal(t, 1000, media.Videos[0].Bitrate)
+	assert.Equal(t, "h264", media.BestVideo().Codec)
+
+	assert.Equal(t, []MediaCover{
+		{Kind: "cover", URL: "https://sns-img-qc.xhscdn.com/cover"},
+		{Kind: "first_frame", URL: "https://sns-img-qc.xhscdn.com/frame"},
+	}, media.Covers)
+}
@@ -0,0 +1,117 @@
+package xiaohongshu
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/sirupsen/logrus"
+)
+
+// NotificationCategory 通知页的标签页
+type NotificationCategory string
+
+const (
+	NotificationCategoryMentions    NotificationCategory = "mentions"    // 评论和@
+	NotificationCategoryLikes       NotificationCategory = "likes"       // 赞和收藏
+	NotificationCategoryConnections NotificationCategory = "connections" // 新增关注
+)
+
+// notificationCategories 标签页在页面上的文字和对应的接口
+var notificationCategories = map[NotificationCategory]struct {
+	label      string
+	apiPattern string
+}{
+	NotificationCategoryMentions:    {"评论和@", "*/api/sns/web/v1/you/mentions*"},
+	NotificationCategoryLikes:       {"赞和收藏", "*/api/sns/web/v1/you/likes*"},
+	NotificationCategoryConnections: {"新增关注", "*/api/sns/web/v1/you/connections*"},
+}
+
+// ParseNotificationCategory 解析通知分类，空字符串视为评论和@
+func ParseNotificationCategory(s string) (NotificationCategory, error) {
+	if s == "" {
+		return NotificationCategoryMentions, nil
+	}
+	category := NotificationCategory(s)
+	if _, ok := notificationCategories[category]; !ok {
+		return "", fmt.Errorf("无效的通知分类: %q，合法值：mentions / likes / connections", s)
+	}
+	return category, nil
+}
+
+const (
+	// RelationLikedMyNote 有人赞了你的笔记
+	RelationLikedMyNote NotificationRelationType = "liked_my_note"
+	// RelationCollectedMyNote 有人收藏了你的笔记
+	RelationCollectedMyNote NotificationRelationType = "collected_my_note"
+	// RelationLikedMyComment 有人赞了你的评论
+	RelationLikedMyComment NotificationRelationType = "liked_my_comment"
+	// RelationNewFollower 有人关注了你
+	RelationNewFollower NotificationRelationType = "new_follower"
+)
+
+// notificationRelation 根据标签页和 API 中的 type 判断通知与当前用户的关系，不支持的 type 返回 false
+func notificationRelation(category NotificationCategory, msgType, commentContent string) (NotificationRelationType, bool) {
+	switch category {
+	case NotificationCategoryLikes:
+		switch {
+		case strings.HasPrefix(msgType, "collect/"):
+			return RelationCollectedMyNote, true
+		case msgType == "like/comment":
+			return RelationLikedMyComment, true
+		case strings.HasPrefix(msgType, "like/"):
+			return RelationLikedMyNote, true
+		}
+		return "", false
+	case NotificationCategoryConnections:
+		// 新增关注标签页只有关注通知，type 取值不稳定（如 follow/you），不做区分
+		return RelationNewFollower, true
+	}
+
+	switch msgType {
+	case "comment/item":
+		// 有人直接评论了你的笔记
+		return RelationCommentOnMyNote, true
+	case "comment/comment":
+		// 启发式判断：评论内容以 @ 开头 → 用户在你的评论下 @了其他人
+		// 否则 → 用户直接回复了你的评论
+		if len(commentContent) > 0 && commentContent[0] == '@' {
+			return RelationAtOthersUnderMyComment, true
+		}
+		return RelationReplyToMyComment, true
+	case "mention/comment":
+		// 有人在评论中直接 @了你（不是在你的评论下，而是在任意评论里 @你）
+		return RelationMentionedMe, true
+	}
+	return "", false
+}
+
+// switchNotificationTab 点击通知页上的标签页，评论和@是默认标签，不需要切换
+func switchNotificationTab(page *rod.Page, category NotificationCategory) error {
+	if category == NotificationCategoryMentions {
+		return nil
+	}
+	label := notificationCategories[category].label
+	logrus.Infof("通知：切换到%s标签", label)
+
+	clicked, err := page.Eval(`(label) => {
+		const tabs = document.querySelectorAll('.reds-tabs-list .reds-tab-item, [class*="tabs"] [class*="tab-item"], [class*="tabs"] [class*="tab"]');
+		for (const t of tabs) {
+			if ((t.innerText || '').trim().startsWith(label)) {
+				t.click();
+				return true;
+			}
+		}
+		return false;
+	}`, label)
+	if err != nil {
+		return fmt.Errorf("切换到%s标签失败: %w", label, err)
+	}
+	if !clicked.Value.Bool() {
+		return fmt.Errorf("通知页上没有%s标签", label)
+	}
+	page.MustWaitDOMStable()
+	time.Sleep(1 * time.Second)
+	return nil
+}
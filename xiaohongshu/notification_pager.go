@@ -0,0 +1,217 @@
+package xiaohongshu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// notificationMaxPages 单次会话从请求的 cursor 起最多翻多少页（每页 20 条）
+	notificationMaxPages = 10
+	// notificationPageWait 等待某个 cursor 对应的 API 响应的最长时间
+	notificationPageWait = 10 * time.Second
+)
+
+// NotificationGapError 翻页链在某一页断开：该页没能按上一页的 NextCursor 接上，
+// 从 Cursor 开始（含）之后的通知都没有取到
+type NotificationGapError struct {
+	Cursor string // 没取到的那一页的请求 cursor，可传给 get_notifications 续翻
+	Pages  int    // 断开前已取到的页数
+	Reason string
+}
+
+func (e *NotificationGapError) Error() string {
+	return fmt.Sprintf("通知翻页在第 %d 页后中断（cursor=%s）: %s", e.Pages, e.Cursor, e.Reason)
+}
+
+// walkNotificationChain 从第一页（cursor 为空）开始，严格按每页返回的 NextCursor 逐页获取，
+// 每取到一页调用一次 visit，visit 返回 false 时停止。
+// maxPages 从请求 cursor 为 start 的那一页起计数（start 为空即第一页）：之前的页只是为了沿链翻到 start，
+// 不计入上限，由会话超时兜底，这样上一次达到上限时给出的 cursor 总能接着翻。
+// 第一页失败时原样返回错误；之后任何一页接不上（获取失败、has_more 却没有 next_cursor、
+// cursor 重复出现、达到 maxPages 仍未结束）都返回 *NotificationGapError，
+// 调用方据此知道结果不完整，而不是把已取到的部分当成全部。
+func walkNotificationChain(
+	fetch func(cursor string) (*NotificationsResult, error),
+	visit func(cursor string, page *NotificationsResult) bool,
+	start string,
+	maxPages int,
+) error {
+	cursor := ""
+	seen := map[string]bool{"": true}
+	counted := 0 // 从 start 起已取到的页数
+
+	for pages := 0; ; pages++ {
+		if cursor == start || counted > 0 {
+			if counted == maxPages {
+				return &NotificationGapError{Cursor: cursor, Pages: pages, Reason: fmt.Sprintf("已达翻页上限 %d 页", maxPages)}
+			}
+			counted++
+		}
+
+		page, err := fetch(cursor)
+		if err != nil {
+			if pages == 0 {
+				return err
+			}
+			var gap *NotificationGapError
+			if errors.As(err, &gap) {
+				gap.Pages = pages
+				return gap
+			}
+			return &NotificationGapError{Cursor: cursor, Pages: pages, Reason: err.Error()}
+		}
+
+		if !visit(cursor, page) || !page.HasMore {
+			return nil
+		}
+
+		next := page.NextCursor
+		if next == "" {
+			return &NotificationGapError{Cursor: cursor, Pages: pages + 1, Reason: "has_more=true 但响应中没有 next_cursor"}
+		}
+		if seen[next] {
+			return &NotificationGapError{Cursor: next, Pages: pages + 1, Reason: "next_cursor 重复出现，翻页链成环"}
+		}
+		seen[next] = true
+		cursor = next
+	}
+}
+
+// notificationPager 在一个通知页会话里按 cursor 取页。
+// 拦截到的 API 响应按请求 URL 中的 cursor 参数索引，取某一页时只认请求 cursor 完全一致的响应。
+type notificationPager struct {
+	page     *rod.Page
+	category NotificationCategory
+	cancel   context.CancelFunc
+	router   *rod.HijackRouter
+
+	mu        sync.Mutex
+	responses map[string]string // 请求 cursor -> 响应体
+	consumed  map[string]bool
+}
+
+// openNotificationPager 打开通知页并切到指定标签，第一页的请求会在打开过程中被拦截到。
+// 调用方必须 Close。
+func (n *NotificationsAction) openNotificationPager(ctx context.Context, category NotificationCategory) (*notificationPager, error) {
+	// 使用独立的内部 context，避免被 mcporter 的短超时 context 提前取消。
+	// 外部 ctx 仍作为父 context，若调用方主动取消则内部也会取消。
+	innerCtx, cancel := context.WithTimeout(ctx, notificationsTimeout)
+
+	p := &notificationPager{
+		page:      n.page.Context(innerCtx),
+		category:  category,
+		cancel:    cancel,
+		responses: make(map[string]string),
+		consumed:  make(map[string]bool),
+	}
+
+	p.router = p.page.HijackRequests()
+	p.router.MustAdd(notificationCategories[category].apiPattern, func(ctx *rod.Hijack) {
+		ctx.MustLoadResponse()
+		c := ctx.Request.URL().Query().Get("cursor")
+		body := ctx.Response.Body()
+		// 第一个响应可能为空，只保留有内容的
+		if body == "" {
+			return
+		}
+		p.mu.Lock()
+		p.responses[c] = body
+		p.mu.Unlock()
+	})
+	go p.router.Run()
+
+	// 先访问主页让 SPA 完全初始化，再强制 reload 通知页面。
+	// 不能直接从主页 SPA 路由切换到 /notification（SPA 会复用内存中的旧数据不重新请求 API）；
+	// 也不能用 about:blank 冷启动（SPA 未初始化，DOM stable 时 JS 还未执行，API 请求来不及发出）。
+	// 正确做法：主页 warmup → 导航到通知页 → Reload 强制浏览器重新发起所有请求。
+	logrus.Info("通知：主页 warmup...")
+	p.page.MustNavigate("https://www.xiaohongshu.com/")
+	p.page.MustWaitDOMStable()
+	time.Sleep(1 * time.Second)
+
+	logrus.Info("通知：导航到通知页并强制 reload...")
+	p.page.MustNavigate("https://www.xiaohongshu.com/notification")
+	p.page.MustWaitDOMStable()
+	// Reload 强制浏览器丢弃 SPA 内存缓存，重新发起通知 API 请求
+	p.page.MustReload()
+	p.page.MustWaitDOMStable()
+
+	// 其他标签页的接口在点击标签后才会请求
+	if err := switchNotificationTab(p.page, category); err != nil {
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// Close 停止拦截并释放内部 context
+func (p *notificationPager) Close() {
+	_ = p.router.Stop()
+	p.cancel()
+}
+
+// fetch 获取请求 cursor 为 cursor 的那一页。非第一页通过滚动到底部触发加载，
+// 页面实际请求的 cursor 与期望不一致时返回 *NotificationGapError。
+func (p *notificationPager) fetch(cursor string) (*NotificationsResult, error) {
+	deadline := time.Now().Add(notificationPageWait)
+	for i := 0; ; i++ {
+		p.mu.Lock()
+		body, ok := p.responses[cursor]
+		if ok {
+			p.consumed[cursor] = true
+		}
+		p.mu.Unlock()
+		if ok {
+			return parseNotificationsResponse(p.category, body)
+		}
+
+		if err := p.page.GetContext().Err(); err != nil {
+			return nil, fmt.Errorf("等待通知页超时: %w", err)
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+		// 滚动到底部触发加载更多；SPA 偶尔不响应第一次滚动，每 3 秒重试一次
+		if cursor != "" && i%3 == 0 {
+			if _, err := p.page.Eval(`() => window.scrollTo(0, document.body.scrollHeight)`); err != nil {
+				logrus.Warnf("通知：滚动加载失败: %v", err)
+			}
+		}
+		time.Sleep(1 * time.Second)
+		logrus.Infof("通知：等待 cursor=%q 的 API 响应... (%ds)", cursor, i+1)
+	}
+
+	if unexpected := p.unexpectedCursors(cursor); len(unexpected) > 0 {
+		return nil, &NotificationGapError{
+			Cursor: cursor,
+			Reason: fmt.Sprintf("页面请求的 cursor 为 %q，与上一页的 next_cursor 不连续", unexpected),
+		}
+	}
+	if cursor == "" {
+		return nil, fmt.Errorf("无法获取通知数据，请确认已登录")
+	}
+	return nil, fmt.Errorf("等待 cursor=%s 的通知页超时", cursor)
+}
+
+// unexpectedCursors 拦截到但既不是期望的、也没被取走过的响应的 cursor
+func (p *notificationPager) unexpectedCursors(expected string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var cursors []string
+	for c := range p.responses {
+		if c != expected && !p.consumed[c] {
+			cursors = append(cursors, c)
+		}
+	}
+	sort.Strings(cursors)
+	return cursors
+}
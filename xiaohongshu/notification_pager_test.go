@@ -0,0 +1,158 @@
+package xiaohongshu
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNotificationPages 按请求 cursor 返回预置的页，记录请求顺序
+func fakeNotificationPages(pages map[string]*NotificationsResult, requested *[]string) func(string) (*NotificationsResult, error) {
+	return func(cursor string) (*NotificationsResult, error) {
+		*requested = append(*requested, cursor)
+		page, ok := pages[cursor]
+		if !ok {
+			return nil, errors.New("timeout")
+		}
+		return page, nil
+	}
+}
+
+func TestWalkNotificationChain(t *testing.T) {
+	pages := map[string]*NotificationsResult{
+		"":   {HasMore: true, NextCursor: "c1"},
+		"c1": {HasMore: true, NextCursor: "c2"},
+		"c2": {HasMore: false},
+	}
+
+	var requested, visited []string
+	err := walkNotificationChain(fakeNotificationPages(pages, &requested), func(c string, _ *NotificationsResult) bool {
+		visited = append(visited, c)
+		return true
+	}, "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "c1", "c2"}, requested)
+	assert.Equal(t, requested, visited)
+
+	// visit 返回 false 时不再继续请求
+	requested = nil
+	err = walkNotificationChain(fakeNotificationPages(pages, &requested), func(c string, _ *NotificationsResult) bool {
+		return c != "c1"
+	}, "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "c1"}, requested)
+}
+
+func TestWalkNotificationChain_Gaps(t *testing.T) {
+	visitAll := func(string, *NotificationsResult) bool { return true }
+
+	t.Run("第一页失败原样返回", func(t *testing.T) {
+		var requested []string
+		err := walkNotificationChain(fakeNotificationPages(nil, &requested), visitAll, "", 10)
+		require.Error(t, err)
+		var gap *NotificationGapError
+		assert.False(t, errors.As(err, &gap))
+	})
+
+	t.Run("中间页获取失败", func(t *testing.T) {
+		var requested []string
+		pages := map[string]*NotificationsResult{
+			"":   {HasMore: true, NextCursor: "c1"},
+			"c1": {HasMore: true, NextCursor: "c2"},
+		}
+		err := walkNotificationChain(fakeNotificationPages(pages, &requested), visitAll, "", 10)
+		var gap *NotificationGapError
+		require.True(t, errors.As(err, &gap))
+		assert.Equal(t, "c2", gap.Cursor)
+		assert.Equal(t, 2, gap.Pages)
+	})
+
+	t.Run("fetch 返回的断链错误补上页数", func(t *testing.T) {
+		fetch := func(cursor string) (*NotificationsResult, error) {
+			if cursor == "" {
+				return &NotificationsResult{HasMore: true, NextCursor: "c1"}, nil
+			}
+			return nil, &NotificationGapError{Cursor: cursor, Reason: "不连续"}
+		}
+		err := walkNotificationChain(fetch, visitAll, "", 10)
+		var gap *NotificationGapError
+		require.True(t, errors.As(err, &gap))
+		assert.Equal(t, "c1", gap.Cursor)
+		assert.Equal(t, 1, gap.Pages)
+		assert.Equal(t, "不连续", gap.Reason)
+	})
+
+	t.Run("has_more 但没有 next_cursor", func(t *testing.T) {
+		var requested []string
+		pages := map[string]*NotificationsResult{"": {HasMore: true}}
+		err := walkNotificationChain(fakeNotificationPages(pages, &requested), visitAll, "", 10)
+		var gap *NotificationGapError
+		require.True(t, errors.As(err, &gap))
+		assert.Equal(t, 1, gap.Pages)
+	})
+
+	t.Run("cursor 成环", func(t *testing.T) {
+		var requested []string
+		pages := map[string]*NotificationsResult{
+			"":   {HasMore: true, NextCursor: "c1"},
+			"c1": {HasMore: true, NextCursor: "c1"},
+		}
+		err := walkNotificationChain(fakeNotificationPages(pages, &requested), visitAll, "", 10)
+		var gap *NotificationGapError
+		require.True(t, errors.As(err, &gap))
+		assert.Equal(t, []string{"", "c1"}, requested)
+	})
+
+	t.Run("达到翻页上限", func(t *testing.T) {
+		var requested []string
+		pages := map[string]*NotificationsResult{
+			"":   {HasMore: true, NextCursor: "c1"},
+			"c1": {HasMore: true, NextCursor: "c2"},
+		}
+		err := walkNotificationChain(fakeNotificationPages(pages, &requested), visitAll, "", 2)
+		var gap *NotificationGapError
+		require.True(t, errors.As(err, &gap))
+		assert.Equal(t, "c2", gap.Cursor)
+		assert.Equal(t, 2, gap.Pages)
+	})
+}
+
+func TestWalkNotificationChain_ResumeFromCapCursor(t *testing.T) {
+	pages := map[string]*NotificationsResult{
+		"":   {HasMore: true, NextCursor: "c1"},
+		"c1": {HasMore: true, NextCursor: "c2"},
+		"c2": {HasMore: true, NextCursor: "c3"},
+		"c3": {HasMore: false},
+	}
+	visitAll := func(string, *NotificationsResult) bool { return true }
+
+	var requested []string
+	err := walkNotificationChain(fakeNotificationPages(pages, &requested), visitAll, "", 2)
+	var gap *NotificationGapError
+	require.True(t, errors.As(err, &gap))
+	require.Equal(t, "c2", gap.Cursor)
+
+	// 用达到上限时给出的 cursor 续翻：翻到该页之前的页不计入上限
+	requested = nil
+	var target *NotificationsResult
+	err = walkNotificationChain(fakeNotificationPages(pages, &requested), func(c string, page *NotificationsResult) bool {
+		if c == gap.Cursor {
+			target = page
+			return false
+		}
+		return true
+	}, gap.Cursor, 2)
+	require.NoError(t, err)
+	assert.Same(t, pages["c2"], target)
+	assert.Equal(t, []string{"", "c1", "c2"}, requested)
+
+	// 上限从 start 那一页起计数
+	requested = nil
+	err = walkNotificationChain(fakeNotificationPages(pages, &requested), visitAll, "c1", 1)
+	require.True(t, errors.As(err, &gap))
+	assert.Equal(t, "c2", gap.Cursor)
+	assert.Equal(t, 2, gap.Pages)
+	assert.Equal(t, []string{"", "c1"}, requested)
+}
@@ -3,8 +3,8 @@ package xiaohongshu
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
@@ -22,6 +22,8 @@ type NotificationUserInfo struct {
 	Nickname  string `json:"nickname"`
 	Image     string `json:"image"`
 	Indicator string `json:"indicator,omitempty"` // 例如 "作者"、"你的粉丝"
+	// FollowStatus 当前账号与该用户的关注关系（新增关注通知中有，如 both 表示已回关）
+	FollowStatus string `json:"follow_status,omitempty"`
 }
 
 // NotificationCommentInfo 通知中的评论信息
@@ -62,6 +64,8 @@ const (
 	RelationMentionedMe NotificationRelationType = "mentioned_me"
 )
 
+// 赞和收藏、新增关注标签页的关系类型见 notification_categories.go
+
 // Notification 单条通知
 type Notification struct {
 	// 通知 ID（用于去重和分页游标）
@@ -70,7 +74,11 @@ type Notification struct {
 	//   "comment/item"    - 有人评论了你的笔记
 	//   "comment/comment" - 有人在你的评论下留言
 	//   "mention/comment" - 有人在评论中直接 @了你
+	//   "like/item"、"collect/item"、"like/comment" - 赞和收藏
+	//   新增关注标签页的 type 不固定，以 RelationType 为准
 	Type string `json:"type"`
+	// Category 通知所在的标签页
+	Category NotificationCategory `json:"category"`
 	// 通知标题（小红书原始文本，如"回复了你的评论"）
 	Title string `json:"title"`
 	// 发通知的用户
@@ -87,6 +95,8 @@ type Notification struct {
 	//   "reply_to_my_comment"           - 有人直接回复了你的评论（子评论）
 	//   "at_others_under_my_comment"    - 有人在你的评论下 @了其他人（你被间接带到）
 	//   "mentioned_me"                  - 有人在评论中直接 @了你（mention/comment 类型）
+	//   "liked_my_note" / "collected_my_note" / "liked_my_comment" - 赞和收藏
+	//   "new_follower"                  - 有人关注了你
 	RelationType NotificationRelationType `json:"relation_type"`
 
 	// ParentCommentID 仅对 comment/comment 类型有效：
@@ -102,6 +112,9 @@ type NotificationsResult struct {
 	HasMore       bool           `json:"has_more"`
 	// 下一页游标（传给 cursor 参数）
 	NextCursor string `json:"next_cursor,omitempty"`
+	// Incomplete 自动翻页时翻页链中途断开，NextCursor 之后（含）的通知没有取到
+	Incomplete bool   `json:"incomplete,omitempty"`
+	Gap        string `json:"gap,omitempty"` // 断开原因
 }
 
 // RetryReason 通知需要重新处理的原因
@@ -162,6 +175,8 @@ type UnprocessedNotificationsResult struct {
 	TotalDeletedRecheck int  `json:"total_deleted_recheck"` // 已删除重确认通知数
 	PagesScanned        int  `json:"pages_scanned"`         // 扫描了几页
 	HasMore             bool `json:"has_more"`              // 是否还有更多（受 max_results 限制时为 true）
+	// Gap 非空表示翻页链中途断开（原因），之后更早的通知本次没有扫描到
+	Gap string `json:"gap,omitempty"`
 }
 
 // GetUnprocessedNotifications 获取需要处理的通知
@@ -195,16 +210,28 @@ func (n *NotificationsAction) GetUnprocessedNotifications(
 	cst := time.FixedZone("CST", 8*3600)
 	result := &UnprocessedNotificationsResult{}
 
+	// 整个扫描在同一个通知页会话里沿 next_cursor 翻页，避免每页都重新打开页面
+	pager, err := n.openNotificationPager(ctx, NotificationCategoryMentions)
+	if err != nil {
+		return nil, err
+	}
+	defer pager.Close()
+
 	var cursor string
+	seenCursors := map[string]bool{"": true}
 	consecutiveDone := 0
 
 	for page := 0; page < maxPages; page++ {
-		pageResult, err := n.GetNotifications(ctx, cursor, 20)
+		pageResult, err := pager.fetch(cursor)
 		if err != nil {
 			if page == 0 {
 				return nil, err
 			}
 			logrus.Warnf("GetUnprocessedNotifications: 第 %d 页获取失败: %v，使用已有结果", page+1, err)
+			gap := &NotificationGapError{Cursor: cursor, Reason: err.Error()}
+			errors.As(err, &gap)
+			gap.Pages = page
+			result.Gap = gap.Error()
 			break
 		}
 
@@ -317,9 +344,19 @@ func (n *NotificationsAction) GetUnprocessedNotifications(
 			}
 		}
 
-		if !pageResult.HasMore || pageResult.NextCursor == "" {
+		if !pageResult.HasMore {
+			break
+		}
+		if pageResult.NextCursor == "" || seenCursors[pageResult.NextCursor] {
+			result.Gap = (&NotificationGapError{
+				Cursor: pageResult.NextCursor,
+				Pages:  page + 1,
+				Reason: "has_more=true 但 next_cursor 为空或重复",
+			}).Error()
+			logrus.Warnf("GetUnprocessedNotifications: %s", result.Gap)
 			break
 		}
+		seenCursors[pageResult.NextCursor] = true
 		cursor = pageResult.NextCursor
 	}
 
@@ -356,14 +393,15 @@ type mentionsMessage struct {
 		Image     string `json:"image"`
 		Indicator string `json:"indicator,omitempty"`
 		XsecToken string `json:"xsec_token,omitempty"`
+		Fstatus   string `json:"fstatus,omitempty"`
 	} `json:"user_info"`
 
 	CommentInfo struct {
-		ID        string `json:"id"`
-		Content   string `json:"content"`
-		Status    int    `json:"status"`
-		Liked     bool   `json:"liked"`
-		LikeCount int    `json:"like_count"`
+		ID            string `json:"id"`
+		Content       string `json:"content"`
+		Status        int    `json:"status"`
+		Liked         bool   `json:"liked"`
+		LikeCount     int    `json:"like_count"`
 		TargetComment *struct {
 			ID       string `json:"id"`
 			Content  string `json:"content"`
@@ -400,266 +438,98 @@ func NewNotificationsAction(page *rod.Page) *NotificationsAction {
 	return &NotificationsAction{page: page}
 }
 
-// GetNotifications 获取通知列表（单页，最多 20 条）
-// cursor 为空时获取最新通知，非空时获取下一页（通过滚动触发）
-func (n *NotificationsAction) GetNotifications(ctx context.Context, cursor string, limit int) (*NotificationsResult, error) {
+// GetNotifications 获取指定标签页的通知列表（单页，最多 20 条）
+// cursor 为空时获取最新通知；非空时从第一页起沿 next_cursor 逐页翻到请求 cursor 为该值的那一页，
+// 翻到该页之前经过的页不计入翻页上限，GetNotificationsSince 达到上限时给出的 cursor 可以直接传入续翻；
+// cursor 不在当前翻页链上（例如期间来了新通知导致游标变化）时返回错误，而不是返回别的页。
+func (n *NotificationsAction) GetNotifications(ctx context.Context, category NotificationCategory, cursor string, limit int) (*NotificationsResult, error) {
 	if limit <= 0 || limit > 20 {
 		limit = 20
 	}
 
-	// 使用独立的内部 context，避免被 mcporter 的短超时 context 提前取消。
-	// 整个通知流程（warmup + 导航 + reload + 等待 API）约需 20-30s，
-	// 外部 ctx 仍作为父 context，若调用方主动取消则内部也会取消。
-	innerCtx, cancel := context.WithTimeout(ctx, notificationsTimeout)
-	defer cancel()
-
-	// 收集所有拦截到的 API 响应（按 cursor 索引）
-	type apiEntry struct {
-		cursor string
-		body   string
-	}
-	var mu sync.Mutex
-	var apiEntries []apiEntry
-
-	page := n.page.Context(innerCtx)
-
-	router := page.HijackRequests()
-	go router.Run()
-	defer router.Stop()
-
-	router.MustAdd("*/api/sns/web/v1/you/mentions*", func(ctx *rod.Hijack) {
-		ctx.MustLoadResponse()
-		reqURL := ctx.Request.URL()
-		c := reqURL.Query().Get("cursor")
-		body := ctx.Response.Body()
-		mu.Lock()
-		apiEntries = append(apiEntries, apiEntry{cursor: c, body: body})
-		mu.Unlock()
-	})
-
-	// 先访问主页让 SPA 完全初始化，再强制 reload 通知页面。
-	// 不能直接从主页 SPA 路由切换到 /notification（SPA 会复用内存中的旧数据不重新请求 API）；
-	// 也不能用 about:blank 冷启动（SPA 未初始化，DOM stable 时 JS 还未执行，API 请求来不及发出）。
-	// 正确做法：主页 warmup → 导航到通知页 → Reload 强制浏览器重新发起所有请求。
-	logrus.Info("通知：主页 warmup...")
-	page.MustNavigate("https://www.xiaohongshu.com/")
-	page.MustWaitDOMStable()
-	time.Sleep(1 * time.Second)
-
-	logrus.Info("通知：导航到通知页并强制 reload...")
-	page.MustNavigate("https://www.xiaohongshu.com/notification")
-	page.MustWaitDOMStable()
-	// Reload 强制浏览器丢弃 SPA 内存缓存，重新发起 /api/sns/web/v1/you/mentions 请求
-	page.MustReload()
-	page.MustWaitDOMStable()
-
-	// 等待第一页 API 响应
-	for i := 0; i < 10; i++ {
-		time.Sleep(1 * time.Second)
-		mu.Lock()
-		count := len(apiEntries)
-		mu.Unlock()
-		if count > 0 {
-			break
-		}
-		logrus.Infof("通知：等待 API 响应... (%ds)", i+1)
+	pager, err := n.openNotificationPager(ctx, category)
+	if err != nil {
+		return nil, err
 	}
+	defer pager.Close()
 
-	mu.Lock()
-	firstEntries := make([]apiEntry, len(apiEntries))
-	copy(firstEntries, apiEntries)
-	mu.Unlock()
-
-	if len(firstEntries) == 0 {
-		return nil, fmt.Errorf("无法获取通知数据，请确认已登录")
+	if cursor != "" {
+		logrus.Infof("通知：需要 cursor=%s 的页面，从第一页开始逐页翻...", cursor)
 	}
 
-	// 找到最后一个有效响应（通常是第二个，第一个可能为空）
-	var targetBody string
-	for i := len(firstEntries) - 1; i >= 0; i-- {
-		if firstEntries[i].body != "" && firstEntries[i].cursor == "" {
-			targetBody = firstEntries[i].body
-			break
+	var target *NotificationsResult
+	err = walkNotificationChain(pager.fetch, func(c string, page *NotificationsResult) bool {
+		if c == cursor {
+			target = page
+			return false
 		}
+		return true
+	}, cursor, notificationMaxPages)
+	if target != nil {
+		return target, nil
 	}
-
-	// 如果需要 cursor 分页，通过滚动触发
-	if cursor != "" {
-		logrus.Infof("通知：需要 cursor=%s 的页面，通过滚动触发...", cursor)
-		mu.Lock()
-		apiEntries = nil
-		mu.Unlock()
-
-		// 滚动到底部触发加载更多
-		page.MustEval(`() => window.scrollTo(0, document.body.scrollHeight)`)
-
-		// 等待带 cursor 的 API 响应
-		for i := 0; i < 10; i++ {
-			time.Sleep(1 * time.Second)
-			mu.Lock()
-			var found string
-			for _, e := range apiEntries {
-				if e.cursor != "" && e.body != "" {
-					found = e.body
-				}
-			}
-			mu.Unlock()
-			if found != "" {
-				targetBody = found
-				break
-			}
-			logrus.Infof("通知：等待 cursor 页 API 响应... (%ds)", i+1)
+	if err != nil {
+		if cursor == "" {
+			return nil, err
 		}
+		return nil, fmt.Errorf("翻页到 cursor=%s 失败: %w", cursor, err)
 	}
+	return nil, fmt.Errorf("当前通知翻页链中没有 cursor=%s 的页面，可能期间有新通知导致游标变化，请从第一页重新获取", cursor)
+}
 
-	if targetBody == "" {
-		return nil, fmt.Errorf("未获取到有效的通知数据")
+// GetNotificationsSince 获取指定标签页在指定时间之后的所有通知（自动翻页）
+// sinceUnix 为 Unix 时间戳（秒），0 表示获取所有。
+// 翻页链中途断开时返回已取到的通知，并设置 Incomplete/Gap，HasMore/NextCursor 指向断开处以便续翻。
+func (n *NotificationsAction) GetNotificationsSince(ctx context.Context, category NotificationCategory, sinceUnix int64) (*NotificationsResult, error) {
+	pager, err := n.openNotificationPager(ctx, category)
+	if err != nil {
+		return nil, err
 	}
+	defer pager.Close()
 
-	return parseNotificationsResponse(targetBody)
-}
-
-// GetNotificationsSince 获取指定时间之后的所有通知（自动翻页）
-// sinceUnix 为 Unix 时间戳（秒），0 表示获取所有
-func (n *NotificationsAction) GetNotificationsSince(ctx context.Context, sinceUnix int64) (*NotificationsResult, error) {
 	var allNotifications []Notification
-	var lastCursor string
-	hasMore := true
+	seenIDs := make(map[string]bool)
 	pageNum := 0
 
-	// 使用独立的内部 context，避免被 mcporter 的短超时 context 提前取消。
-	// since 模式可能需要翻多页，给更充裕的时间（最多 10 页 × 约 15s/页）。
-	innerCtx, cancel := context.WithTimeout(ctx, notificationsTimeout)
-	defer cancel()
-
-	// 收集所有拦截到的 API 响应
-	type apiEntry struct {
-		cursor string
-		body   string
-	}
-	var mu sync.Mutex
-	var apiEntries []apiEntry
-
-	page := n.page.Context(innerCtx)
-
-	router := page.HijackRequests()
-	go router.Run()
-	defer router.Stop()
-
-	router.MustAdd("*/api/sns/web/v1/you/mentions*", func(ctx *rod.Hijack) {
-		ctx.MustLoadResponse()
-		reqURL := ctx.Request.URL()
-		c := reqURL.Query().Get("cursor")
-		body := ctx.Response.Body()
-		if body != "" {
-			mu.Lock()
-			apiEntries = append(apiEntries, apiEntry{cursor: c, body: body})
-			mu.Unlock()
-		}
-	})
-
-	// 主页 warmup → 导航通知页 → Reload（原因同 GetNotifications）
-	logrus.Info("通知(since)：主页 warmup...")
-	page.MustNavigate("https://www.xiaohongshu.com/")
-	page.MustWaitDOMStable()
-	time.Sleep(1 * time.Second)
-
-	logrus.Info("通知(since)：导航到通知页并强制 reload...")
-	page.MustNavigate("https://www.xiaohongshu.com/notification")
-	page.MustWaitDOMStable()
-	page.MustReload()
-	page.MustWaitDOMStable()
-
-	// 等待第一页 API 响应
-	for i := 0; i < 10; i++ {
-		time.Sleep(1 * time.Second)
-		mu.Lock()
-		count := len(apiEntries)
-		mu.Unlock()
-		if count > 0 {
-			break
-		}
-		logrus.Infof("通知(since)：等待第一页 API 响应... (%ds)", i+1)
-	}
-
-	for hasMore && pageNum < 10 { // 最多翻 10 页（200 条）
-		// 获取当前页的响应
-		mu.Lock()
-		var pageBody string
-		for _, e := range apiEntries {
-			if e.cursor == lastCursor && e.body != "" {
-				pageBody = e.body
-			}
-		}
-		mu.Unlock()
-
-		if pageBody == "" {
-			if pageNum == 0 {
-				return nil, fmt.Errorf("无法获取通知数据，请确认已登录")
-			}
-			break
-		}
-
-		result, err := parseNotificationsResponse(pageBody)
-		if err != nil {
-			return nil, err
-		}
-
+	err = walkNotificationChain(pager.fetch, func(_ string, page *NotificationsResult) bool {
+		pageNum++
 		// 过滤时间范围，并检查是否需要继续翻页
 		reachedOldData := false
-		for _, n := range result.Notifications {
+		for _, n := range page.Notifications {
 			if sinceUnix > 0 && n.Time < sinceUnix {
 				reachedOldData = true
 				break
 			}
+			// 翻页期间来了新通知时，相邻两页可能出现同一条
+			if seenIDs[n.ID] {
+				continue
+			}
+			seenIDs[n.ID] = true
 			allNotifications = append(allNotifications, n)
 		}
 
 		logrus.Infof("通知(since)：第 %d 页获取 %d 条，累计 %d 条，has_more=%v",
-			pageNum+1, len(result.Notifications), len(allNotifications), result.HasMore)
-
-		if reachedOldData || !result.HasMore {
-			break
-		}
-
-		// 滚动触发下一页
-		lastCursor = result.NextCursor
-		mu.Lock()
-		apiEntries = nil
-		mu.Unlock()
-
-		logrus.Infof("通知(since)：滚动加载下一页（cursor=%s）...", lastCursor)
-		page.MustEval(`() => window.scrollTo(0, document.body.scrollHeight)`)
-
-		// 等待下一页响应
-		for i := 0; i < 10; i++ {
-			time.Sleep(1 * time.Second)
-			mu.Lock()
-			found := false
-			for _, e := range apiEntries {
-				if e.cursor != "" && e.body != "" {
-					found = true
-				}
-			}
-			mu.Unlock()
-			if found {
-				break
-			}
-			logrus.Infof("通知(since)：等待下一页 API 响应... (%ds)", i+1)
+			pageNum, len(page.Notifications), len(allNotifications), page.HasMore)
+		return !reachedOldData
+	}, "", notificationMaxPages)
+
+	result := &NotificationsResult{Notifications: allNotifications}
+	if err != nil {
+		var gap *NotificationGapError
+		if !errors.As(err, &gap) {
+			return nil, err
 		}
-
-		pageNum++
+		logrus.Warnf("通知(since)：%v，已取到 %d 条", gap, len(allNotifications))
+		result.HasMore = true
+		result.NextCursor = gap.Cursor
+		result.Incomplete = true
+		result.Gap = gap.Error()
 	}
-
-	return &NotificationsResult{
-		Notifications: allNotifications,
-		HasMore:       false,
-		NextCursor:    lastCursor,
-	}, nil
+	return result, nil
 }
 
-// parseNotificationsResponse 解析 mentions API 响应
-func parseNotificationsResponse(body string) (*NotificationsResult, error) {
+// parseNotificationsResponse 解析通知 API 响应（评论和@、赞和收藏、新增关注的结构相同）
+func parseNotificationsResponse(category NotificationCategory, body string) (*NotificationsResult, error) {
 	var apiResp mentionsAPIResponse
 	if err := json.Unmarshal([]byte(body), &apiResp); err != nil {
 		preview := body
@@ -676,21 +546,25 @@ func parseNotificationsResponse(body string) (*NotificationsResult, error) {
 	notifications := make([]Notification, 0, len(apiResp.Data.MessageList))
 	for _, msg := range apiResp.Data.MessageList {
 		logrus.Debugf("通知原始 type=%q id=%s title=%q", msg.Type, msg.ID, msg.Title)
-		if msg.Type != "comment/item" && msg.Type != "comment/comment" && msg.Type != "mention/comment" {
+		relation, ok := notificationRelation(category, msg.Type, msg.CommentInfo.Content)
+		if !ok {
 			logrus.Infof("通知过滤（未支持的 type）: type=%q id=%s title=%q", msg.Type, msg.ID, msg.Title)
 			continue
 		}
 
 		notification := Notification{
-			ID:    msg.ID,
-			Type:  msg.Type,
-			Title: msg.Title,
-			Time:  msg.Time,
+			ID:           msg.ID,
+			Type:         msg.Type,
+			Category:     category,
+			Title:        msg.Title,
+			Time:         msg.Time,
+			RelationType: relation,
 			UserInfo: NotificationUserInfo{
-				UserID:    msg.UserInfo.UserID,
-				Nickname:  msg.UserInfo.Nickname,
-				Image:     msg.UserInfo.Image,
-				Indicator: msg.UserInfo.Indicator,
+				UserID:       msg.UserInfo.UserID,
+				Nickname:     msg.UserInfo.Nickname,
+				Image:        msg.UserInfo.Image,
+				Indicator:    msg.UserInfo.Indicator,
+				FollowStatus: msg.UserInfo.Fstatus,
 			},
 			CommentInfo: NotificationCommentInfo{
 				ID:      msg.CommentInfo.ID,
@@ -723,25 +597,6 @@ func parseNotificationsResponse(body string) (*NotificationsResult, error) {
 			notification.ParentCommentID = msg.CommentInfo.TargetComment.ID
 		}
 
-		// 设置 RelationType：客观描述评论与当前用户的关系
-		switch msg.Type {
-		case "comment/item":
-			// 有人直接评论了你的笔记
-			notification.RelationType = RelationCommentOnMyNote
-		case "comment/comment":
-			content := msg.CommentInfo.Content
-			// 启发式判断：评论内容以 @ 开头 → 用户在你的评论下 @了其他人
-			// 否则 → 用户直接回复了你的评论
-			if len(content) > 0 && content[0] == '@' {
-				notification.RelationType = RelationAtOthersUnderMyComment
-			} else {
-				notification.RelationType = RelationReplyToMyComment
-			}
-		case "mention/comment":
-			// 有人在评论中直接 @了你（不是在你的评论下，而是在任意评论里 @你）
-			notification.RelationType = RelationMentionedMe
-		}
-
 		notifications = append(notifications, notification)
 	}
 
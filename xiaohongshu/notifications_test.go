@@ -0,0 +1,78 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNotificationsResponse_Mentions(t *testing.T) {
+	body := `{"code":0,"success":true,"data":{"has_more":true,"strCursor":"c1","message_list":[
+		{"id":"1","type":"comment/item","time":100,"user_info":{"userid":"u1","nickname":"甲"},
+		 "comment_info":{"id":"c1","content":"好看"},"item_info":{"id":"n1","xsec_token":"t1"}},
+		{"id":"2","type":"comment/comment","time":99,"user_info":{"userid":"u2"},
+		 "comment_info":{"id":"c2","content":"@丙 看这个","target_comment":{"id":"c0","content":"原评论"}}},
+		{"id":"3","type":"like/item","time":98}
+	]}}`
+
+	result, err := parseNotificationsResponse(NotificationCategoryMentions, body)
+	require.NoError(t, err)
+	require.Len(t, result.Notifications, 2)
+	assert.True(t, result.HasMore)
+	assert.Equal(t, "c1", result.NextCursor)
+
+	assert.Equal(t, RelationCommentOnMyNote, result.Notifications[0].RelationType)
+	assert.Equal(t, NotificationCategoryMentions, result.Notifications[0].Category)
+	assert.Equal(t, RelationAtOthersUnderMyComment, result.Notifications[1].RelationType)
+	assert.Equal(t, "c0", result.Notifications[1].ParentCommentID)
+}
+
+func TestParseNotificationsResponse_Likes(t *testing.T) {
+	body := `{"code":0,"success":true,"data":{"message_list":[
+		{"id":"1","type":"like/item","title":"赞了你的笔记","item_info":{"id":"n1","content":"笔记"}},
+		{"id":"2","type":"collect/item","title":"收藏了你的笔记","item_info":{"id":"n1"}},
+		{"id":"3","type":"like/comment","title":"赞了你的评论","comment_info":{"id":"c1","content":"我的评论"},"item_info":{"id":"n2"}},
+		{"id":"4","type":"system/notice"}
+	]}}`
+
+	result, err := parseNotificationsResponse(NotificationCategoryLikes, body)
+	require.NoError(t, err)
+	require.Len(t, result.Notifications, 3)
+
+	var relations []NotificationRelationType
+	for _, n := range result.Notifications {
+		assert.Equal(t, NotificationCategoryLikes, n.Category)
+		relations = append(relations, n.RelationType)
+	}
+	assert.Equal(t, []NotificationRelationType{RelationLikedMyNote, RelationCollectedMyNote, RelationLikedMyComment}, relations)
+	assert.Equal(t, "c1", result.Notifications[2].CommentInfo.ID)
+}
+
+func TestParseNotificationsResponse_Connections(t *testing.T) {
+	body := `{"code":0,"success":true,"data":{"message_list":[
+		{"id":"1","type":"follow/you","title":"开始关注你了","time":100,
+		 "user_info":{"userid":"u1","nickname":"新粉丝","fstatus":"fans"}}
+	]}}`
+
+	result, err := parseNotificationsResponse(NotificationCategoryConnections, body)
+	require.NoError(t, err)
+	require.Len(t, result.Notifications, 1)
+	n := result.Notifications[0]
+	assert.Equal(t, RelationNewFollower, n.RelationType)
+	assert.Equal(t, "u1", n.UserInfo.UserID)
+	assert.Equal(t, "fans", n.UserInfo.FollowStatus)
+}
+
+func TestParseNotificationCategory(t *testing.T) {
+	category, err := ParseNotificationCategory("")
+	require.NoError(t, err)
+	assert.Equal(t, NotificationCategoryMentions, category)
+
+	category, err = ParseNotificationCategory("likes")
+	require.NoError(t, err)
+	assert.Equal(t, NotificationCategoryLikes, category)
+
+	_, err = ParseNotificationCategory("fans")
+	assert.Error(t, err)
+}
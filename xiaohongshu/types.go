@@ -54,6 +54,9 @@ type InteractInfo struct {
 
 	CollectedCount string `json:"collectedCount"`
 	Collected      bool   `json:"collected"`
+
+	// 是否在作者主页置顶
+	Sticky bool `json:"sticky,omitempty"`
 }
 
 // Cover 表示封面信息
@@ -103,15 +106,100 @@ type FeedDetail struct {
 	User         User              `json:"user"`
 	InteractInfo InteractInfo      `json:"interactInfo"`
 	ImageList    []DetailImageInfo `json:"imageList"`
+	Video        *DetailVideo      `json:"video,omitempty"` // 视频笔记才有
+
+	LastUpdateTime int64           `json:"lastUpdateTime"`
+	TagList        []NoteTag       `json:"tagList"`    // 话题标签
+	AtUserList     []NoteAtUser    `json:"atUserList"` // 正文中 @ 的用户
+	Poi            *NotePoi        `json:"poi,omitempty"`
+	Collection     *NoteCollection `json:"noteCollection,omitempty"` // 所属合集
+	ShareInfo      NoteShareInfo   `json:"shareInfo"`
+	IsAd           bool            `json:"isAd,omitempty"`
+
+	// 以下字段不在页面数据中，由 extractFeedDetail 补充
+	ShareLink string `json:"shareLink,omitempty"`
+}
+
+// NoteTag 表示笔记的话题标签
+type NoteTag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"` // topic / location / brand 等
+}
+
+// NoteAtUser 表示正文中 @ 的用户
+type NoteAtUser struct {
+	UserID    string `json:"userId"`
+	Nickname  string `json:"nickname"`
+	XsecToken string `json:"xsecToken,omitempty"`
+}
+
+// NotePoi 表示笔记关联的地点
+type NotePoi struct {
+	PoiID   string `json:"poiId"`
+	Name    string `json:"name"`
+	Address string `json:"address,omitempty"`
+	Type    int    `json:"type,omitempty"`
+}
+
+// NoteCollection 表示笔记所属的合集
+type NoteCollection struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	NoteNum int    `json:"noteNum,omitempty"`
+}
+
+// NoteShareInfo 表示笔记的分享设置
+type NoteShareInfo struct {
+	UnShare bool `json:"unShare"` // 作者关闭了分享
 }
 
 // DetailImageInfo 表示详情页的图片信息
 type DetailImageInfo struct {
-	Width      int    `json:"width"`
-	Height     int    `json:"height"`
-	URLDefault string `json:"urlDefault"`
-	URLPre     string `json:"urlPre"`
-	LivePhoto  bool   `json:"livePhoto,omitempty"`
+	Width      int         `json:"width"`
+	Height     int         `json:"height"`
+	URLDefault string      `json:"urlDefault"`
+	URLPre     string      `json:"urlPre"`
+	LivePhoto  bool        `json:"livePhoto,omitempty"`
+	InfoList   []ImageInfo `json:"infoList,omitempty"`
+	// Stream 实况图（live photo）的视频流，仅 LivePhoto 为 true 时有
+	Stream *VideoStreamSet `json:"stream,omitempty"`
+}
+
+// DetailVideo 表示详情页的视频信息
+type DetailVideo struct {
+	Capa  VideoCapability `json:"capa"`
+	Media struct {
+		Stream VideoStreamSet `json:"stream"`
+	} `json:"media"`
+	Image struct {
+		FirstFrameFileID string `json:"firstFrameFileid"`
+		ThumbnailFileID  string `json:"thumbnailFileid"`
+	} `json:"image"`
+}
+
+// VideoStreamSet 按编码分组的视频流
+type VideoStreamSet struct {
+	H264 []VideoStreamInfo `json:"h264"`
+	H265 []VideoStreamInfo `json:"h265"`
+	H266 []VideoStreamInfo `json:"h266"`
+	AV1  []VideoStreamInfo `json:"av1"`
+}
+
+// VideoStreamInfo 表示单路视频流
+type VideoStreamInfo struct {
+	MasterURL    string   `json:"masterUrl"`
+	BackupURLs   []string `json:"backupUrls"`
+	Width        int      `json:"width"`
+	Height       int      `json:"height"`
+	VideoCodec   string   `json:"videoCodec"`
+	VideoBitrate int      `json:"videoBitrate"`
+	AvgBitrate   int      `json:"avgBitrate"`
+	Fps          int      `json:"fps"`
+	Duration     int      `json:"duration"` // 毫秒
+	Size         int64    `json:"size"`     // 字节
+	QualityType  string   `json:"qualityType"`
+	Format       string   `json:"format"`
 }
 
 // CommentList 表示评论列表
@@ -134,6 +222,14 @@ type Comment struct {
 	SubCommentCount string    `json:"subCommentCount"`
 	SubComments     []Comment `json:"subComments"`
 	ShowTags        []string  `json:"showTags"`
+	// 被回复的评论（仅楼中楼子评论有）
+	TargetComment *TargetComment `json:"targetComment,omitempty"`
+}
+
+// TargetComment 表示子评论所回复的目标评论
+type TargetComment struct {
+	ID       string `json:"id"`
+	UserInfo User   `json:"userInfo"`
 }
 
 // UserProfileResponse 用户详情页完整响应
@@ -141,6 +237,10 @@ type UserProfileResponse struct {
 	UserBasicInfo UserBasicInfo      `json:"userBasicInfo"`
 	Interactions  []UserInteractions `json:"interactions"`
 	Feeds         []Feed             `json:"feeds"`
+	// Tab 按标签页加载时 Feeds 所属的标签页
+	Tab ProfileNotesTab `json:"tab,omitempty"`
+	// HasMore 按标签页加载时是否还有未返回的笔记
+	HasMore bool `json:"hasMore,omitempty"`
 }
 
 // UserPageData 用户的详细信息
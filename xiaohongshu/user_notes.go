@@ -0,0 +1,270 @@
+package xiaohongshu
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/sirupsen/logrus"
+)
+
+// ProfileNotesTab 用户主页的笔记标签页
+type ProfileNotesTab string
+
+const (
+	ProfileTabNotes     ProfileNotesTab = "notes"     // 笔记
+	ProfileTabCollected ProfileNotesTab = "collected" // 收藏
+	ProfileTabLiked     ProfileNotesTab = "liked"     // 赞过
+)
+
+// profileTabs 标签页在页面上的文字、在 __INITIAL_STATE__.user.notes 中的下标和对应的分页接口
+var profileTabs = map[ProfileNotesTab]struct {
+	label      string
+	stateIndex int
+	apiPattern string
+}{
+	ProfileTabNotes:     {"笔记", 0, "*/api/sns/web/v1/user_posted*"},
+	ProfileTabCollected: {"收藏", 1, "*/api/sns/web/v2/note/collect/page*"},
+	ProfileTabLiked:     {"赞过", 2, "*/api/sns/web/v1/note/like/page*"},
+}
+
+// UserProfileOptions 获取用户主页时的笔记加载选项
+type UserProfileOptions struct {
+	// Tab 笔记标签页，为空时保持原有行为（只返回首屏数据）
+	Tab ProfileNotesTab
+	// MaxNotes 最多返回的笔记数，大于 0 时滚动主页加载更多
+	MaxNotes int
+}
+
+// paginated 是否需要按标签页分页加载
+func (o UserProfileOptions) paginated() bool {
+	return o.Tab != "" || o.MaxNotes > 0
+}
+
+// profileNotesAPIResponse 主页笔记分页接口的响应
+type profileNotesAPIResponse struct {
+	Success bool   `json:"success"`
+	Msg     string `json:"msg"`
+	Data    struct {
+		Notes   []profileAPINote `json:"notes"`
+		Cursor  string           `json:"cursor"`
+		HasMore bool             `json:"has_more"`
+	} `json:"data"`
+}
+
+// profileAPINote 主页笔记分页接口中的笔记（字段为下划线命名，与 __INITIAL_STATE__ 不同）
+type profileAPINote struct {
+	NoteID       string `json:"note_id"`
+	DisplayTitle string `json:"display_title"`
+	Type         string `json:"type"`
+	XsecToken    string `json:"xsec_token"`
+	User         struct {
+		UserID   string `json:"user_id"`
+		Nickname string `json:"nickname"`
+		NickName string `json:"nick_name"`
+		Avatar   string `json:"avatar"`
+	} `json:"user"`
+	InteractInfo struct {
+		Liked      bool   `json:"liked"`
+		LikedCount string `json:"liked_count"`
+		Sticky     bool   `json:"sticky"`
+	} `json:"interact_info"`
+	Cover struct {
+		URL        string `json:"url"`
+		URLPre     string `json:"url_pre"`
+		URLDefault string `json:"url_default"`
+		Width      int    `json:"width"`
+		Height     int    `json:"height"`
+	} `json:"cover"`
+}
+
+func (n profileAPINote) toFeed() Feed {
+	return Feed{
+		ID:        n.NoteID,
+		XsecToken: n.XsecToken,
+		ModelType: "note",
+		NoteCard: NoteCard{
+			Type:         n.Type,
+			DisplayTitle: n.DisplayTitle,
+			User: User{
+				UserID:   n.User.UserID,
+				Nickname: n.User.Nickname,
+				NickName: n.User.NickName,
+				Avatar:   n.User.Avatar,
+			},
+			InteractInfo: InteractInfo{
+				Liked:      n.InteractInfo.Liked,
+				LikedCount: n.InteractInfo.LikedCount,
+				Sticky:     n.InteractInfo.Sticky,
+			},
+			Cover: Cover{
+				URL:        n.Cover.URL,
+				URLPre:     n.Cover.URLPre,
+				URLDefault: n.Cover.URLDefault,
+				Width:      n.Cover.Width,
+				Height:     n.Cover.Height,
+			},
+		},
+	}
+}
+
+// profileNotesCollector 合并首屏数据和分页接口返回的笔记，按笔记 ID 去重
+type profileNotesCollector struct {
+	mu      sync.Mutex
+	feeds   []Feed
+	seen    map[string]bool
+	hasMore bool
+	// rejectMsg 接口返回失败时的提示（如对方未公开收藏）
+	rejectMsg string
+}
+
+func newProfileNotesCollector() *profileNotesCollector {
+	return &profileNotesCollector{seen: make(map[string]bool), hasMore: true}
+}
+
+func (c *profileNotesCollector) addFeeds(feeds []Feed) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, f := range feeds {
+		if f.ID == "" || c.seen[f.ID] {
+			continue
+		}
+		c.seen[f.ID] = true
+		f.Index = len(c.feeds)
+		c.feeds = append(c.feeds, f)
+	}
+}
+
+func (c *profileNotesCollector) addResponse(resp profileNotesAPIResponse) {
+	if !resp.Success {
+		c.mu.Lock()
+		c.rejectMsg = resp.Msg
+		c.hasMore = false
+		c.mu.Unlock()
+		return
+	}
+	feeds := make([]Feed, 0, len(resp.Data.Notes))
+	for _, n := range resp.Data.Notes {
+		feeds = append(feeds, n.toFeed())
+	}
+	c.addFeeds(feeds)
+	c.mu.Lock()
+	c.hasMore = resp.Data.HasMore
+	c.mu.Unlock()
+}
+
+func (c *profileNotesCollector) count() (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.feeds), c.hasMore
+}
+
+// result 返回前 maxNotes 条（maxNotes<=0 时全部）以及是否还有更多
+func (c *profileNotesCollector) result(maxNotes int) ([]Feed, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if maxNotes <= 0 || maxNotes >= len(c.feeds) {
+		return append([]Feed{}, c.feeds...), c.hasMore
+	}
+	return append([]Feed{}, c.feeds[:maxNotes]...), true
+}
+
+// addProfileNotesHijack 拦截指定标签页的分页接口
+func addProfileNotesHijack(router *rod.HijackRouter, tab ProfileNotesTab, collector *profileNotesCollector) {
+	router.MustAdd(profileTabs[tab].apiPattern, func(h *rod.Hijack) {
+		h.MustLoadResponse()
+		var resp profileNotesAPIResponse
+		if err := json.Unmarshal([]byte(h.Response.Body()), &resp); err != nil {
+			logrus.Warnf("解析主页%s接口响应失败: %v", tab, err)
+			return
+		}
+		collector.addResponse(resp)
+	})
+}
+
+// switchProfileTab 点击主页上的标签页（收藏、赞过）。对方未公开时页面上没有该标签或标签不可点
+func switchProfileTab(page *rod.Page, tab ProfileNotesTab) error {
+	label := profileTabs[tab].label
+	clicked, err := page.Eval(`(label) => {
+		const tabs = document.querySelectorAll('.reds-tabs-list .reds-tab-item, .tab-content-container .reds-tab-item, [class*="tabs"] [class*="tab-item"]');
+		for (const t of tabs) {
+			if ((t.innerText || '').trim().startsWith(label)) {
+				t.click();
+				return true;
+			}
+		}
+		return false;
+	}`, label)
+	if err != nil {
+		return fmt.Errorf("切换到%s标签失败: %w", label, err)
+	}
+	if !clicked.Value.Bool() {
+		return fmt.Errorf("主页上没有%s标签，对方可能未公开", label)
+	}
+	time.Sleep(2 * time.Second)
+	return nil
+}
+
+// readStateNotes 读取 __INITIAL_STATE__.user.notes 中某个标签页已加载的笔记
+func readStateNotes(page *rod.Page, index int) []Feed {
+	result, err := page.Eval(`(index) => {
+		const state = window.__INITIAL_STATE__;
+		if (!state || !state.user || !state.user.notes) return "";
+		const notes = state.user.notes;
+		const data = notes.value !== undefined ? notes.value : notes._value;
+		return data && data[index] ? JSON.stringify(data[index]) : "";
+	}`, index)
+	if err != nil || result.Value.String() == "" {
+		return nil
+	}
+	var feeds []Feed
+	if err := json.Unmarshal([]byte(result.Value.String()), &feeds); err != nil {
+		logrus.Warnf("解析主页笔记失败: %v", err)
+		return nil
+	}
+	return feeds
+}
+
+// loadProfileNotes 切换到指定标签页并滚动加载，直到凑够 maxNotes 条或没有更多
+func loadProfileNotes(page *rod.Page, opts UserProfileOptions, collector *profileNotesCollector) ([]Feed, bool, error) {
+	tab := opts.Tab
+	if tab != ProfileTabNotes {
+		if err := switchProfileTab(page, tab); err != nil {
+			return nil, false, err
+		}
+	}
+	collector.addFeeds(readStateNotes(page, profileTabs[tab].stateIndex))
+
+	stale := 0
+	for opts.MaxNotes > 0 {
+		count, hasMore := collector.count()
+		if count >= opts.MaxNotes || !hasMore {
+			break
+		}
+		page.MustEval(`() => window.scrollTo(0, document.body.scrollHeight)`)
+		time.Sleep(1500 * time.Millisecond)
+
+		// 新数据可能只进入 state 而没有经过接口（首屏后的第一次加载），两处都合并
+		collector.addFeeds(readStateNotes(page, profileTabs[tab].stateIndex))
+		if newCount, _ := collector.count(); newCount == count {
+			stale++
+			if stale >= 3 {
+				logrus.Warnf("主页%s滚动 %d 次没有新笔记，停止加载", tab, stale)
+				break
+			}
+		} else {
+			stale = 0
+		}
+	}
+
+	collector.mu.Lock()
+	rejectMsg := collector.rejectMsg
+	collector.mu.Unlock()
+	feeds, hasMore := collector.result(opts.MaxNotes)
+	if len(feeds) == 0 && rejectMsg != "" {
+		return nil, false, fmt.Errorf("获取主页%s失败: %s", profileTabs[tab].label, rejectMsg)
+	}
+	return feeds, hasMore, nil
+}
@@ -0,0 +1,49 @@
+package xiaohongshu
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileNotesCollector(t *testing.T) {
+	var resp profileNotesAPIResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"success":true,"data":{"has_more":true,"cursor":"n3","notes":[
+		{"note_id":"n2","display_title":"第二篇","type":"video","xsec_token":"t2",
+		 "user":{"user_id":"u1","nickname":"作者"},"interact_info":{"liked_count":"12","sticky":true},
+		 "cover":{"url_default":"https://c/2.jpg","width":3,"height":4}},
+		{"note_id":"n3","display_title":"第三篇","type":"normal"}
+	]}}`), &resp))
+
+	c := newProfileNotesCollector()
+	// 首屏数据和接口数据重叠时去重
+	c.addFeeds([]Feed{{ID: "n1"}, {ID: "n2"}})
+	c.addResponse(resp)
+
+	feeds, hasMore := c.result(0)
+	require.Len(t, feeds, 3)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{"n1", "n2", "n3"}, []string{feeds[0].ID, feeds[1].ID, feeds[2].ID})
+	assert.Equal(t, 2, feeds[2].Index)
+	assert.Equal(t, "第三篇", feeds[2].NoteCard.DisplayTitle)
+
+	converted := resp.Data.Notes[0].toFeed()
+	assert.Equal(t, "t2", converted.XsecToken)
+	assert.Equal(t, "video", converted.NoteCard.Type)
+	assert.Equal(t, "12", converted.NoteCard.InteractInfo.LikedCount)
+	assert.True(t, converted.NoteCard.InteractInfo.Sticky)
+	assert.Equal(t, "https://c/2.jpg", converted.NoteCard.Cover.URLDefault)
+
+	// 截断到 max_notes 时 hasMore 为 true
+	feeds, hasMore = c.result(2)
+	assert.Len(t, feeds, 2)
+	assert.True(t, hasMore)
+
+	// 未公开时接口返回失败
+	c.addResponse(profileNotesAPIResponse{Msg: "该用户未公开收藏"})
+	_, hasMore = c.count()
+	assert.False(t, hasMore)
+	assert.Equal(t, "该用户未公开收藏", c.rejectMsg)
+}
@@ -18,15 +18,55 @@ func NewUserProfileAction(page *rod.Page) *UserProfileAction {
 	return &UserProfileAction{page: pp}
 }
 
-// UserProfile 获取用户基本信息及帖子
-func (u *UserProfileAction) UserProfile(ctx context.Context, userID, xsecToken string) (*UserProfileResponse, error) {
+// UserProfile 获取用户基本信息及帖子。
+// opts 为零值时只返回首屏数据；指定标签页或 MaxNotes 时，通过拦截分页接口并滚动主页加载该标签页的笔记。
+func (u *UserProfileAction) UserProfile(ctx context.Context, userID, xsecToken string, opts UserProfileOptions) (*UserProfileResponse, error) {
 	page := u.page.Context(ctx)
+	if !opts.paginated() {
+		searchURL := makeUserProfileURL(userID, xsecToken)
+		page.MustNavigate(searchURL)
+		page.MustWaitStable()
+		if err := checkPageAccessible(page); err != nil {
+			return nil, err
+		}
+
+		return u.extractUserProfileData(page)
+	}
+
+	if opts.Tab == "" {
+		opts.Tab = ProfileTabNotes
+	}
+	if _, ok := profileTabs[opts.Tab]; !ok {
+		return nil, fmt.Errorf("不支持的标签页: %s", opts.Tab)
+	}
+	// 滚动加载可能较慢，放宽超时
+	page = page.Timeout(5 * time.Minute)
 
-	searchURL := makeUserProfileURL(userID, xsecToken)
-	page.MustNavigate(searchURL)
+	collector := newProfileNotesCollector()
+	router := page.HijackRequests()
+	addProfileNotesHijack(router, opts.Tab, collector)
+	go router.Run()
+	defer router.Stop()
+
+	page.MustNavigate(makeUserProfileURL(userID, xsecToken))
 	page.MustWaitStable()
+	if err := checkPageAccessible(page); err != nil {
+		return nil, err
+	}
 
-	return u.extractUserProfileData(page)
+	profile, err := u.extractUserProfileData(page)
+	if err != nil {
+		return nil, err
+	}
+
+	feeds, hasMore, err := loadProfileNotes(page, opts, collector)
+	if err != nil {
+		return nil, err
+	}
+	profile.Feeds = feeds
+	profile.Tab = opts.Tab
+	profile.HasMore = hasMore
+	return profile, nil
 }
 
 // extractUserProfileData 从页面中提取用户资料数据的通用方法
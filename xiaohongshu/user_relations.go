@@ -0,0 +1,289 @@
+package xiaohongshu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/sirupsen/logrus"
+)
+
+// RelationListType 关注关系列表类型
+type RelationListType string
+
+const (
+	RelationFollowers RelationListType = "followers" // 粉丝
+	RelationFollowing RelationListType = "following" // 关注
+)
+
+// RelationUser 关注/粉丝列表中的用户
+type RelationUser struct {
+	UserID    string `json:"user_id"`
+	Nickname  string `json:"nickname"`
+	Avatar    string `json:"avatar,omitempty"`
+	Desc      string `json:"desc,omitempty"`
+	XsecToken string `json:"xsec_token,omitempty"`
+	// FollowStatus 当前账号与该用户的关注关系（none / follows / fans / both）
+	FollowStatus string `json:"follow_status,omitempty"`
+	// Mutual 是否互相关注
+	Mutual bool `json:"mutual"`
+	// FollowTime 关注时间（毫秒时间戳），接口未返回时为 0
+	FollowTime int64 `json:"follow_time,omitempty"`
+}
+
+// RelationListResult 关注/粉丝列表的一页
+type RelationListResult struct {
+	// UserID 列表所属用户，查询自己时为空
+	UserID   string           `json:"user_id,omitempty"`
+	ListType RelationListType `json:"list_type"`
+	Users    []RelationUser   `json:"users"`
+	Offset   int              `json:"offset"`
+	// NextOffset 下一页的 offset，HasMore 为 false 时无意义
+	NextOffset int  `json:"next_offset"`
+	HasMore    bool `json:"has_more"`
+}
+
+// relationAPIResponse 关注/粉丝列表接口的响应，字段兼容不同版本的命名
+type relationAPIResponse struct {
+	Success bool `json:"success"`
+	Data    struct {
+		Users   []relationAPIUser `json:"users"`
+		Cursor  json.RawMessage   `json:"cursor"`
+		HasMore bool              `json:"has_more"`
+	} `json:"data"`
+}
+
+type relationAPIUser struct {
+	UserID    string `json:"user_id"`
+	ID        string `json:"id"`
+	Nickname  string `json:"nickname"`
+	Name      string `json:"name"`
+	Images    string `json:"images"`
+	Image     string `json:"image"`
+	Avatar    string `json:"avatar"`
+	Desc      string `json:"desc"`
+	XsecToken string `json:"xsec_token"`
+	Fstatus   string `json:"fstatus"`
+	Time      int64  `json:"time"`
+}
+
+func (u relationAPIUser) toRelationUser() RelationUser {
+	user := RelationUser{
+		UserID:       firstNonEmpty(u.UserID, u.ID),
+		Nickname:     firstNonEmpty(u.Nickname, u.Name),
+		Avatar:       firstNonEmpty(u.Images, u.Image, u.Avatar),
+		Desc:         u.Desc,
+		XsecToken:    u.XsecToken,
+		FollowStatus: u.Fstatus,
+		FollowTime:   u.Time,
+	}
+	user.Mutual = followStatus(u.Fstatus) == followStatusBoth
+	return user
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// relationCollector 收集列表接口返回的用户，按出现顺序去重
+type relationCollector struct {
+	mu      sync.Mutex
+	users   []RelationUser
+	seen    map[string]bool
+	hasMore bool
+	pages   int
+}
+
+func newRelationCollector() *relationCollector {
+	return &relationCollector{seen: make(map[string]bool), hasMore: true}
+}
+
+// add 追加一页接口响应
+func (c *relationCollector) add(resp relationAPIResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pages++
+	c.hasMore = resp.Data.HasMore
+	for _, u := range resp.Data.Users {
+		user := u.toRelationUser()
+		if user.UserID == "" || c.seen[user.UserID] {
+			continue
+		}
+		c.seen[user.UserID] = true
+		c.users = append(c.users, user)
+	}
+}
+
+func (c *relationCollector) snapshot() (count, pages int, hasMore bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.users), c.pages, c.hasMore
+}
+
+// page 取 [offset, offset+limit) 的用户
+func (c *relationCollector) page(offset, limit int) ([]RelationUser, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if offset >= len(c.users) {
+		return []RelationUser{}, c.hasMore
+	}
+	end := offset + limit
+	if end > len(c.users) {
+		end = len(c.users)
+	}
+	users := append([]RelationUser{}, c.users[offset:end]...)
+	return users, end < len(c.users) || c.hasMore
+}
+
+// UserRelationAction 负责获取关注/粉丝列表
+type UserRelationAction struct {
+	page *rod.Page
+}
+
+// NewUserRelationAction 创建关注列表动作
+func NewUserRelationAction(page *rod.Page) *UserRelationAction {
+	return &UserRelationAction{page: page}
+}
+
+// GetRelationList 获取用户的关注或粉丝列表中 [offset, offset+limit) 的部分。
+// userID 为空时获取当前登录账号的列表。网页端只能通过滚动列表弹窗加载，
+// 因此每次调用都会从头滚动到 offset+limit 条为止，offset 较大时耗时相应增加。
+// 对方隐藏了列表时返回错误。
+func (a *UserRelationAction) GetRelationList(ctx context.Context, userID, xsecToken string, listType RelationListType, offset, limit int) (*RelationListResult, error) {
+	page := a.page.Context(ctx).Timeout(5 * time.Minute)
+
+	collector := newRelationCollector()
+	router := page.HijackRequests()
+	for _, pattern := range relationAPIPatterns(listType) {
+		router.MustAdd(pattern, func(h *rod.Hijack) {
+			h.MustLoadResponse()
+			var resp relationAPIResponse
+			if err := json.Unmarshal([]byte(h.Response.Body()), &resp); err != nil || !resp.Success {
+				logrus.Warnf("解析%s列表接口响应失败: %v", listType, err)
+				return
+			}
+			collector.add(resp)
+		})
+	}
+	go router.Run()
+	defer router.Stop()
+
+	if userID == "" {
+		if err := NewNavigate(page).ToProfilePage(ctx); err != nil {
+			return nil, fmt.Errorf("打开个人主页失败: %w", err)
+		}
+	} else {
+		page.MustNavigate(makeUserProfileURL(userID, xsecToken))
+	}
+	page.MustWaitDOMStable()
+	time.Sleep(1 * time.Second)
+
+	if err := checkPageAccessible(page); err != nil {
+		return nil, err
+	}
+
+	if err := openRelationList(page, listType); err != nil {
+		return nil, err
+	}
+
+	want := offset + limit
+	stale := 0
+	for {
+		count, _, hasMore := collector.snapshot()
+		if count >= want || !hasMore {
+			break
+		}
+		before := count
+		scrollRelationList(page)
+		time.Sleep(1500 * time.Millisecond)
+		if count, _, _ = collector.snapshot(); count == before {
+			stale++
+			if stale >= 3 {
+				logrus.Warnf("%s列表滚动 %d 次没有新数据，停止加载", listType, stale)
+				break
+			}
+		} else {
+			stale = 0
+		}
+	}
+
+	count, pages, _ := collector.snapshot()
+	if pages == 0 {
+		return nil, fmt.Errorf("未捕获到%s列表数据，对方可能隐藏了该列表", listType)
+	}
+	logrus.Infof("%s列表：加载 %d 页共 %d 人", listType, pages, count)
+
+	users, hasMore := collector.page(offset, limit)
+	return &RelationListResult{
+		UserID:     userID,
+		ListType:   listType,
+		Users:      users,
+		Offset:     offset,
+		NextOffset: offset + len(users),
+		HasMore:    hasMore,
+	}, nil
+}
+
+// relationAPIPatterns 关注/粉丝列表接口
+func relationAPIPatterns(listType RelationListType) []string {
+	if listType == RelationFollowers {
+		return []string{"*/api/sns/web/v1/user/fans*", "*/api/sns/web/v1/user/followers*"}
+	}
+	return []string{"*/api/sns/web/v1/user/followings*", "*/api/sns/web/v1/user/follows*"}
+}
+
+// openRelationList 点击主页上的关注/粉丝数，打开列表弹窗
+func openRelationList(page *rod.Page, listType RelationListType) error {
+	label := "关注"
+	if listType == RelationFollowers {
+		label = "粉丝"
+	}
+
+	clicked, err := page.Eval(`(label) => {
+		const items = document.querySelectorAll('.user-interactions > div, .user-interactions .interaction-item, [class*="interactions"] > div');
+		for (const item of items) {
+			const text = item.innerText || '';
+			if (text.includes(label) && !text.includes('获赞')) {
+				item.click();
+				return true;
+			}
+		}
+		return false;
+	}`, label)
+	if err != nil {
+		return fmt.Errorf("打开%s列表失败: %w", label, err)
+	}
+	if !clicked.Value.Bool() {
+		return fmt.Errorf("主页上没有%s数入口", label)
+	}
+	time.Sleep(2 * time.Second)
+	return nil
+}
+
+// scrollRelationList 滚动列表弹窗到底部，触发加载下一页
+func scrollRelationList(page *rod.Page) {
+	_, err := page.Eval(`() => {
+		const containers = document.querySelectorAll('.reds-modal [class*="list"], [role=dialog] [class*="list"], [class*="follow-list"], [class*="fans-list"], [class*="user-list"]');
+		let scrolled = false;
+		for (const el of containers) {
+			if (el.scrollHeight > el.clientHeight) {
+				el.scrollTop = el.scrollHeight;
+				scrolled = true;
+			}
+		}
+		if (!scrolled) window.scrollTo(0, document.body.scrollHeight);
+	}`)
+	if err != nil {
+		logrus.Warnf("滚动列表失败: %v", err)
+	}
+}
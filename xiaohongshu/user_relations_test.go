@@ -0,0 +1,52 @@
+package xiaohongshu
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelationCollector(t *testing.T) {
+	var page1, page2 relationAPIResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"success":true,"data":{"has_more":true,"users":[
+		{"user_id":"u1","nickname":"甲","images":"https://a/1.jpg","xsec_token":"t1","fstatus":"both","time":1700000000000},
+		{"id":"u2","name":"乙","image":"https://a/2.jpg","fstatus":"fans"}
+	]}}`), &page1))
+	require.NoError(t, json.Unmarshal([]byte(`{"success":true,"data":{"has_more":false,"users":[
+		{"user_id":"u2","nickname":"乙"},
+		{"user_id":"u3","nickname":"丙"}
+	]}}`), &page2))
+
+	c := newRelationCollector()
+	c.add(page1)
+
+	users, hasMore := c.page(0, 5)
+	assert.True(t, hasMore)
+	assert.Equal(t, RelationUser{
+		UserID: "u1", Nickname: "甲", Avatar: "https://a/1.jpg", XsecToken: "t1",
+		FollowStatus: "both", Mutual: true, FollowTime: 1700000000000,
+	}, users[0])
+	assert.Equal(t, "u2", users[1].UserID)
+	assert.Equal(t, "乙", users[1].Nickname)
+	assert.False(t, users[1].Mutual)
+
+	// 重复用户去重，最后一页后 has_more 为 false
+	c.add(page2)
+	count, pages, _ := c.snapshot()
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 2, pages)
+
+	users, hasMore = c.page(1, 1)
+	assert.Equal(t, []RelationUser{{UserID: "u2", Nickname: "乙", Avatar: "https://a/2.jpg", FollowStatus: "fans"}}, users)
+	assert.True(t, hasMore)
+
+	users, hasMore = c.page(2, 5)
+	assert.Len(t, users, 1)
+	assert.False(t, hasMore)
+
+	users, hasMore = c.page(10, 5)
+	assert.Empty(t, users)
+	assert.False(t, hasMore)
+}